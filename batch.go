@@ -0,0 +1,159 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package twitter
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/928799934/twitter/jape"
+)
+
+// A Query is the common interface satisfied by the query types of the API
+// packages, whose Invoke methods return replies of type R. For example,
+// tweets.Query satisfies Query[*tweets.Reply].
+type Query[R any] interface {
+	Invoke(context.Context, *Client) (R, error)
+}
+
+// A Batch runs a collection of queries concurrently, with a bounded number of
+// workers and optional pacing of requests shared among the workers.
+//
+// For example:
+//
+//	var qs []twitter.Query[*tweets.Reply]
+//	for _, ids := range idChunks {
+//	   qs = append(qs, tweets.Lookup(ids[0], &tweets.LookupOpts{More: ids[1:]}))
+//	}
+//	rsps, err := twitter.Batch[*tweets.Reply]{Concurrency: 4}.Run(ctx, cli, qs...)
+type Batch[R any] struct {
+	// The maximum number of queries to invoke concurrently.
+	// If Concurrency ≤ 0, the queries are invoked one at a time.
+	Concurrency int
+
+	// If positive, the minimum interval between the start of consecutive
+	// queries, shared among all workers.
+	MinInterval time.Duration
+}
+
+// Run invokes each of the given queries on cli, and returns their replies in
+// the same order as the queries. If any of the queries fail, Run returns the
+// replies of the queries that succeeded, together with an error of concrete
+// type *BatchError describing the failures. The reply for a failed query is
+// the zero value of R.
+//
+// If ctx ends before all the queries have been started, the remaining queries
+// fail with the error from ctx.
+func (b Batch[R]) Run(ctx context.Context, cli *Client, qs ...Query[R]) ([]R, error) {
+	nw := b.Concurrency
+	if nw <= 0 {
+		nw = 1
+	}
+	if nw > len(qs) {
+		nw = len(qs)
+	}
+
+	out := make([]R, len(qs))
+	errs := make([]error, len(qs))
+	p := &pacer{interval: b.MinInterval, clock: (*jape.Client)(cli)}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < nw; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				if err := p.wait(ctx); err != nil {
+					errs[i] = err
+					continue
+				}
+				out[i], errs[i] = qs[i].Invoke(ctx, cli)
+			}
+		}()
+	}
+	for i := range qs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	var nerr int
+	for _, err := range errs {
+		if err != nil {
+			nerr++
+		}
+	}
+	if nerr != 0 {
+		return out, &BatchError{Errors: errs, Failed: nerr}
+	}
+	return out, nil
+}
+
+// A BatchError reports the errors from a batch of queries.
+type BatchError struct {
+	// The errors from each query, in the same order as the queries.
+	// The error for a query that succeeded is nil.
+	Errors []error
+
+	// The number of queries that failed.
+	Failed int
+}
+
+// Error satisfies the error interface.
+func (e *BatchError) Error() string {
+	for _, err := range e.Errors {
+		if err != nil {
+			return fmt.Sprintf("%d of %d queries failed; first error: %v", e.Failed, len(e.Errors), err)
+		}
+	}
+	return "batch failed" // unreachable for a well-formed error
+}
+
+// Unwrap reports the non-nil errors from e, for use by errors.Is and errors.As.
+func (e *BatchError) Unwrap() []error {
+	var out []error
+	for _, err := range e.Errors {
+		if err != nil {
+			out = append(out, err)
+		}
+	}
+	return out
+}
+
+// A pacer enforces a minimum interval between events.
+type pacer struct {
+	interval time.Duration
+	clock    jape.Clock
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// wait blocks until the next event is permitted, or ctx ends.
+func (p *pacer) wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	} else if p.interval <= 0 {
+		return nil
+	}
+	p.mu.Lock()
+	now := p.clock.Now()
+	when := p.next
+	if when.Before(now) {
+		when = now
+	}
+	p.next = when.Add(p.interval)
+	p.mu.Unlock()
+
+	if d := when.Sub(now); d > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-p.clock.After(d):
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package twitter_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/jape"
+	"github.com/928799934/twitter/tweets"
+)
+
+func TestBatch(t *testing.T) {
+	var active, peak int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&active, 1)
+		defer atomic.AddInt32(&active, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		id := r.URL.Query().Get("ids")
+		if id == "bad" {
+			http.Error(w, `{"title":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"data":[{"id":"` + id + `","text":"ok"}]}`))
+	}))
+	defer srv.Close()
+
+	cli := twitter.NewClient(&jape.Client{BaseURL: srv.URL})
+	ids := []string{"1", "2", "bad", "4", "5", "6", "7", "8"}
+	var qs []twitter.Query[*tweets.Reply]
+	for _, id := range ids {
+		qs = append(qs, tweets.Lookup(id, nil))
+	}
+
+	rsps, err := twitter.Batch[*tweets.Reply]{Concurrency: 3}.Run(context.Background(), cli, qs...)
+	var berr *twitter.BatchError
+	if !errors.As(err, &berr) {
+		t.Fatalf("Run: got error %v, want *BatchError", err)
+	}
+	if berr.Failed != 1 || berr.Errors[2] == nil {
+		t.Errorf("BatchError: got %d failed %v, want query 2 to fail", berr.Failed, berr.Errors)
+	}
+	var jerr *jape.Error
+	if !errors.As(err, &jerr) || jerr.Status != http.StatusNotFound {
+		t.Errorf("BatchError: got %v, want wrapped 404 *jape.Error", err)
+	}
+	for i, id := range ids {
+		if i == 2 {
+			if rsps[i] != nil {
+				t.Errorf("Reply %d: got %v, want nil", i, rsps[i])
+			}
+			continue
+		}
+		if rsps[i] == nil || len(rsps[i].Tweets) != 1 || rsps[i].Tweets[0].ID != id {
+			t.Errorf("Reply %d: got %+v, want tweet %q", i, rsps[i], id)
+		}
+	}
+	if p := atomic.LoadInt32(&peak); p > 3 {
+		t.Errorf("Peak concurrency: got %d, want ≤ 3", p)
+	}
+
+	t.Run("MinInterval", func(t *testing.T) {
+		clock := newFakeClock()
+		start := clock.Now()
+		pcli := twitter.NewClient(&jape.Client{BaseURL: srv.URL, Clock: clock})
+		_, err := twitter.Batch[*tweets.Reply]{
+			Concurrency: 4,
+			MinInterval: 25 * time.Second,
+		}.Run(context.Background(), pcli, qs[:4]...)
+		if err != nil && !errors.As(err, &berr) {
+			t.Fatalf("Run: unexpected error: %v", err)
+		}
+		if elapsed := clock.Now().Sub(start); elapsed < 75*time.Second {
+			t.Errorf("Run took %v, want ≥ 75s with pacing", elapsed)
+		}
+	})
+}
+
+// fakeClock is a jape.Clock whose time advances only when a caller waits for
+// a timer, which fires immediately.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2022, 3, 14, 12, 0, 0, 0, time.UTC)}
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *fakeClock) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- f.now
+	return ch
+}
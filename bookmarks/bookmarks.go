@@ -0,0 +1,228 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+// Package bookmarks supports queries for reading and editing the bookmarks of
+// the authenticated user.
+//
+// To list the bookmarks of a user, use bookmarks.List:
+//
+//	q := bookmarks.List(userID, nil)
+//	for q.HasMorePages() {
+//	   rsp, err := q.Invoke(ctx, cli)
+//	   // ...
+//	   for _, b := range rsp.Bookmarks {
+//	      process(b.Tweet)
+//	   }
+//	}
+//
+// To list only the bookmarks in a particular folder, set the FolderID field
+// of the options. Each bookmark reports the folder it was listed from, if any.
+// The folders of a user can be listed with bookmarks.Folders.
+//
+// To add or remove a bookmark, use bookmarks.Add and bookmarks.Remove. Both
+// identify the bookmark by the ID of the bookmarked tweet.
+package bookmarks
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/edit"
+	"github.com/928799934/twitter/jape"
+	"github.com/928799934/twitter/tweets"
+	"github.com/928799934/twitter/types"
+)
+
+// List constructs a query for the tweets bookmarked by the given user ID.
+//
+// API: 2/users/:id/bookmarks
+//
+// If opts.FolderID is set, the query lists only the bookmarks in that folder.
+//
+// API: 2/users/:id/bookmarks/folders/:folder_id
+func List(userID string, opts *ListOpts) Query {
+	q := tweets.BookmarkedBy(userID, opts.tweetOpts())
+	var folder string
+	if opts != nil && opts.FolderID != "" {
+		folder = opts.FolderID
+		q.Request.Method = "2/users/" + userID + "/bookmarks/folders/" + folder
+	}
+	return Query{Query: q, folderID: folder}
+}
+
+// Add constructs a query for the given user ID to bookmark the given tweet ID.
+// This is equivalent to edit.Bookmark.
+//
+// API: POST 2/users/:id/bookmarks
+func Add(userID, tweetID string) edit.Query { return edit.Bookmark(userID, tweetID) }
+
+// Remove constructs a query for the given user ID to remove the bookmark for
+// the given tweet ID, regardless of which folder (if any) it belongs to.  This
+// is equivalent to edit.Unbookmark.
+//
+// API: DELETE 2/users/:id/bookmarks/:tweet_id
+func Remove(userID, tweetID string) edit.Query { return edit.Unbookmark(userID, tweetID) }
+
+// A Query performs a query for bookmarks. A Query supports pagination in the
+// same manner as a tweets.Query.
+type Query struct {
+	tweets.Query
+	folderID string
+}
+
+// Invoke executes the query on the given context and client. If the reply
+// contains a pagination token, q is updated in-place so that invoking the
+// query again will fetch the next page.
+func (q Query) Invoke(ctx context.Context, cli *twitter.Client) (*Reply, error) {
+	rsp, err := q.Query.Invoke(ctx, cli)
+	if err != nil {
+		return nil, err
+	}
+	out := &Reply{Reply: rsp}
+	var folder *Folder
+	if q.folderID != "" {
+		folder = &Folder{ID: q.folderID}
+	}
+	for _, t := range rsp.Tweets {
+		out.Bookmarks = append(out.Bookmarks, &Bookmark{Tweet: t, Folder: folder})
+	}
+	return out, nil
+}
+
+// A Reply is the response from a Query. The Tweets field of the embedded
+// reply contains the bookmarked tweets, in the same order as Bookmarks.
+type Reply struct {
+	*tweets.Reply
+	Bookmarks []*Bookmark
+}
+
+// A Bookmark is a single bookmark entry.
+type Bookmark struct {
+	Tweet *types.Tweet
+
+	// The folder containing the bookmark. This is nil unless the bookmark was
+	// listed from a specific folder.
+	Folder *Folder
+}
+
+// A Folder describes a bookmark folder. When a folder is reported for a
+// Bookmark, only the ID is populated.
+type Folder struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// ListOpts provide parameters for listing bookmarks. A nil *ListOpts provides
+// empty values for all fields.
+type ListOpts struct {
+	// If set, list only the bookmarks in the folder with this ID.
+	FolderID string
+
+	// A pagination token provided by the server.
+	PageToken string
+
+	// The maximum number of results to return; 0 means let the server choose.
+	// The service will accept values up to 100.
+	MaxResults int
+
+	// Optional response fields and expansions.
+	Optional []types.Fields
+}
+
+func (o *ListOpts) tweetOpts() *tweets.ListOpts {
+	if o == nil {
+		return nil
+	}
+	return &tweets.ListOpts{
+		PageToken:  o.PageToken,
+		MaxResults: o.MaxResults,
+		Optional:   o.Optional,
+	}
+}
+
+// Folders constructs a query for the bookmark folders of the given user ID.
+//
+// API: 2/users/:id/bookmarks/folders
+func Folders(userID string, opts *FolderOpts) FolderQuery {
+	req := &jape.Request{
+		Method: "2/users/" + userID + "/bookmarks/folders",
+		Params: make(jape.Params),
+	}
+	opts.addRequestParams(req)
+	return FolderQuery{Request: req}
+}
+
+// A FolderQuery performs a query for bookmark folders.
+type FolderQuery struct {
+	*jape.Request
+}
+
+// Invoke executes the query on the given context and client. If the reply
+// contains a pagination token, q is updated in-place so that invoking the
+// query again will fetch the next page.
+func (q FolderQuery) Invoke(ctx context.Context, cli *twitter.Client) (*FolderReply, error) {
+	rsp, err := cli.Call(ctx, q.Request)
+	if err != nil {
+		return nil, err
+	}
+	out := &FolderReply{Reply: rsp}
+	if len(rsp.Data) != 0 {
+		if err := json.Unmarshal(rsp.Data, &out.Folders); err != nil {
+			return nil, &jape.Error{Data: rsp.Data, Message: "decoding folder data", Err: err}
+		}
+	}
+	q.Request.Params.Set(twitter.NextTokenParam, "")
+	if len(rsp.Meta) != 0 {
+		if err := json.Unmarshal(rsp.Meta, &out.Meta); err != nil {
+			return nil, &jape.Error{Data: rsp.Meta, Message: "decoding response metadata", Err: err}
+		}
+		q.Request.Params.Set(twitter.NextTokenParam, out.Meta.NextToken)
+	}
+	return out, nil
+}
+
+// HasMorePages reports whether the query has more pages to fetch. This is true
+// for a freshly-constructed query, and for an invoked query where the server
+// has not reported a next-page token.
+func (q FolderQuery) HasMorePages() bool {
+	v, ok := q.Request.Params[twitter.NextTokenParam]
+	return !ok || v[0] != ""
+}
+
+// ResetPageToken clears (resets) the query's current page token. Subsequently
+// invoking the query will then fetch the first page of results.
+func (q FolderQuery) ResetPageToken() { q.Request.Params.Reset(twitter.NextTokenParam) }
+
+// PageToken returns the page token the query will send when it is next
+// invoked, or "" if the query is fresh or has no more pages.
+func (q FolderQuery) PageToken() string { return q.Request.Params.Get(twitter.NextTokenParam) }
+
+// A FolderReply is the response from a FolderQuery.
+type FolderReply struct {
+	*twitter.Reply
+	Folders []*Folder
+	Meta    *twitter.Pagination
+}
+
+// FolderOpts provide parameters for listing bookmark folders. A nil
+// *FolderOpts provides empty values for all fields.
+type FolderOpts struct {
+	// A pagination token provided by the server.
+	PageToken string
+
+	// The maximum number of results to return; 0 means let the server choose.
+	MaxResults int
+}
+
+func (o *FolderOpts) addRequestParams(req *jape.Request) {
+	if o == nil {
+		return // nothing to do
+	}
+	if o.PageToken != "" {
+		req.Params.Set(twitter.NextTokenParam, o.PageToken)
+	}
+	if o.MaxResults > 0 {
+		req.Params.Set("max_results", strconv.Itoa(o.MaxResults))
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package bookmarks_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/bookmarks"
+	"github.com/928799934/twitter/jape"
+)
+
+func TestBookmarks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "GET /2/users/u1/bookmarks":
+			w.Write([]byte(`{"data":[{"id":"t1","text":"one"},{"id":"t2","text":"two"}],"meta":{"result_count":2}}`))
+		case "GET /2/users/u1/bookmarks/folders/f1":
+			w.Write([]byte(`{"data":[{"id":"t3","text":"three"}],"meta":{"result_count":1}}`))
+		case "GET /2/users/u1/bookmarks/folders":
+			w.Write([]byte(`{"data":[{"id":"f1","name":"Reading"}],"meta":{"result_count":1}}`))
+		case "POST /2/users/u1/bookmarks":
+			body, _ := io.ReadAll(r.Body)
+			if string(body) != `{"tweet_id":"t9"}` {
+				http.Error(w, "bad body", http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"data":{"bookmarked":true}}`))
+		case "DELETE /2/users/u1/bookmarks/t1":
+			w.Write([]byte(`{"data":{"bookmarked":false}}`))
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	cli := twitter.NewClient(&jape.Client{BaseURL: srv.URL})
+	ctx := context.Background()
+
+	t.Run("List", func(t *testing.T) {
+		q := bookmarks.List("u1", nil)
+		rsp, err := q.Invoke(ctx, cli)
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		if len(rsp.Bookmarks) != 2 || rsp.Bookmarks[0].Tweet.ID != "t1" || rsp.Bookmarks[0].Folder != nil {
+			t.Errorf("List: got %+v, want 2 bookmarks without folders", rsp.Bookmarks)
+		}
+		if q.HasMorePages() {
+			t.Error("List: query reports more pages")
+		}
+	})
+
+	t.Run("ListFolder", func(t *testing.T) {
+		rsp, err := bookmarks.List("u1", &bookmarks.ListOpts{FolderID: "f1"}).Invoke(ctx, cli)
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		if len(rsp.Bookmarks) != 1 {
+			t.Fatalf("List: got %d bookmarks, want 1", len(rsp.Bookmarks))
+		}
+		if b := rsp.Bookmarks[0]; b.Tweet.ID != "t3" || b.Folder == nil || b.Folder.ID != "f1" {
+			t.Errorf("List: got %+v, want t3 in folder f1", b)
+		}
+	})
+
+	t.Run("Folders", func(t *testing.T) {
+		rsp, err := bookmarks.Folders("u1", nil).Invoke(ctx, cli)
+		if err != nil {
+			t.Fatalf("Folders failed: %v", err)
+		}
+		if len(rsp.Folders) != 1 || rsp.Folders[0].Name != "Reading" {
+			t.Errorf("Folders: got %+v, want one folder named Reading", rsp.Folders)
+		}
+	})
+
+	t.Run("AddRemove", func(t *testing.T) {
+		if ok, err := bookmarks.Add("u1", "t9").Invoke(ctx, cli); err != nil || !ok {
+			t.Errorf("Add: got %v, %v; want true, nil", ok, err)
+		}
+		if ok, err := bookmarks.Remove("u1", "t1").Invoke(ctx, cli); err != nil || ok {
+			t.Errorf("Remove: got %v, %v; want false, nil", ok, err)
+		}
+	})
+}
@@ -0,0 +1,125 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package twitter
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/928799934/twitter/jape"
+)
+
+// MaxLookupIDs is the maximum number of IDs or names the API accepts in a
+// single lookup request.
+const MaxLookupIDs = 100
+
+// CallChunked issues req once for each chunk of at most MaxLookupIDs values
+// of the named parameter, and returns a single reply merging the results.
+// Up to concurrency requests are issued at once; if concurrency ≤ 0, they are
+// issued one at a time. If the parameter has at most MaxLookupIDs values,
+// CallChunked is equivalent to Call.
+//
+// The merged reply contains the data, includes, and errors of all the chunks,
+// in order. The data of the merged reply is always an array. Its RateLimit is
+// the most restrictive limit reported by any chunk. If any chunk fails, the
+// error has concrete type *BatchError.
+func (c *Client) CallChunked(ctx context.Context, req *jape.Request, param string, concurrency int) (*Reply, error) {
+	vals := req.Params[param]
+	if len(vals) <= MaxLookupIDs {
+		return c.Call(ctx, req)
+	}
+	var qs []Query[*Reply]
+	for i := 0; i < len(vals); i += MaxLookupIDs {
+		end := i + MaxLookupIDs
+		if end > len(vals) {
+			end = len(vals)
+		}
+		cp := *req
+		cp.Params = make(jape.Params, len(req.Params))
+		for name, vs := range req.Params {
+			cp.Params[name] = vs
+		}
+		cp.Params[param] = vals[i:end]
+		qs = append(qs, callQuery{req: &cp})
+	}
+	start := time.Now() // measured on the wall clock, not the client Clock
+	rsps, err := Batch[*Reply]{Concurrency: concurrency}.Run(ctx, c, qs...)
+	if err != nil {
+		return nil, err
+	}
+	out, err := MergeReplies(rsps)
+	if err != nil {
+		return nil, err
+	}
+	out.Info.Latency = time.Since(start)
+	return out, nil
+}
+
+// callQuery is a Query that issues a request with Call.
+type callQuery struct{ req *jape.Request }
+
+func (q callQuery) Invoke(ctx context.Context, cli *Client) (*Reply, error) {
+	return cli.Call(ctx, q.req)
+}
+
+// MergeReplies combines the data, includes, and errors of rsps into a single
+// reply, in order. The data of the merged reply is an array, and identical
+// included objects are reported only once. The merged reply has the most
+// restrictive rate limit of rsps, and its Info sums their sizes and decoding
+// times. Metadata are not merged, since their contents vary by endpoint.
+func MergeReplies(rsps []*Reply) (*Reply, error) {
+	out := &Reply{Info: new(CallInfo)}
+	var data []json.RawMessage
+	incs := make(map[string][]json.RawMessage)
+	seen := make(map[string]bool)
+	for _, rsp := range rsps {
+		if len(rsp.Data) != 0 {
+			if rsp.Data[0] == '{' {
+				data = append(data, rsp.Data)
+			} else {
+				var vs []json.RawMessage
+				if err := json.Unmarshal(rsp.Data, &vs); err != nil {
+					return nil, &jape.Error{Data: rsp.Data, Message: "decoding response data", Err: err}
+				}
+				data = append(data, vs...)
+			}
+		}
+		for key, inc := range rsp.Includes {
+			var vs []json.RawMessage
+			if err := json.Unmarshal(inc, &vs); err != nil {
+				return nil, &jape.Error{Data: inc, Message: "decoding includes", Err: err}
+			}
+			for _, v := range vs {
+				if id := key + "\x00" + string(v); !seen[id] {
+					seen[id] = true
+					incs[key] = append(incs[key], v)
+				}
+			}
+		}
+		out.Errors = append(out.Errors, rsp.Errors...)
+		if rl := rsp.RateLimit; rl != nil && (out.RateLimit == nil || rl.Remaining < out.RateLimit.Remaining) {
+			out.RateLimit = rl
+		}
+		if rsp.Info != nil {
+			out.Info.Bytes += rsp.Info.Bytes
+			out.Info.Decode += rsp.Info.Decode
+			out.Info.ServerTime += rsp.Info.ServerTime
+		}
+	}
+	var err error
+	if len(data) != 0 {
+		if out.Data, err = json.Marshal(data); err != nil {
+			return nil, err
+		}
+	}
+	if len(incs) != 0 {
+		out.Includes = make(map[string]json.RawMessage, len(incs))
+		for key, vs := range incs {
+			if out.Includes[key], err = json.Marshal(vs); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return out, nil
+}
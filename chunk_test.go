@@ -0,0 +1,99 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package twitter_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/jape"
+	"github.com/928799934/twitter/tweets"
+	"github.com/928799934/twitter/users"
+)
+
+func TestChunkedLookup(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		param := "ids"
+		if r.URL.Path == "/2/users/by" {
+			param = "usernames"
+		}
+		ids := strings.Split(r.URL.Query().Get(param), ",")
+		if len(ids) > twitter.MaxLookupIDs {
+			t.Errorf("Request has %d IDs, want at most %d", len(ids), twitter.MaxLookupIDs)
+		}
+		type obj map[string]string
+		var data []obj
+		for _, id := range ids {
+			if r.URL.Path == "/2/tweets" {
+				data = append(data, obj{"id": id, "text": "tweet " + id, "author_id": "u1"})
+			} else {
+				data = append(data, obj{"id": "u" + id, "username": id})
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data":     data,
+			"includes": map[string][]obj{"users": {{"id": "u1", "username": "one"}}},
+		})
+	}))
+	defer srv.Close()
+
+	cli := twitter.NewClient(&jape.Client{BaseURL: srv.URL})
+	ctx := context.Background()
+
+	var ids []string
+	for i := 1; i <= 250; i++ {
+		ids = append(ids, strconv.Itoa(i))
+	}
+
+	t.Run("Tweets", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		rsp, err := tweets.Lookup(ids[0], &tweets.LookupOpts{
+			More:        ids[1:],
+			Concurrency: 2,
+		}).Invoke(ctx, cli)
+		if err != nil {
+			t.Fatalf("Lookup failed: %v", err)
+		}
+		if n := atomic.LoadInt32(&calls); n != 3 {
+			t.Errorf("Lookup made %d calls, want 3", n)
+		}
+		if len(rsp.Tweets) != len(ids) {
+			t.Fatalf("Lookup: got %d tweets, want %d", len(rsp.Tweets), len(ids))
+		}
+		for i, tw := range rsp.Tweets {
+			if tw.ID != ids[i] {
+				t.Errorf("Tweet %d: got ID %q, want %q", i, tw.ID, ids[i])
+			}
+		}
+		us, err := rsp.IncludedUsers()
+		if err != nil {
+			t.Fatalf("IncludedUsers: %v", err)
+		}
+		if len(us) != 1 || us[0].ID != "u1" {
+			t.Errorf("IncludedUsers: got %+v, want one user u1", us)
+		}
+	})
+
+	t.Run("Users", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		rsp, err := users.LookupByName(ids[0], &users.LookupOpts{More: ids[1:]}).Invoke(ctx, cli)
+		if err != nil {
+			t.Fatalf("LookupByName failed: %v", err)
+		}
+		if n := atomic.LoadInt32(&calls); n != 3 {
+			t.Errorf("LookupByName made %d calls, want 3", n)
+		}
+		if len(rsp.Users) != len(ids) {
+			t.Errorf("LookupByName: got %d users, want %d", len(rsp.Users), len(ids))
+		}
+	})
+}
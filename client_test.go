@@ -0,0 +1,214 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package twitter_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/jape"
+	"github.com/928799934/twitter/tweets"
+)
+
+func TestCallInto(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/ok":
+			w.Write([]byte(`{"data":[{"id":"1","text":"a"},{"id":"2","text":"b"}],"meta":{"result_count":2}}`))
+		case "/bad":
+			w.Write([]byte(`{"data":"wrong shape"}`))
+		}
+	}))
+	defer srv.Close()
+
+	cli := twitter.NewClient(&jape.Client{BaseURL: srv.URL})
+	ctx := context.Background()
+
+	var got []struct {
+		ID string `json:"id"`
+	}
+	rsp, err := cli.CallInto(ctx, &jape.Request{Method: "ok"}, &got)
+	if err != nil {
+		t.Fatalf("CallInto failed: %v", err)
+	}
+	if len(got) != 2 || got[0].ID != "1" || got[1].ID != "2" {
+		t.Errorf("CallInto: got %+v, want IDs 1, 2", got)
+	}
+	if len(rsp.Meta) == 0 {
+		t.Error("CallInto: reply is missing metadata")
+	}
+
+	if _, err := cli.CallInto(ctx, &jape.Request{Method: "bad"}, &got); err == nil {
+		t.Error("CallInto: got nil error for mismatched data")
+	} else if _, ok := err.(*jape.Error); !ok {
+		t.Errorf("CallInto: got error %T, want *jape.Error", err)
+	}
+}
+
+func TestCallInfo(t *testing.T) {
+	const body = `{"data":{"id":"1","text":"hello"}}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Response-Time", "37")
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	cli := twitter.NewClient(&jape.Client{BaseURL: srv.URL})
+	rsp, err := cli.Call(context.Background(), &jape.Request{Method: "2/tweets/1"})
+	if err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	if rsp.Info == nil {
+		t.Fatal("Call: reply has no Info")
+	}
+	if rsp.Info.Bytes != len(body) {
+		t.Errorf("Info.Bytes: got %d, want %d", rsp.Info.Bytes, len(body))
+	}
+	if rsp.Info.Latency <= 0 {
+		t.Errorf("Info.Latency: got %v, want > 0", rsp.Info.Latency)
+	}
+	if want := 37 * time.Millisecond; rsp.Info.ServerTime != want {
+		t.Errorf("Info.ServerTime: got %v, want %v", rsp.Info.ServerTime, want)
+	}
+}
+
+func TestPartialErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":[{"id":"1","text":"ok"}],"errors":[` +
+			`{"value":"2","detail":"Could not find tweet with ids: [2].","title":"Not Found Error",` +
+			`"resource_type":"tweet","parameter":"ids","resource_id":"2",` +
+			`"type":"https://api.twitter.com/2/problems/resource-not-found"},` +
+			`{"title":"Authorization Error","detail":"Sorry, you are not authorized to see the Tweet with ids: [3].",` +
+			`"value":"3","resource_type":"tweet","parameter":"ids",` +
+			`"type":"https://api.twitter.com/2/problems/not-authorized-for-resource"}]}`))
+	}))
+	defer srv.Close()
+
+	cli := twitter.NewClient(&jape.Client{BaseURL: srv.URL})
+	rsp, err := tweets.Lookup("1", &tweets.LookupOpts{More: []string{"2", "3"}}).Invoke(context.Background(), cli)
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	if len(rsp.Tweets) != 1 {
+		t.Errorf("Lookup: got %d tweets, want 1", len(rsp.Tweets))
+	}
+	errs := rsp.ErrorsByID()
+	if len(errs) != 2 {
+		t.Fatalf("ErrorsByID: got %v, want 2 entries", errs)
+	}
+	if e := errs["2"]; e == nil || twitter.Problem(e.TypeURL) != twitter.ProblemResourceNotFound {
+		t.Errorf("Error for 2: got %+v, want not found", e)
+	}
+	if e := errs["3"]; e == nil || twitter.Problem(e.TypeURL) != twitter.ProblemNotAuthorized {
+		t.Errorf("Error for 3: got %+v, want not authorized", e)
+	}
+}
+
+func TestStreamMessages(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"id":"1","text":"one"},"matching_rules":[` +
+			`{"id":"101","tag":"cats"},{"id":"102"}]}` + "\r\n"))
+		w.Write([]byte(`{"errors":[{"title":"operational-disconnect",` +
+			`"disconnect_type":"UpstreamOperationalDisconnect",` +
+			`"detail":"This stream has been disconnected upstream for operational reasons.",` +
+			`"type":"https://api.twitter.com/2/problems/operational-disconnect"}]}` + "\r\n"))
+	}))
+	defer srv.Close()
+
+	cli := twitter.NewClient(&jape.Client{BaseURL: srv.URL})
+	var ntweets int
+	var sys []*twitter.Error
+	var matched []string
+	err := tweets.SearchStream(func(rsp *tweets.Reply) error {
+		ntweets += len(rsp.Tweets)
+		for _, r := range rsp.MatchingRules {
+			matched = append(matched, r.ID+":"+r.Tag)
+		}
+		if rsp.System != nil {
+			sys = append(sys, rsp.System)
+		}
+		return nil
+	}, nil).Invoke(context.Background(), cli)
+
+	if !errors.Is(err, twitter.ProblemOperationalDisconnect) {
+		t.Errorf("Stream: got error %v, want %v", err, twitter.ProblemOperationalDisconnect)
+	}
+	if _, ok := err.(*jape.Error); !ok {
+		t.Errorf("Stream: got error %T, want *jape.Error", err)
+	}
+	if ntweets != 1 {
+		t.Errorf("Stream: got %d tweets, want 1", ntweets)
+	}
+	if got, want := strings.Join(matched, " "), "101:cats 102:"; got != want {
+		t.Errorf("Matching rules: got %q, want %q", got, want)
+	}
+	if len(sys) != 1 {
+		t.Fatalf("Stream: got %d system messages, want 1", len(sys))
+	}
+	if got := sys[0].Errors[0].DisconnectType; got != "UpstreamOperationalDisconnect" {
+		t.Errorf("Disconnect type: got %q, want UpstreamOperationalDisconnect", got)
+	}
+}
+
+func TestStreamTransportError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errors":[{"title":"operational-disconnect",` +
+			`"type":"https://api.twitter.com/2/problems/operational-disconnect"}]}` + "\r\n"))
+		w.(http.Flusher).Flush()
+
+		// Drop the connection without ending the response body.
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("Hijack: %v", err)
+			return
+		}
+		conn.Close()
+	}))
+	defer srv.Close()
+
+	cli := twitter.NewClient(&jape.Client{BaseURL: srv.URL})
+	err := tweets.SearchStream(func(*tweets.Reply) error { return nil }, nil).Invoke(context.Background(), cli)
+	if err == nil {
+		t.Fatal("Stream: got nil error, want a transport error")
+	}
+	if errors.Is(err, twitter.ProblemOperationalDisconnect) {
+		t.Errorf("Stream: got error %v, want the transport error", err)
+	}
+}
+
+func TestRateLimitStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/1.1/application/rate_limit_status.json" {
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("resources"); got != "statuses,users" {
+			t.Errorf("Parameter resources: got %q, want statuses,users", got)
+		}
+		w.Write([]byte(`{"rate_limit_context":{"access_token":"x"},"resources":{
+  "statuses":{"/statuses/show/:id":{"limit":900,"remaining":899,"reset":1665700000}},
+  "users":{"/users/lookup":{"limit":300,"remaining":0,"reset":1665700900}}}}`))
+	}))
+	defer srv.Close()
+	cli := twitter.NewClient(&jape.Client{BaseURL: srv.URL})
+
+	got, err := cli.RateLimitStatus(context.Background(), "statuses", "users")
+	if err != nil {
+		t.Fatalf("RateLimitStatus failed: %v", err)
+	}
+	if len(got) != 2 {
+		t.Errorf("RateLimitStatus: got %d limits, want 2", len(got))
+	}
+	rl := got["/users/lookup"]
+	if rl == nil || rl.Ceiling != 300 || rl.Remaining != 0 || rl.Reset.Unix() != 1665700900 {
+		t.Errorf("Limit for /users/lookup: got %+v", rl)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+// Package compliance supports queries for batch compliance jobs.
+//
+// A compliance job reports which of a set of tweet or user IDs have been
+// deleted, suspended, or otherwise changed, so that stored data can be brought
+// into compliance. To create a job, use compliance.Create:
+//
+//	rsp, err := compliance.Create(compliance.TweetsJob, &compliance.CreateOpts{
+//	   Name: "nightly",
+//	}).Invoke(ctx, cli)
+//
+// The reply reports the URL to which the IDs to check should be uploaded, and
+// the URL from which the results can be downloaded once the job is complete.
+// To check the status of a job, use compliance.Lookup; to list existing jobs,
+// use compliance.List.
+//
+// To upload IDs and download results, use compliance.Upload and
+// compliance.Download. To run the whole workflow in one call, use
+// compliance.Run.
+//
+// These queries require app-only (bearer token) authorization.
+package compliance
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/jape"
+)
+
+// Constants for the types of compliance jobs.
+const (
+	TweetsJob = "tweets"
+	UsersJob  = "users"
+)
+
+// Constants for the status of a compliance job.
+const (
+	StatusCreated    = "created"
+	StatusInProgress = "in_progress"
+	StatusFailed     = "failed"
+	StatusComplete   = "complete"
+	StatusExpired    = "expired"
+)
+
+// A Job is the decoded form of a compliance job.
+type Job struct {
+	ID        string     `json:"id"`
+	Type      string     `json:"type"`   // TweetsJob or UsersJob
+	Status    string     `json:"status"` // e.g., StatusComplete
+	Name      string     `json:"name,omitempty"`
+	Resumable bool       `json:"resumable,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+
+	// The pre-signed URL to which the IDs to check are uploaded, and the time
+	// after which it is no longer valid.
+	UploadURL       string     `json:"upload_url,omitempty"`
+	UploadExpiresAt *time.Time `json:"upload_expires_at,omitempty"`
+
+	// The pre-signed URL from which the results are downloaded once the job
+	// is complete, and the time after which it is no longer valid.
+	DownloadURL       string     `json:"download_url,omitempty"`
+	DownloadExpiresAt *time.Time `json:"download_expires_at,omitempty"`
+}
+
+// Create constructs a query to create a new compliance job of the given type.
+// A successful reply contains a single Job value for the created job.
+//
+// API: POST 2/compliance/jobs
+func Create(jobType string, opts *CreateOpts) Query {
+	req := &jape.Request{
+		Method:     "2/compliance/jobs",
+		HTTPMethod: "POST",
+		Params:     make(jape.Params),
+	}
+	body := struct {
+		Type      string `json:"type"`
+		Name      string `json:"name,omitempty"`
+		Resumable bool   `json:"resumable,omitempty"`
+	}{Type: jobType}
+	if opts != nil {
+		body.Name = opts.Name
+		body.Resumable = opts.Resumable
+	}
+	data, err := json.Marshal(body)
+	req.Data = data
+	req.ContentType = "application/json"
+	return Query{Request: req, encodeErr: err}
+}
+
+// CreateOpts provides parameters for creating a compliance job. A nil
+// *CreateOpts provides empty values for all fields.
+type CreateOpts struct {
+	// A name for the job, to help tell jobs apart.
+	Name string
+
+	// If true, the upload URL permits a resumable upload.
+	Resumable bool
+}
+
+// List constructs a query for the compliance jobs of the given type.
+//
+// API: 2/compliance/jobs
+func List(jobType string, opts *ListOpts) Query {
+	req := &jape.Request{
+		Method: "2/compliance/jobs",
+		Params: make(jape.Params),
+	}
+	req.Params.Set("type", jobType)
+	opts.addRequestParams(req)
+	return Query{Request: req}
+}
+
+// ListOpts provides parameters for listing compliance jobs. A nil *ListOpts
+// provides empty values for all fields.
+type ListOpts struct {
+	// If set, list only jobs with this status (e.g., StatusComplete).
+	Status string
+}
+
+func (o *ListOpts) addRequestParams(req *jape.Request) {
+	if o == nil {
+		return // nothing to do
+	}
+	if o.Status != "" {
+		req.Params.Set("status", o.Status)
+	}
+}
+
+// Lookup constructs a query for the compliance job with the given ID.
+//
+// API: 2/compliance/jobs/:id
+func Lookup(id string) Query {
+	return Query{Request: &jape.Request{
+		Method: "2/compliance/jobs/" + id,
+		Params: make(jape.Params),
+	}}
+}
+
+// A Query performs a query for compliance jobs.
+type Query struct {
+	*jape.Request
+	encodeErr error
+}
+
+// Invoke executes the query on the given context and client.
+func (q Query) Invoke(ctx context.Context, cli *twitter.Client) (*Reply, error) {
+	if q.encodeErr != nil {
+		return nil, q.encodeErr // deferred encoding error
+	}
+	rsp, err := cli.Call(ctx, q.Request)
+	if err != nil {
+		return nil, err
+	}
+	var jobs []*Job
+	if len(rsp.Data) == 0 {
+		// no results
+	} else if rsp.Data[0] == '{' {
+		// single-value return
+		jobs = append(jobs, new(Job))
+		err = json.Unmarshal(rsp.Data, jobs[0])
+	} else {
+		// multiple-value return
+		err = json.Unmarshal(rsp.Data, &jobs)
+	}
+	if err != nil {
+		return nil, &jape.Error{Data: rsp.Data, Message: "decoding compliance job data", Err: err}
+	}
+	return &Reply{Reply: rsp, Jobs: jobs}, nil
+}
+
+// A Reply is the response from a Query.
+type Reply struct {
+	*twitter.Reply
+	Jobs []*Job
+}
@@ -0,0 +1,221 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package compliance_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/compliance"
+	"github.com/928799934/twitter/internal/otest"
+	"github.com/928799934/twitter/jape"
+)
+
+const testJob = `{"id":"j1","type":"tweets","status":"created","name":"nightly",` +
+	`"upload_url":"https://upload.example.com/j1","upload_expires_at":"2022-10-01T12:15:00.000Z",` +
+	`"download_url":"https://download.example.com/j1","download_expires_at":"2022-10-08T12:00:00.000Z"}`
+
+func TestJobs(t *testing.T) {
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /2/compliance/jobs":
+			body, _ := io.ReadAll(r.Body)
+			if got, want := string(body), `{"type":"tweets","name":"nightly"}`; got != want {
+				t.Errorf("Create body: got %s, want %s", got, want)
+			}
+			io.WriteString(w, `{"data":`+testJob+`}`)
+		case "GET /2/compliance/jobs":
+			q := r.URL.Query()
+			if q.Get("type") != "tweets" || q.Get("status") != "created" {
+				t.Errorf("List: unexpected query %q", r.URL.RawQuery)
+			}
+			io.WriteString(w, `{"data":[`+testJob+`]}`)
+		case "GET /2/compliance/jobs/j1":
+			io.WriteString(w, `{"data":`+testJob+`}`)
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL)
+			http.NotFound(w, r)
+		}
+	}))
+	ctx := context.Background()
+
+	for _, test := range []struct {
+		name string
+		q    compliance.Query
+	}{
+		{"Create", compliance.Create(compliance.TweetsJob, &compliance.CreateOpts{Name: "nightly"})},
+		{"List", compliance.List(compliance.TweetsJob, &compliance.ListOpts{Status: compliance.StatusCreated})},
+		{"Lookup", compliance.Lookup("j1")},
+	} {
+		rsp, err := test.q.Invoke(ctx, cli)
+		if err != nil {
+			t.Errorf("%s failed: %v", test.name, err)
+			continue
+		}
+		if len(rsp.Jobs) != 1 {
+			t.Errorf("%s: got %d jobs, want 1", test.name, len(rsp.Jobs))
+			continue
+		}
+		job := rsp.Jobs[0]
+		if job.ID != "j1" || job.Status != compliance.StatusCreated || job.UploadURL == "" {
+			t.Errorf("%s: got job %+v", test.name, job)
+		}
+		if job.UploadExpiresAt == nil || job.UploadExpiresAt.Minute() != 15 {
+			t.Errorf("%s: upload expiration is %v", test.name, job.UploadExpiresAt)
+		}
+	}
+}
+
+func TestUpload(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.URL.Path != "/upload/j1" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "text/plain" {
+			t.Errorf("Content-Type: got %q, want text/plain", ct)
+		}
+		if r.Header.Get("Authorization") != "" {
+			t.Error("Upload sent an authorization header")
+		}
+		if r.ContentLength <= 0 {
+			t.Errorf("Content-Length: got %d, want > 0", r.ContentLength)
+		}
+		body, _ := io.ReadAll(r.Body)
+		got = string(body)
+	}))
+	defer srv.Close()
+	cli := twitter.NewClient(&jape.Client{
+		Authorize: jape.BearerTokenAuthorizer("token"),
+	})
+	ctx := context.Background()
+
+	ids, err := os.CreateTemp(t.TempDir(), "ids")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ids.Close()
+	const want = "1\n2\n3\n"
+	if _, err := io.WriteString(ids, want); err != nil {
+		t.Fatal(err)
+	}
+	ids.Seek(0, io.SeekStart)
+
+	job := &compliance.Job{ID: "j1", UploadURL: srv.URL + "/upload/j1"}
+	if err := compliance.Upload(ctx, cli, job, ids); err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+	if got != want {
+		t.Errorf("Upload: got body %q, want %q", got, want)
+	}
+
+	past := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	job.UploadExpiresAt = &past
+	if err := compliance.Upload(ctx, cli, job, strings.NewReader(want)); err == nil {
+		t.Error("Upload with an expired URL: got nil error")
+	}
+}
+
+func TestDownload(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"id":"1","action":"delete","created_at":"2022-10-01T00:00:00.000Z","redacted_at":"2022-10-02T00:00:00.000Z","reason":"deleted"}
+{"id":"2","action":"delete","reason":"protected"}
+{"id":"3","action":"delete","reason":"scrub_geo"}
+`)
+	}))
+	defer srv.Close()
+	cli := twitter.NewClient(&jape.Client{})
+	job := &compliance.Job{ID: "j1", DownloadURL: srv.URL + "/download/j1"}
+
+	var got []string
+	if err := compliance.Download(context.Background(), cli, job, func(evt *compliance.Event) error {
+		got = append(got, evt.ID+":"+evt.Reason)
+		if evt.Reason == compliance.ReasonProtected {
+			return jape.ErrStopStreaming
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+	if want := "1:deleted 2:protected"; strings.Join(got, " ") != want {
+		t.Errorf("Download: got %q, want %q", got, want)
+	}
+}
+
+func TestRun(t *testing.T) {
+	var base string
+	var checks int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		job := func(status string) string {
+			return `{"data":{"id":"j1","type":"users","status":"` + status + `",` +
+				`"upload_url":"` + base + `/upload","download_url":"` + base + `/download"}}`
+		}
+		switch r.Method + " " + r.URL.Path {
+		case "POST /2/compliance/jobs":
+			io.WriteString(w, job(compliance.StatusCreated))
+		case "PUT /upload":
+			if body, _ := io.ReadAll(r.Body); string(body) != "12\n" {
+				t.Errorf("Upload: got body %q", body)
+			}
+		case "GET /2/compliance/jobs/j1":
+			if checks++; checks < 3 {
+				io.WriteString(w, job(compliance.StatusInProgress))
+			} else {
+				io.WriteString(w, job(compliance.StatusComplete))
+			}
+		case "GET /download":
+			io.WriteString(w, `{"id":"12","action":"delete","reason":"deactivated"}`+"\n")
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL)
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	base = srv.URL
+	cli := twitter.NewClient(&jape.Client{BaseURL: srv.URL})
+
+	evts, err := compliance.Run(context.Background(), cli, compliance.UsersJob,
+		strings.NewReader("12\n"), &compliance.RunOpts{PollInterval: time.Millisecond})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(evts) != 1 || evts[0].ID != "12" || evts[0].Reason != compliance.ReasonDeactivated {
+		t.Errorf("Run: got events %+v", evts)
+	}
+	if checks != 3 {
+		t.Errorf("Run: got %d status checks, want 3", checks)
+	}
+}
+
+func TestStream(t *testing.T) {
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2/tweets/compliance/stream" {
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("partition"); got != "2" {
+			t.Errorf("Parameter partition: got %q, want 2", got)
+		}
+		io.WriteString(w, `{"data":{"delete":{"tweet":{"id":"1","author_id":"9"},"event_at":"2022-10-01T00:00:00.000Z"}}}`+"\r\n")
+		io.WriteString(w, `{"data":{"withheld":{"tweet":{"id":"2","author_id":"9"},"withheld_in_countries":["DE","FR"]}}}`+"\r\n")
+	}))
+
+	var got []string
+	err := compliance.TweetStream(2, func(rsp *compliance.StreamReply) error {
+		evt := rsp.Event
+		got = append(got, evt.Type+":"+evt.Tweet.ID+":"+strings.Join(evt.WithheldIn, ","))
+		return nil
+	}, nil).Invoke(context.Background(), cli)
+	if err != nil {
+		t.Fatalf("TweetStream failed: %v", err)
+	}
+	if want := "delete:1: withheld:2:DE,FR"; strings.Join(got, " ") != want {
+		t.Errorf("TweetStream: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package compliance
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/jape"
+)
+
+// DefaultPollInterval is the default initial interval between status checks
+// when Run waits for a job to complete.
+const DefaultPollInterval = 10 * time.Second
+
+// maxPollInterval bounds the interval between status checks in Run.
+const maxPollInterval = 5 * time.Minute
+
+// Run performs a complete compliance check: it creates a job of the given
+// type, uploads the IDs read from ids (see Upload), waits for the job to
+// complete, and returns the events reported in its results (see Download).
+//
+//	evts, err := compliance.Run(ctx, cli, compliance.TweetsJob, ids, nil)
+//
+// While waiting, Run checks the status of the job at increasing intervals,
+// starting from the interval in opts. If the job fails or expires, Run
+// reports an error. If ctx ends before the job is complete, Run reports the
+// error from the context; the job continues on the server, and its ID is
+// included in the error message.
+func Run(ctx context.Context, cli *twitter.Client, jobType string, ids io.Reader, opts *RunOpts) ([]*Event, error) {
+	rsp, err := Create(jobType, opts.createOpts()).Invoke(ctx, cli)
+	if err != nil {
+		return nil, err
+	} else if len(rsp.Jobs) != 1 {
+		return nil, fmt.Errorf("create job: got %d jobs, want 1", len(rsp.Jobs))
+	}
+	job := rsp.Jobs[0]
+	if err := Upload(ctx, cli, job, ids); err != nil {
+		return nil, err
+	}
+
+	jc := (*jape.Client)(cli)
+	wait := opts.interval()
+	for job.Status != StatusComplete {
+		switch job.Status {
+		case StatusFailed, StatusExpired:
+			return nil, fmt.Errorf("job %s %s", job.ID, job.Status)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("job %s: %w", job.ID, ctx.Err())
+		case <-jc.After(wait):
+		}
+		if wait *= 2; wait > maxPollInterval {
+			wait = maxPollInterval
+		}
+
+		q := Lookup(job.ID)
+		q.NoCache = true // the job status changes between lookups
+		rsp, err := q.Invoke(ctx, cli)
+		if err != nil {
+			return nil, err
+		} else if len(rsp.Jobs) != 1 {
+			return nil, fmt.Errorf("job %s: got %d jobs, want 1", job.ID, len(rsp.Jobs))
+		}
+		job = rsp.Jobs[0]
+	}
+
+	var evts []*Event
+	if err := Download(ctx, cli, job, func(evt *Event) error {
+		evts = append(evts, evt)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return evts, nil
+}
+
+// RunOpts provides parameters for Run. A nil *RunOpts provides empty values
+// for all fields.
+type RunOpts struct {
+	// A name for the job (see CreateOpts).
+	Name string
+
+	// The initial interval between status checks. If zero, use
+	// DefaultPollInterval. The interval doubles after each check.
+	PollInterval time.Duration
+}
+
+func (o *RunOpts) createOpts() *CreateOpts {
+	if o == nil {
+		return nil
+	}
+	return &CreateOpts{Name: o.Name}
+}
+
+func (o *RunOpts) interval() time.Duration {
+	if o == nil || o.PollInterval <= 0 {
+		return DefaultPollInterval
+	}
+	return o.PollInterval
+}
@@ -0,0 +1,168 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package compliance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/jape"
+	"github.com/928799934/twitter/types"
+)
+
+// TweetStream constructs a query for the given partition of the tweet
+// compliance stream, which delivers compliance events for tweets (such as
+// deletions and withholdings) in real time as they occur. Partitions are
+// numbered from 1. This endpoint requires Enterprise access.
+//
+//	err := compliance.TweetStream(1, func(rsp *compliance.StreamReply) error {
+//	   if rsp.Event != nil && rsp.Event.Type == "delete" {
+//	      forget(rsp.Event.Tweet.ID)
+//	   }
+//	   return nil
+//	}, nil).Managed(nil).Run(ctx, cli)
+//
+// API: 2/tweets/compliance/stream
+func TweetStream(partition int, f Callback, opts *StreamOpts) Stream {
+	return newStream("2/tweets/compliance/stream", partition, f, opts)
+}
+
+// UserStream constructs a query for the given partition of the user
+// compliance stream, which delivers compliance events for users (such as
+// deletions, suspensions, and protections) in real time as they occur.
+// Partitions are numbered from 1. This endpoint requires Enterprise access.
+//
+// API: 2/users/compliance/stream
+func UserStream(partition int, f Callback, opts *StreamOpts) Stream {
+	return newStream("2/users/compliance/stream", partition, f, opts)
+}
+
+func newStream(method string, partition int, f Callback, opts *StreamOpts) Stream {
+	req := &jape.Request{
+		Method: method,
+		Params: make(jape.Params),
+	}
+	req.Params.Set("partition", strconv.Itoa(partition))
+	opts.addRequestParams(req)
+	return Stream{Request: req, callback: f}
+}
+
+// StreamOpts provides parameters for compliance streams. A nil *StreamOpts
+// provides empty values for all fields.
+type StreamOpts struct {
+	// If set, deliver events from this UTC time, to recover events sent while
+	// the caller was not connected. The server permits times up to five
+	// minutes in the past.
+	StartTime time.Time
+
+	// If set, end the stream after delivering events up to this UTC time.
+	EndTime time.Time
+}
+
+func (o *StreamOpts) addRequestParams(req *jape.Request) {
+	if o == nil {
+		return // nothing to do
+	}
+	if !o.StartTime.IsZero() {
+		req.Params.Set("start_time", o.StartTime.Format(types.DateFormat))
+	}
+	if !o.EndTime.IsZero() {
+		req.Params.Set("end_time", o.EndTime.Format(types.DateFormat))
+	}
+}
+
+// A Callback receives streaming replies from a compliance stream. If the
+// callback returns an error, the stream is terminated. If the error is not
+// jape.ErrStopStreaming, that error is reported to the caller.
+//
+// A system message from the server is delivered as a reply with no event,
+// whose System field is set (see twitter.Reply).
+type Callback func(*StreamReply) error
+
+// A Stream performs a compliance stream query.
+type Stream struct {
+	*jape.Request
+	callback Callback
+}
+
+// Invoke executes the streaming query on the given context and client.
+func (s Stream) Invoke(ctx context.Context, cli *twitter.Client) error {
+	return cli.Stream(ctx, s.Request, s.handler)
+}
+
+// Managed returns a managed stream for s, which automatically reconnects
+// after disconnects according to cfg (see twitter.ManagedStream).
+func (s Stream) Managed(cfg *twitter.StreamConfig) *twitter.ManagedStream {
+	return twitter.NewManagedStream(s.Request, s.handler, cfg)
+}
+
+// handler decodes stream replies and delivers them to the callback of s.
+func (s Stream) handler(rsp *twitter.Reply) error {
+	if rsp.System != nil {
+		return s.callback(&StreamReply{Reply: rsp}) // no event data
+	}
+	evt, err := decodeStreamEvent(rsp.Data)
+	if err != nil {
+		return &jape.Error{Data: rsp.Data, Message: "decoding compliance event", Err: err}
+	}
+	return s.callback(&StreamReply{Reply: rsp, Event: evt})
+}
+
+// A StreamReply is a single message from a compliance stream.
+type StreamReply struct {
+	*twitter.Reply
+	Event *StreamEvent
+}
+
+// A StreamEvent is a compliance event delivered by a compliance stream.
+// Which fields are populated depends on the type of the event.
+type StreamEvent struct {
+	// The type of the event, e.g., "delete", "withheld", "tweet_edit",
+	// "user_suspend", or "scrub_geo".
+	Type string `json:"-"`
+
+	Tweet   *Target    `json:"tweet,omitempty"` // for tweet events
+	User    *Target    `json:"user,omitempty"`  // for user events
+	EventAt *time.Time `json:"event_at,omitempty"`
+
+	// For withheld events, the countries in which the content is withheld.
+	WithheldIn []string `json:"withheld_in_countries,omitempty"`
+
+	// For scrub_geo events, geo data are removed from the user's tweets up to
+	// and including this ID.
+	UpToTweetID string `json:"up_to_tweet_id,omitempty"`
+
+	// For tweet_edit events, the ID of the original tweet, and the IDs of all
+	// the versions of the tweet, oldest first.
+	InitialTweetID string   `json:"initial_tweet_id,omitempty"`
+	EditTweetIDs   []string `json:"edit_tweet_ids,omitempty"`
+}
+
+// A Target identifies the tweet or user affected by a compliance event.
+type Target struct {
+	ID       string `json:"id"`
+	AuthorID string `json:"author_id,omitempty"` // for tweets
+}
+
+// decodeStreamEvent decodes the data of a compliance stream message, which is
+// an object with a single field naming the type of the event.
+func decodeStreamEvent(data []byte) (*StreamEvent, error) {
+	var msg map[string]json.RawMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, err
+	} else if len(msg) != 1 {
+		return nil, fmt.Errorf("got %d event types, want 1", len(msg))
+	}
+	evt := new(StreamEvent)
+	for kind, body := range msg {
+		evt.Type = kind
+		if err := json.Unmarshal(body, evt); err != nil {
+			return nil, err
+		}
+	}
+	return evt, nil
+}
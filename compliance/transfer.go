@@ -0,0 +1,152 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package compliance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/jape"
+	"github.com/928799934/twitter/types"
+)
+
+// Upload uploads the IDs to check for a compliance job to the job's upload
+// URL. The IDs are read from ids, which should contain one tweet or user ID
+// per line, matching the type of the job. The upload URL is pre-signed, so
+// the request does not use the authorization of cli, only its HTTP client.
+//
+// The upload URL must be used before it expires (see Job.UploadExpiresAt);
+// Upload reports an error without sending anything if it has expired. Note
+// that the upload must state its length: if ids is an io.Seeker, Upload uses
+// it to find the length, otherwise the body is sent with chunked encoding,
+// which the upload service may reject.
+func Upload(ctx context.Context, cli *twitter.Client, job *Job, ids io.Reader) error {
+	jc := (*jape.Client)(cli)
+	if job.UploadURL == "" {
+		return errors.New("job has no upload URL")
+	} else if t := job.UploadExpiresAt; t != nil && !jc.Now().Before(*t) {
+		return &jape.Error{Message: "upload URL expired at " + t.Format(types.DateFormat)}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, job.UploadURL, ids)
+	if err != nil {
+		return err
+	}
+	if req.ContentLength == 0 {
+		if s, ok := ids.(io.Seeker); ok {
+			if req.ContentLength, err = remaining(s); err != nil {
+				return err
+			}
+		}
+	}
+	req.Header.Set("Content-Type", "text/plain")
+	rsp, err := do(jc, req)
+	if err != nil {
+		return err
+	}
+	rsp.Body.Close()
+	return nil
+}
+
+// Download downloads the results of a completed compliance job from the job's
+// download URL, and calls f with each compliance event in turn. As with
+// Upload, the request uses only the HTTP client of cli.
+//
+// If f reports an error, the download ends. If the error is not
+// jape.ErrStopStreaming, that error is reported to the caller; otherwise
+// Download returns nil. Download reports an error without sending anything
+// if the download URL has expired (see Job.DownloadExpiresAt).
+func Download(ctx context.Context, cli *twitter.Client, job *Job, f func(*Event) error) error {
+	jc := (*jape.Client)(cli)
+	if job.DownloadURL == "" {
+		return errors.New("job has no download URL")
+	} else if t := job.DownloadExpiresAt; t != nil && !jc.Now().Before(*t) {
+		return &jape.Error{Message: "download URL expired at " + t.Format(types.DateFormat)}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, job.DownloadURL, nil)
+	if err != nil {
+		return err
+	}
+	rsp, err := do(jc, req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	dec := json.NewDecoder(rsp.Body)
+	for {
+		var evt Event
+		if err := dec.Decode(&evt); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return &jape.Error{Message: "decoding compliance event", Err: err}
+		}
+		if err := f(&evt); errors.Is(err, jape.ErrStopStreaming) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// Constants for the reason reported with a compliance Event.
+const (
+	ReasonDeleted     = "deleted"
+	ReasonDeactivated = "deactivated"
+	ReasonSuspended   = "suspended"
+	ReasonProtected   = "protected"
+	ReasonScrubGeo    = "scrub_geo"
+)
+
+// An Event is a single result of a compliance job, reporting a change to one
+// of the tweets or users checked by the job.
+type Event struct {
+	ID         string     `json:"id"`
+	Action     string     `json:"action"` // e.g., "delete"
+	Reason     string     `json:"reason"` // e.g., ReasonDeleted
+	CreatedAt  *time.Time `json:"created_at,omitempty"`
+	RedactedAt *time.Time `json:"redacted_at,omitempty"`
+}
+
+// remaining reports the number of bytes from the current offset of s to its
+// end, and leaves the offset of s unchanged.
+func remaining(s io.Seeker) (int64, error) {
+	cur, err := s.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	end, err := s.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	_, err = s.Seek(cur, io.SeekStart)
+	return end - cur, err
+}
+
+// do issues req with the HTTP client of jc, and reports an error if the
+// request fails or does not succeed. On success, the caller must close the
+// body of the response.
+func do(jc *jape.Client, req *http.Request) (*http.Response, error) {
+	hc := jc.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	rsp, err := hc.Do(req)
+	if err != nil {
+		return nil, &jape.Error{Message: "issuing request", Err: err}
+	}
+	if rsp.StatusCode < 200 || rsp.StatusCode >= 300 {
+		defer rsp.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(rsp.Body, 4096))
+		return nil, &jape.Error{
+			Message: "request failed: " + rsp.Status,
+			Status:  rsp.StatusCode,
+			Data:    data,
+		}
+	}
+	return rsp, nil
+}
@@ -0,0 +1,184 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+// Package dms supports queries for Direct Message events.
+//
+// To list the recent Direct Message events of the authenticated user, use
+// dms.Events. The results are paginated:
+//
+//	for it := dms.Events(nil).Iterate(cli); it.Next(ctx); {
+//	   process(it.Event())
+//	}
+//
+// To read the history of a single conversation, use dms.ConversationEvents
+// with the conversation ID, or dms.EventsWith for the one-to-one conversation
+// with a particular user.
+//
+// By default only the default fields are returned (see types.DMEvent).  To
+// request additional fields or expansions, include them in the options:
+//
+//	q := dms.Events(&dms.ListOpts{
+//	   EventTypes: []string{types.DMMessageCreate},
+//	   Optional: []types.Fields{
+//	      types.DMEventFields{SenderID: true, CreatedAt: true},
+//	      types.Expansions{SenderID: true},
+//	   },
+//	})
+//
+// These queries require user-context authorization.
+package dms
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/jape"
+	"github.com/928799934/twitter/types"
+)
+
+// Events constructs a query for the Direct Message events of the
+// authenticated user, in all of their conversations, newest first.
+//
+// API: 2/dm_events
+func Events(opts *ListOpts) Query {
+	req := &jape.Request{
+		Method: "2/dm_events",
+		Params: make(jape.Params),
+	}
+	opts.addRequestParams(req)
+	return Query{Request: req}
+}
+
+// ConversationEvents constructs a query for the Direct Message events in the
+// conversation with the given ID, newest first.
+//
+// API: 2/dm_conversations/:id/dm_events
+func ConversationEvents(convID string, opts *ListOpts) Query {
+	req := &jape.Request{
+		Method: "2/dm_conversations/" + convID + "/dm_events",
+		Params: make(jape.Params),
+	}
+	opts.addRequestParams(req)
+	return Query{Request: req}
+}
+
+// EventsWith constructs a query for the Direct Message events in the one-to-one
+// conversation between the authenticated user and the given user ID, newest
+// first.
+//
+// API: 2/dm_conversations/with/:participant_id/dm_events
+func EventsWith(participantID string, opts *ListOpts) Query {
+	req := &jape.Request{
+		Method: "2/dm_conversations/with/" + participantID + "/dm_events",
+		Params: make(jape.Params),
+	}
+	opts.addRequestParams(req)
+	return Query{Request: req}
+}
+
+// A Query performs a query for Direct Message events.
+type Query struct {
+	*jape.Request
+}
+
+// Invoke executes the query on the given context and client. If the reply
+// contains a pagination token, q is updated in-place so that invoking the
+// query again will fetch the next page.
+func (q Query) Invoke(ctx context.Context, cli *twitter.Client) (*Reply, error) {
+	rsp, err := cli.Call(ctx, q.Request)
+	if err != nil {
+		return nil, err
+	}
+	out := &Reply{Reply: rsp}
+	if len(rsp.Data) == 0 {
+		// no results
+	} else if err := json.Unmarshal(rsp.Data, &out.Events); err != nil {
+		return nil, &jape.Error{Data: rsp.Data, Message: "decoding DM event data", Err: err}
+	}
+	q.Request.Params.Set(twitter.NextTokenParam, "")
+	if len(rsp.Meta) != 0 {
+		if err := json.Unmarshal(rsp.Meta, &out.Meta); err != nil {
+			return nil, &jape.Error{Data: rsp.Meta, Message: "decoding response metadata", Err: err}
+		}
+		// Update the query page token. Do this even if next_token is empty; the
+		// HasMorePages method uses the presence of the parameter to distinguish
+		// a fresh query from end-of-pages.
+		q.Request.Params.Set(twitter.NextTokenParam, out.Meta.NextToken)
+	}
+	return out, nil
+}
+
+// HasMorePages reports whether the query has more pages to fetch. This is true
+// for a freshly-constructed query, and for an invoked query where the server
+// has reported a next-page token.
+func (q Query) HasMorePages() bool {
+	v, ok := q.Request.Params[twitter.NextTokenParam]
+	return !ok || v[0] != ""
+}
+
+// ResetPageToken clears (resets) the query's current page token. Subsequently
+// invoking the query will then fetch the first page of results.
+func (q Query) ResetPageToken() { q.Request.Params.Reset(twitter.NextTokenParam) }
+
+// PageToken returns the page token the query will send when it is next
+// invoked, or "" if the query is fresh or has no more pages.
+func (q Query) PageToken() string { return q.Request.Params.Get(twitter.NextTokenParam) }
+
+// Iterate returns an iterator over the events reported by q, which fetches
+// pages of results from cli as needed.
+func (q Query) Iterate(cli *twitter.Client) Iterator {
+	return Iterator{twitter.NewIterator[*Reply, *types.DMEvent](cli, q, func(r *Reply) []*types.DMEvent {
+		return r.Events
+	})}
+}
+
+// An Iterator yields the events reported by a paginated query.
+type Iterator struct {
+	*twitter.Iterator[*Reply, *types.DMEvent]
+}
+
+// Event returns the current event. It is valid only after Next returns true.
+func (it Iterator) Event() *types.DMEvent { return it.Item() }
+
+// A Reply is the response from a Query.
+type Reply struct {
+	*twitter.Reply
+	Events types.DMEvents
+	Meta   *twitter.Pagination
+}
+
+// ListOpts provide parameters for listing Direct Message events. A nil
+// *ListOpts provides empty values for all fields.
+type ListOpts struct {
+	// A pagination token provided by the server.
+	PageToken string
+
+	// The maximum number of results to return; 0 means let the server choose.
+	// The service will accept values up to 100.
+	MaxResults int
+
+	// If set, report only events of these types (see types.DMMessageCreate).
+	EventTypes []string
+
+	// Optional response fields and expansions.
+	Optional []types.Fields
+}
+
+func (o *ListOpts) addRequestParams(req *jape.Request) {
+	if o == nil {
+		return // nothing to do
+	}
+	if o.PageToken != "" {
+		req.Params.Set(twitter.NextTokenParam, o.PageToken)
+	}
+	if o.MaxResults > 0 {
+		req.Params.Set("max_results", strconv.Itoa(o.MaxResults))
+	}
+	req.Params.Add("event_types", o.EventTypes...)
+	for _, fs := range o.Optional {
+		if vs := fs.Values(); len(vs) != 0 {
+			req.Params.Add(fs.Label(), vs...)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package dms_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/928799934/twitter/dms"
+	"github.com/928799934/twitter/internal/otest"
+	"github.com/928799934/twitter/types"
+)
+
+func TestEvents(t *testing.T) {
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2/dm_events" {
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+		q := r.URL.Query()
+		for param, want := range map[string]string{
+			"event_types":     "MessageCreate",
+			"dm_event.fields": "sender_id",
+			"expansions":      "sender_id",
+		} {
+			if got := q.Get(param); got != want {
+				t.Errorf("Parameter %s: got %q, want %q", param, got, want)
+			}
+		}
+		switch q.Get("pagination_token") {
+		case "":
+			io.WriteString(w, `{"data":[{"id":"e1","event_type":"MessageCreate","text":"hi","sender_id":"u1"}],`+
+				`"includes":{"users":[{"id":"u1","username":"alice"}]},"meta":{"result_count":1,"next_token":"p2"}}`)
+		case "p2":
+			io.WriteString(w, `{"data":[{"id":"e2","event_type":"MessageCreate","text":"bye","sender_id":"u2"}],`+
+				`"meta":{"result_count":1}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	var got []string
+	it := dms.Events(&dms.ListOpts{
+		EventTypes: []string{types.DMMessageCreate},
+		Optional: []types.Fields{
+			types.DMEventFields{SenderID: true},
+			types.Expansions{SenderID: true},
+		},
+	}).Iterate(cli)
+	for it.Next(context.Background()) {
+		evt := it.Event()
+		got = append(got, evt.ID+":"+evt.SenderID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Events failed: %v", err)
+	}
+	if want := "e1:u1 e2:u2"; strings.Join(got, " ") != want {
+		t.Errorf("Events: got %q, want %q", got, want)
+	}
+}
+
+func TestConversation(t *testing.T) {
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2/dm_conversations/c1/dm_events":
+			io.WriteString(w, `{"data":[{"id":"e1","event_type":"ParticipantsJoin","participant_ids":["u2"]}]}`)
+		case "/2/dm_conversations/with/u2/dm_events":
+			if got := r.URL.Query().Get("max_results"); got != "5" {
+				t.Errorf("Parameter max_results: got %q, want 5", got)
+			}
+			io.WriteString(w, `{"data":[{"id":"e2","event_type":"MessageCreate","text":"hi"}]}`)
+		default:
+			t.Errorf("Unexpected path %q", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	ctx := context.Background()
+
+	rsp, err := dms.ConversationEvents("c1", nil).Invoke(ctx, cli)
+	if err != nil {
+		t.Fatalf("ConversationEvents failed: %v", err)
+	}
+	if len(rsp.Events) != 1 || rsp.Events[0].EventType != types.DMParticipantsJoin {
+		t.Errorf("ConversationEvents: got %+v, want one join event", rsp.Events)
+	}
+
+	q := dms.EventsWith("u2", &dms.ListOpts{MaxResults: 5})
+	rsp, err = q.Invoke(ctx, cli)
+	if err != nil {
+		t.Fatalf("EventsWith failed: %v", err)
+	}
+	if len(rsp.Events) != 1 || rsp.Events[0].Text != "hi" {
+		t.Errorf("EventsWith: got %+v, want one message", rsp.Events)
+	}
+	if q.HasMorePages() {
+		t.Error("EventsWith: HasMorePages is true without a next token")
+	}
+}
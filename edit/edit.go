@@ -18,7 +18,8 @@ import (
 func DeleteTweet(tweetID string) Query {
 	return Query{
 		Request: &jape.Request{
-			Method:     "2/tweets/" + tweetID,
+			Method:     "2/tweets/:tid",
+			PathParams: map[string]string{"tid": tweetID},
 			HTTPMethod: "DELETE",
 		},
 		tag: "deleted",
@@ -42,6 +43,9 @@ func (e Query) Invoke(ctx context.Context, cli *twitter.Client) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	if len(rsp.Data) == 0 {
+		return false, nil // no result, e.g., in dry-run mode
+	}
 	m := make(map[string]*bool)
 	if err := json.Unmarshal(rsp.Data, &m); err != nil {
 		return false, &jape.Error{Data: rsp.Data, Message: "decoding response", Err: err}
@@ -124,8 +128,8 @@ func Bookmark(userID, tweetID string) Query {
 	}
 }
 
-// Unbookmark constructs a query for the given user ID to un-like the given
-// tweet ID.
+// Unbookmark constructs a query for the given user ID to remove the bookmark
+// for the given tweet ID.
 //
 // API: DELETE 2/users/:id/bookmarks/:tid
 func Unbookmark(userID, tweetID string) Query {
@@ -270,6 +274,38 @@ func Unmute(userID, muteeID string) Query {
 	}
 }
 
+// FollowList constructs a query for one user ID to follow a list ID.
+//
+// API: POST 2/users/:id/followed_lists
+func FollowList(userID, listID string) Query {
+	body, err := json.Marshal(struct {
+		ID string `json:"list_id"`
+	}{ID: listID})
+	return Query{
+		Request: &jape.Request{
+			Method:      "2/users/" + userID + "/followed_lists",
+			HTTPMethod:  "POST",
+			ContentType: "application/json",
+			Data:        body,
+		},
+		tag:       "following",
+		encodeErr: err,
+	}
+}
+
+// UnfollowList constructs a query for one user ID to un-follow a list ID.
+//
+// API: DELETE 2/users/:id/followed_lists/:lid
+func UnfollowList(userID, listID string) Query {
+	return Query{
+		Request: &jape.Request{
+			Method:     "2/users/" + userID + "/followed_lists/" + listID,
+			HTTPMethod: "DELETE",
+		},
+		tag: "following",
+	}
+}
+
 // PinList constructs a query for one user ID to pin a list ID.
 //
 // API: POST 2/users/:id/pinned_lists
@@ -292,7 +328,7 @@ func PinList(userID, listID string) Query {
 // UnpinList constructs a query for one user ID to un-pin a list ID.
 //
 // API: DELETE 2/users/:id/pinned_lists/:lid
-func UnpinLists(userID, listID string) Query {
+func UnpinList(userID, listID string) Query {
 	return Query{
 		Request: &jape.Request{
 			Method:     "2/users/" + userID + "/pinned_lists/" + listID,
@@ -301,3 +337,8 @@ func UnpinLists(userID, listID string) Query {
 		tag: "pinned",
 	}
 }
+
+// UnpinLists is a former name for UnpinList.
+//
+// Deprecated: Use UnpinList instead.
+func UnpinLists(userID, listID string) Query { return UnpinList(userID, listID) }
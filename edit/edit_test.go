@@ -0,0 +1,73 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package edit_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/edit"
+	"github.com/928799934/twitter/jape"
+)
+
+func TestDeleteTweet(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" || r.URL.EscapedPath() != "/2/tweets/12%2F345" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.EscapedPath())
+		}
+		io.WriteString(w, `{"data":{"deleted":true}}`)
+	}))
+	defer srv.Close()
+	cli := twitter.NewClient(&jape.Client{BaseURL: srv.URL})
+
+	// The ID is escaped, so it cannot address a different path.
+	ok, err := edit.DeleteTweet("12/345").Invoke(context.Background(), cli)
+	if err != nil {
+		t.Fatalf("DeleteTweet failed: %v", err)
+	}
+	if !ok {
+		t.Error("DeleteTweet: got false, want true")
+	}
+
+	if _, err := edit.DeleteTweet("").Invoke(context.Background(), cli); err == nil {
+		t.Error("DeleteTweet with no ID: got nil error")
+	}
+}
+
+func TestListEdits(t *testing.T) {
+	var method, path, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		method, path, body = r.Method, r.URL.Path, string(data)
+		io.WriteString(w, `{"data":{"following":true,"pinned":true}}`)
+	}))
+	defer srv.Close()
+	cli := twitter.NewClient(&jape.Client{BaseURL: srv.URL})
+
+	tests := []struct {
+		query              edit.Query
+		method, path, body string
+	}{
+		{edit.FollowList("12", "99"), "POST", "/2/users/12/followed_lists", `{"list_id":"99"}`},
+		{edit.UnfollowList("12", "99"), "DELETE", "/2/users/12/followed_lists/99", ""},
+		{edit.PinList("12", "99"), "POST", "/2/users/12/pinned_lists", `{"list_id":"99"}`},
+		{edit.UnpinList("12", "99"), "DELETE", "/2/users/12/pinned_lists/99", ""},
+	}
+	for _, test := range tests {
+		ok, err := test.query.Invoke(context.Background(), cli)
+		if err != nil {
+			t.Errorf("Invoke %s %s failed: %v", test.method, test.path, err)
+			continue
+		}
+		if !ok {
+			t.Errorf("Invoke %s %s: got false, want true", test.method, test.path)
+		}
+		if method != test.method || path != test.path || body != test.body {
+			t.Errorf("Request: got %s %s %q, want %s %s %q", method, path, body, test.method, test.path, test.body)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package twitter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/928799934/twitter/jape"
+	"github.com/928799934/twitter/types"
+)
+
+// A Problem is the type URL of a problem reported by the API. A Problem can
+// be used as the target of errors.Is to check for a particular problem in an
+// error reported by the client:
+//
+//	if errors.Is(err, twitter.ProblemResourceNotFound) {
+//	   // ...
+//	}
+//
+// See https://developer.twitter.com/en/support/twitter-api/error-troubleshooting
+type Problem string
+
+// Error satisfies the error interface.
+func (p Problem) Error() string { return "problem: " + path.Base(string(p)) }
+
+// Known problem types reported by the API.
+const (
+	ProblemInvalidRequest        Problem = "https://api.twitter.com/2/problems/invalid-request"
+	ProblemResourceNotFound      Problem = "https://api.twitter.com/2/problems/resource-not-found"
+	ProblemNotAuthorized         Problem = "https://api.twitter.com/2/problems/not-authorized-for-resource"
+	ProblemClientForbidden       Problem = "https://api.twitter.com/2/problems/client-forbidden"
+	ProblemDisallowedResource    Problem = "https://api.twitter.com/2/problems/disallowed-resource"
+	ProblemUnsupportedAuth       Problem = "https://api.twitter.com/2/problems/unsupported-authentication"
+	ProblemUsageCapped           Problem = "https://api.twitter.com/2/problems/usage-capped"
+	ProblemConnectionException   Problem = "https://api.twitter.com/2/problems/streaming-connection"
+	ProblemClientDisconnected    Problem = "https://api.twitter.com/2/problems/client-disconnected"
+	ProblemOperationalDisconnect Problem = "https://api.twitter.com/2/problems/operational-disconnect"
+	ProblemRuleCap               Problem = "https://api.twitter.com/2/problems/rule-cap"
+	ProblemRuleLength            Problem = "https://api.twitter.com/2/problems/rule-length"
+	ProblemInvalidRules          Problem = "https://api.twitter.com/2/problems/invalid-rules"
+	ProblemDuplicateRules        Problem = "https://api.twitter.com/2/problems/duplicate-rules"
+)
+
+// An Error describes a problem reported by the API in an error response.
+// When the server reports a problem, the error returned by Call, CallRaw, or
+// Stream is a *jape.Error whose Err field is an *Error.
+//
+// The fields of an Error are decoded from the problem envelope of the response.
+// The raw response body remains available in the Data field of the *jape.Error.
+type Error struct {
+	Type   Problem // the problem type URL
+	Title  string  // e.g., "Not Found Error"
+	Detail string  // for human consumption
+	Status int     // the HTTP status code
+
+	// Details of the individual errors reported in the envelope, if any.
+	// For example, an invalid request reports an error for each invalid
+	// parameter.
+	Errors []*types.ErrorDetail
+
+	// A more specific error for this problem, if any (e.g., *UsageCapError).
+	Err error
+}
+
+// Error satisfies the error interface.
+func (e *Error) Error() string {
+	if e.Detail != "" {
+		return e.Detail
+	} else if e.Title != "" {
+		return e.Title
+	}
+	return string(e.Type)
+}
+
+// Is reports whether target is the Problem type of e, or of any of the
+// individual errors reported by e.
+func (e *Error) Is(target error) bool {
+	p, ok := target.(Problem)
+	if !ok || p == "" {
+		return false
+	} else if p == e.Type {
+		return true
+	}
+	for _, d := range e.Errors {
+		if Problem(d.TypeURL) == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Unwrap supports error wrapping.
+func (e *Error) Unwrap() error { return e.Err }
+
+// A UsageCapError reports that the caller has exceeded the usage cap for
+// their product or project. Unlike an ordinary rate limit, which resets after
+// a short window (see RateLimit), the usage cap does not reset until the start
+// of the next billing period.
+//
+// When the API reports a usage cap (ProblemUsageCapped), the *Error reported
+// by the client wraps a *UsageCapError. Use errors.As to detect it:
+//
+//	var uc *twitter.UsageCapError
+//	if errors.As(err, &uc) {
+//	   log.Printf("Usage capped; parking for %v", uc.Until(time.Now()))
+//	}
+type UsageCapError struct {
+	Title  string // e.g., "UsageCapExceeded"
+	Detail string // for human consumption
+	Period string // e.g., "Monthly"
+	Scope  string // e.g., "Product", "Account"
+
+	// The time at which the usage cap is expected to reset.  The API does not
+	// report this directly; it is estimated as the start of the next period.
+	Reset time.Time
+}
+
+// Error satisfies the error interface.
+func (e *UsageCapError) Error() string {
+	if e.Detail != "" {
+		return e.Detail
+	}
+	return fmt.Sprintf("usage cap exceeded (%s %s)", e.Period, e.Scope)
+}
+
+// Until reports the duration from now until e.Reset. It returns 0 if the reset
+// time has already passed.
+func (e *UsageCapError) Until(now time.Time) time.Duration {
+	if d := e.Reset.Sub(now); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// UsageCapReset returns the time at which a monthly usage cap in effect at
+// time t will reset, namely midnight UTC on the first day of the next month.
+func UsageCapReset(t time.Time) time.Time {
+	y, m, _ := t.UTC().Date()
+	return time.Date(y, m+1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// checkError examines an error reported by the API at time now and, if it
+// describes a problem, attaches an *Error describing the problem to it.
+func checkError(err error, now time.Time) error {
+	var e *jape.Error
+	if !errors.As(err, &e) || e.Err != nil || len(e.Data) == 0 {
+		return err
+	}
+	var p struct {
+		Type   Problem              `json:"type"`
+		Title  string               `json:"title"`
+		Detail string               `json:"detail"`
+		Status int                  `json:"status"`
+		Errors []*types.ErrorDetail `json:"errors"`
+		Period string               `json:"period"`
+		Scope  string               `json:"scope"`
+	}
+	if json.Unmarshal(e.Data, &p) != nil || (p.Type == "" && p.Title == "") {
+		return err
+	}
+	perr := &Error{
+		Type:   p.Type,
+		Title:  p.Title,
+		Detail: p.Detail,
+		Status: e.Status,
+		Errors: p.Errors,
+	}
+	if perr.Status == 0 {
+		perr.Status = p.Status
+	}
+	if p.Type == ProblemUsageCapped || p.Title == "UsageCapExceeded" {
+		perr.Type = ProblemUsageCapped
+		perr.Err = &UsageCapError{
+			Title:  p.Title,
+			Detail: p.Detail,
+			Period: p.Period,
+			Scope:  p.Scope,
+			Reset:  UsageCapReset(now),
+		}
+	}
+	e.Err = perr
+	return err
+}
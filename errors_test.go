@@ -0,0 +1,126 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package twitter_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/jape"
+)
+
+func TestUsageCapError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/capped":
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"title":"UsageCapExceeded","detail":"Usage cap exceeded: Monthly product cap",` +
+				`"period":"Monthly","scope":"Product","type":"https://api.twitter.com/2/problems/usage-capped"}`))
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"title":"Not Found Error","detail":"Could not find list with id: [1].",` +
+				`"type":"https://api.twitter.com/2/problems/resource-not-found"}`))
+		case "/invalid":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errors":[{"parameters":{"ids":["x"]},"message":"The id query parameter value [x] is not valid"}],` +
+				`"title":"Invalid Request","detail":"One or more parameters to your request was invalid.",` +
+				`"type":"https://api.twitter.com/2/problems/invalid-request"}`))
+		case "/limited":
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"title":"Too Many Requests","detail":"Too Many Requests","status":429}`))
+		}
+	}))
+	defer srv.Close()
+
+	clock := newFakeClock()
+	cli := twitter.NewClient(&jape.Client{BaseURL: srv.URL, Clock: clock})
+	ctx := context.Background()
+
+	t.Run("Capped", func(t *testing.T) {
+		_, err := cli.Call(ctx, &jape.Request{Method: "capped"})
+		var jerr *jape.Error
+		if !errors.As(err, &jerr) {
+			t.Fatalf("Call: got %v, want *jape.Error", err)
+		}
+		var uc *twitter.UsageCapError
+		if !errors.As(err, &uc) {
+			t.Fatalf("Call: got %v, want *UsageCapError", err)
+		}
+		if !errors.Is(err, twitter.ProblemUsageCapped) {
+			t.Errorf("Call: got %v, want %v", err, twitter.ProblemUsageCapped)
+		}
+		if uc.Period != "Monthly" || uc.Scope != "Product" {
+			t.Errorf("UsageCapError: got period %q scope %q", uc.Period, uc.Scope)
+		}
+		if want := time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC); !uc.Reset.Equal(want) {
+			t.Errorf("Reset: got %v, want %v", uc.Reset, want)
+		}
+		if got, want := uc.Until(clock.Now()), 17*24*time.Hour+12*time.Hour; got != want {
+			t.Errorf("Until: got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Problem", func(t *testing.T) {
+		_, err := cli.Call(ctx, &jape.Request{Method: "missing"})
+		if !errors.Is(err, twitter.ProblemResourceNotFound) {
+			t.Errorf("Call: got %v, want %v", err, twitter.ProblemResourceNotFound)
+		}
+		if errors.Is(err, twitter.ProblemUsageCapped) {
+			t.Errorf("Call: got %v, unexpectedly matched %v", err, twitter.ProblemUsageCapped)
+		}
+		var perr *twitter.Error
+		if !errors.As(err, &perr) {
+			t.Fatalf("Call: got %v, want *twitter.Error", err)
+		}
+		if perr.Title != "Not Found Error" || perr.Detail == "" {
+			t.Errorf("Error: got title %q detail %q", perr.Title, perr.Detail)
+		}
+	})
+
+	t.Run("Envelope", func(t *testing.T) {
+		_, err := cli.Call(ctx, &jape.Request{Method: "invalid"})
+		var perr *twitter.Error
+		if !errors.As(err, &perr) {
+			t.Fatalf("Call: got %v, want *twitter.Error", err)
+		}
+		if perr.Type != twitter.ProblemInvalidRequest || perr.Status != http.StatusBadRequest {
+			t.Errorf("Error: got type %q status %d", perr.Type, perr.Status)
+		}
+		if len(perr.Errors) != 1 {
+			t.Fatalf("Error: got %d details, want 1", len(perr.Errors))
+		}
+		if d := perr.Errors[0]; d.Message == "" || len(d.Parameters["ids"]) != 1 {
+			t.Errorf("Detail: got %+v, want message and ids parameter", d)
+		}
+	})
+
+	t.Run("RateLimited", func(t *testing.T) {
+		_, err := cli.Call(ctx, &jape.Request{Method: "limited"})
+		var uc *twitter.UsageCapError
+		if err == nil || errors.As(err, &uc) {
+			t.Fatalf("Call: got %v, want a non-cap error", err)
+		}
+	})
+}
+
+func TestUsageCapReset(t *testing.T) {
+	tests := []struct {
+		input time.Time
+		want  time.Time
+	}{
+		{time.Date(2022, 3, 14, 12, 0, 0, 0, time.UTC), time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2022, 12, 31, 23, 59, 0, 0, time.UTC), time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range tests {
+		if got := twitter.UsageCapReset(tc.input); !got.Equal(tc.want) {
+			t.Errorf("UsageCapReset(%v): got %v, want %v", tc.input, got, tc.want)
+		}
+	}
+}
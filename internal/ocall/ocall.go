@@ -35,6 +35,15 @@ func HasMorePages(req *jape.Request) bool {
 // Subsequently invoking the query will then fetch the first page of results.
 func ResetPageToken(req *jape.Request) { req.Params.Reset(nextTokenParam) }
 
+// PageToken returns the request's current page token, or "" if the request
+// is fresh or has no more pages.
+func PageToken(req *jape.Request) string {
+	if v := req.Params.Get(nextTokenParam); v != "0" {
+		return v
+	}
+	return ""
+}
+
 // GetUsers invokes an API method that returns API v1.1 user objects and
 // pagination metadata.
 func GetUsers(ctx context.Context, req *jape.Request, opts types.UserFields, cli *twitter.Client) (*UsersReply, error) {
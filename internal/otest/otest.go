@@ -5,6 +5,8 @@ package otest
 
 import (
 	"flag"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
@@ -38,3 +40,12 @@ func NewClient(t *testing.T, opts *jape.Client) *twitter.Client {
 	}
 	return cli
 }
+
+// ServerClient starts an HTTP test server running h, and returns a client
+// whose base URL targets that server. The server is closed when t ends.
+func ServerClient(t *testing.T, h http.Handler) *twitter.Client {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return NewClient(t, &jape.Client{BaseURL: srv.URL})
+}
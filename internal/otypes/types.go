@@ -285,3 +285,38 @@ func (o User) ToUserV2(opt types.UserFields) *types.User {
 	}
 	return u
 }
+
+// Place captures a subset of the fields of the v1.1 API Place object, as
+// needed to populate the fields of a v2 Place.
+//
+// See https://developer.twitter.com/en/docs/twitter-api/v1/data-dictionary/object-model/geo
+type Place struct {
+	ID              string          `json:"id"`
+	Name            string          `json:"name"`
+	FullName        string          `json:"full_name"`
+	PlaceType       string          `json:"place_type"`
+	Country         string          `json:"country"`
+	CountryCode     string          `json:"country_code"`
+	ContainedWithin []*Place        `json:"contained_within"`
+	BoundingBox     json.RawMessage `json:"bounding_box"` // GeoJSON geometry
+}
+
+// ToPlaceV2 converts o to an approximately-equivalent API v2 Place value.
+// The v2 geo field is populated from the bounding box.
+func (o Place) ToPlaceV2() *types.Place {
+	p := &types.Place{
+		ID:          o.ID,
+		FullName:    o.FullName,
+		Name:        o.Name,
+		Type:        o.PlaceType,
+		CountryName: o.Country,
+		CountryCode: o.CountryCode,
+	}
+	for _, c := range o.ContainedWithin {
+		p.ContainedIn = append(p.ContainedIn, c.ID)
+	}
+	if len(o.BoundingBox) != 0 && string(o.BoundingBox) != "null" {
+		p.Location = o.BoundingBox
+	}
+	return p
+}
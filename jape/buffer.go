@@ -0,0 +1,128 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package jape
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// A StreamBuffer configures a client to buffer stream messages in a bounded
+// queue between the network reader and the callback. This allows a stream to
+// absorb bursts of messages, or an occasional slow callback, without falling
+// behind the server; the Twitter API disconnects clients that do not keep up.
+//
+// A StreamBuffer may be shared by multiple clients or streams, in which case
+// its Dropped count is the total for all of them.
+type StreamBuffer struct {
+	// The maximum number of messages held in the buffer. If Size ≤ 0, no
+	// buffering is done.
+	Size int
+
+	// What to do when a message arrives and the buffer is full.
+	Overflow OverflowPolicy
+
+	dropped atomic.Int64
+}
+
+// Dropped reports the number of messages discarded because the buffer was
+// full when they arrived.
+func (b *StreamBuffer) Dropped() int64 { return b.dropped.Load() }
+
+// An OverflowPolicy determines how a StreamBuffer handles a message that
+// arrives when the buffer is full.
+type OverflowPolicy int
+
+// Constants for OverflowPolicy.
+const (
+	// Stop reading from the server until the callback makes room.
+	OverflowBlock OverflowPolicy = iota
+
+	// Discard the oldest message in the buffer to make room.
+	OverflowDropOldest
+
+	// Discard the message that arrived.
+	OverflowDropNewest
+)
+
+var errQueueStopped = errors.New("stream queue stopped")
+
+// A streamQueue is a bounded queue of stream messages, filled by a reader and
+// drained by a consumer.
+type streamQueue struct {
+	cfg *StreamBuffer
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	buf     [][]byte
+	done    bool  // the reader is finished
+	err     error // the error that ended the reader, if any
+	stopped bool  // the consumer is finished
+}
+
+func newStreamQueue(cfg *StreamBuffer) *streamQueue {
+	q := &streamQueue{cfg: cfg}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// push adds msg to the queue, subject to the overflow policy. It reports an
+// error if the consumer has stopped.
+func (q *streamQueue) push(msg []byte) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.cfg.Overflow == OverflowBlock {
+		for len(q.buf) >= q.cfg.Size && !q.stopped {
+			q.cond.Wait()
+		}
+	}
+	if q.stopped {
+		return errQueueStopped
+	} else if len(q.buf) >= q.cfg.Size {
+		q.cfg.dropped.Add(1)
+		if q.cfg.Overflow == OverflowDropNewest {
+			return nil
+		}
+		q.buf[0] = nil
+		q.buf = q.buf[1:]
+	}
+	q.buf = append(q.buf, msg)
+	q.cond.Broadcast()
+	return nil
+}
+
+// pop removes and returns the oldest message in the queue, blocking until
+// one is available. Once the queue is empty and the reader has finished, pop
+// returns nil and the error that ended the reader.
+func (q *streamQueue) pop() ([]byte, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.buf) == 0 && !q.done {
+		q.cond.Wait()
+	}
+	if len(q.buf) == 0 {
+		return nil, q.err
+	}
+	msg := q.buf[0]
+	q.buf[0] = nil
+	q.buf = q.buf[1:]
+	q.cond.Broadcast()
+	return msg, nil
+}
+
+// close marks the reader as finished with the given error.
+func (q *streamQueue) close(err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.done, q.err = true, err
+	q.cond.Broadcast()
+}
+
+// stop marks the consumer as finished, unblocking the reader.
+func (q *streamQueue) stop() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.stopped = true
+	q.cond.Broadcast()
+}
@@ -0,0 +1,78 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package jape_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/928799934/twitter/jape"
+)
+
+func TestStreamBuffer(t *testing.T) {
+	const numMessages = 10
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 1; i <= numMessages; i++ {
+			w.Write([]byte(strconv.Itoa(i) + "\r\n"))
+		}
+	}))
+	defer srv.Close()
+
+	// Depending on whether the first message is consumed before the buffer
+	// fills, the callback sees 2 or 3 messages when dropping, and the reader
+	// drops 7 or 8.
+	tests := []struct {
+		policy     jape.OverflowPolicy
+		want       string // for dropping policies, a prefix or suffix
+		minDropped int64
+	}{
+		{jape.OverflowBlock, "1 2 3 4 5 6 7 8 9 10", 0},
+		{jape.OverflowDropNewest, "1 2", 7},
+		{jape.OverflowDropOldest, "9 10", 7},
+	}
+	for _, test := range tests {
+		buf := &jape.StreamBuffer{Size: 2, Overflow: test.policy}
+		cli := &jape.Client{BaseURL: srv.URL, StreamBuffer: buf}
+
+		var got []string
+		err := cli.Stream(context.Background(), &jape.Request{Method: "stream"}, func(msg []byte) error {
+			if len(got) == 0 && test.minDropped > 0 {
+				// Stall on the first message until the reader has overflowed.
+				deadline := time.Now().Add(5 * time.Second)
+				for buf.Dropped() < test.minDropped && time.Now().Before(deadline) {
+					time.Sleep(5 * time.Millisecond)
+				}
+			}
+			got = append(got, string(msg))
+			return nil
+		})
+		if err != nil {
+			t.Errorf("Stream (policy %d): unexpected error: %v", test.policy, err)
+		}
+		s := strings.Join(got, " ")
+		switch test.policy {
+		case jape.OverflowBlock:
+			if s != test.want {
+				t.Errorf("Stream (block): got %q, want %q", s, test.want)
+			}
+		case jape.OverflowDropNewest:
+			if !strings.HasPrefix(s, test.want) {
+				t.Errorf("Stream (drop newest): got %q, want prefix %q", s, test.want)
+			}
+		case jape.OverflowDropOldest:
+			if !strings.HasSuffix(s, test.want) {
+				t.Errorf("Stream (drop oldest): got %q, want suffix %q", s, test.want)
+			}
+		}
+		n := buf.Dropped()
+		if n < test.minDropped || int(n)+len(got) != numMessages {
+			t.Errorf("Stream (policy %d): dropped %d and delivered %d, want %d total",
+				test.policy, n, len(got), numMessages)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package jape
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// A Cache configures a client to cache the responses to GET requests, so that
+// repeated requests for the same stable objects can be served without using
+// quota.
+//
+// If a cached response has a validator (an ETag or Last-Modified header), a
+// subsequent identical request is sent to the server as a conditional
+// request; if the server reports the object is unchanged (304 Not Modified)
+// the cached response is returned. If a cached response has no validator, it
+// is reused without contacting the server until TTL has elapsed.
+//
+// Cache keys are the complete request URL, so a Store should not be shared
+// among clients that use different credentials. A request with NoCache set
+// bypasses the cache.
+type Cache struct {
+	// The storage for cached responses. This field must be set.
+	Store Store
+
+	// If positive, responses without validators are cached, and reused for
+	// this duration after they are stored. If zero, only responses having a
+	// validator are cached.
+	TTL time.Duration
+}
+
+// A Store is a storage backend for cached responses. A Store must be safe for
+// concurrent use by multiple goroutines.
+type Store interface {
+	// Get returns the response stored for key, if any.
+	Get(key string) (*CachedResponse, bool)
+
+	// Put stores rsp as the response for key, replacing any previous value.
+	Put(key string, rsp *CachedResponse)
+}
+
+// A CachedResponse is a response stored in a cache. The contents of a cached
+// response must not be modified once it has been stored.
+type CachedResponse struct {
+	Header http.Header // the response headers
+	Body   []byte      // the response body
+	Stored time.Time   // when the response was stored or last revalidated
+}
+
+// validators returns the conditional request headers for c, or nil if c is
+// nil or has no validators.
+func (c *CachedResponse) validators() http.Header {
+	if c == nil {
+		return nil
+	}
+	var h http.Header
+	if v := c.Header.Get("ETag"); v != "" {
+		h = http.Header{"If-None-Match": {v}}
+	}
+	if v := c.Header.Get("Last-Modified"); v != "" {
+		if h == nil {
+			h = make(http.Header)
+		}
+		h.Set("If-Modified-Since", v)
+	}
+	return h
+}
+
+// isFresh reports whether e can be reused at time now without contacting the
+// server.
+func (c *Cache) isFresh(e *CachedResponse, now time.Time) bool {
+	return c.TTL > 0 && e.validators() == nil && now.Sub(e.Stored) < c.TTL
+}
+
+// put stores a successful response in the cache, if it is cacheable.
+func (c *Cache) put(key string, header http.Header, body []byte, now time.Time) {
+	if header.Get("ETag") == "" && header.Get("Last-Modified") == "" && c.TTL <= 0 {
+		return // no validator, and TTL caching is not enabled
+	}
+	c.Store.Put(key, &CachedResponse{
+		Header: header.Clone(),
+		Body:   append([]byte(nil), body...),
+		Stored: now,
+	})
+}
+
+// A MemoryStore is an in-memory Store. A zero MemoryStore is ready for use.
+// It retains all responses stored in it until they are replaced or removed.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*CachedResponse
+}
+
+// Get implements part of the Store interface.
+func (m *MemoryStore) Get(key string) (*CachedResponse, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rsp, ok := m.entries[key]
+	return rsp, ok
+}
+
+// Put implements part of the Store interface.
+func (m *MemoryStore) Put(key string, rsp *CachedResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.entries == nil {
+		m.entries = make(map[string]*CachedResponse)
+	}
+	m.entries[key] = rsp
+}
+
+// Remove removes the response stored for key, if any.
+func (m *MemoryStore) Remove(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+}
+
+// Len reports the number of responses stored in m.
+func (m *MemoryStore) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.entries)
+}
@@ -0,0 +1,84 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package jape_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/928799934/twitter/jape"
+)
+
+func TestCache(t *testing.T) {
+	var full, notModified, plain int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/etag":
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				notModified++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			full++
+			w.Header().Set("ETag", `"v1"`)
+			w.Write([]byte(`{"data":{"id":"1"}}`))
+		case "/plain":
+			plain++
+			w.Write([]byte(`{"data":{"id":"2"}}`))
+		}
+	}))
+	defer srv.Close()
+
+	store := new(jape.MemoryStore)
+	cli := &jape.Client{
+		BaseURL: srv.URL,
+		Cache:   &jape.Cache{Store: store, TTL: 50 * time.Millisecond},
+	}
+	ctx := context.Background()
+
+	call := func(method, want string) {
+		t.Helper()
+		_, body, err := cli.Call(ctx, &jape.Request{Method: method})
+		if err != nil {
+			t.Fatalf("Call %q failed: %v", method, err)
+		}
+		if got := string(body); got != want {
+			t.Errorf("Call %q: got %q, want %q", method, got, want)
+		}
+	}
+
+	// A response with a validator is revalidated on each request.
+	for i := 0; i < 3; i++ {
+		call("etag", `{"data":{"id":"1"}}`)
+	}
+	if full != 1 || notModified != 2 {
+		t.Errorf("ETag: got %d full and %d not-modified responses, want 1, 2", full, notModified)
+	}
+
+	// A response without a validator is reused until the TTL expires.
+	call("plain", `{"data":{"id":"2"}}`)
+	call("plain", `{"data":{"id":"2"}}`)
+	if plain != 1 {
+		t.Errorf("TTL: got %d server calls, want 1", plain)
+	}
+	time.Sleep(60 * time.Millisecond)
+	call("plain", `{"data":{"id":"2"}}`)
+	if plain != 2 {
+		t.Errorf("TTL expired: got %d server calls, want 2", plain)
+	}
+
+	// A request with NoCache set bypasses a fresh cached response.
+	if _, _, err := cli.Call(ctx, &jape.Request{Method: "plain", NoCache: true}); err != nil {
+		t.Fatalf("Call with NoCache failed: %v", err)
+	}
+	if plain != 3 {
+		t.Errorf("NoCache: got %d server calls, want 3", plain)
+	}
+
+	if n := store.Len(); n != 2 {
+		t.Errorf("Store has %d entries, want 2", n)
+	}
+}
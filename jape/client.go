@@ -25,21 +25,30 @@
 package jape
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 )
 
 const (
 	// DefaultContentType is the default content-type reported for a request body.
 	DefaultContentType = "application/json"
+
+	// DryRunHeader is the name of a header set in synthetic responses
+	// generated by a client in dry-run mode.
+	DryRunHeader = "X-Jape-Dry-Run"
 )
 
 // A Client serves as a client for an JSON-based HTTP API.
@@ -56,12 +65,69 @@ type Client struct {
 	// Defines the base URL for requests to the API.
 	BaseURL string
 
+	// If non-empty, send this value as the User-Agent header of each request.
+	UserAgent string
+
 	// If set, this function is called to log interesting events during the
 	// transaction.
 	Log LogFunc
 
 	// If non-zero, only log tags in this mask are sent to the log function.
 	LogMask LogTag
+
+	// If set, this is used to record measurements of requests and streams.
+	Metrics Metrics
+
+	// If positive, each call made by Call is limited to this duration,
+	// including the time to read the response body. This does not apply to
+	// streaming requests (see StreamReadTimeout).
+	//
+	// Note that a Timeout set on the HTTPClient applies to all requests,
+	// including streams, so a client that issues streaming requests should
+	// typically leave that unset and use the timeouts here instead.
+	CallTimeout time.Duration
+
+	// If positive, a streaming request made by Stream fails with
+	// ErrStreamStalled if no data are received from the server for this
+	// duration. The deadline is renewed each time data are received, including
+	// keep-alive messages, so a stream may stay open indefinitely as long as
+	// the server continues to deliver data. Only time spent waiting for the
+	// server counts toward the timeout, not time spent in the callback.
+	//
+	// The Twitter API sends a keep-alive at least every 20 seconds, so a
+	// value somewhat larger than that detects a dead connection promptly.
+	StreamReadTimeout time.Duration
+
+	// If true, a message in a stream that is not valid JSON is logged with
+	// the LogStreamMalformed tag and skipped, instead of ending the stream.
+	StreamSkipMalformed bool
+
+	// If set, messages received by Stream are buffered in a bounded queue
+	// between the network reader and the callback (see StreamBuffer).
+	StreamBuffer *StreamBuffer
+
+	// If set, selected requests issued by Call are mirrored to a shadow
+	// client, and the results are compared (see Shadow).
+	Shadow *Shadow
+
+	// If set, identical concurrent GET requests issued by Call are coalesced
+	// into a single request to the server (see Coalescer).
+	Coalesce *Coalescer
+
+	// If set, responses to GET requests issued by Call are cached, and reused
+	// or revalidated for subsequent identical requests (see Cache).
+	Cache *Cache
+
+	// If true, requests are not sent to the server. Instead, the complete
+	// request is logged with the LogDryRun tag (with the contents of the
+	// Authorization header redacted), and the client behaves as if the server
+	// returned a successful response with an empty JSON object as its body.
+	// The headers of a dry-run response include DryRunHeader.
+	DryRun bool
+
+	// If set, this is used to obtain the current time and timers.
+	// If nil, SystemClock is used.
+	Clock Clock
 }
 
 func (c *Client) httpClient() *http.Client {
@@ -83,8 +149,8 @@ func (c *Client) wantLog(tag LogTag) bool {
 
 // start issues the specified API request and returns its HTTP response.  The
 // caller is responsible for interpreting any errors or unexpected status codes
-// from the request.
-func (c *Client) start(ctx context.Context, req *Request) (*http.Response, error) {
+// from the request.  Any headers in extra are added to the request.
+func (c *Client) start(ctx context.Context, req *Request, extra http.Header) (*http.Response, error) {
 	requestURL, err := req.URL(c.BaseURL)
 	if err != nil {
 		return nil, &Error{Message: "invalid request URL", Err: err}
@@ -100,6 +166,12 @@ func (c *Client) start(ctx context.Context, req *Request) (*http.Response, error
 	if dlen > 0 {
 		hreq.Header.Set("Content-Type", dtype)
 	}
+	if c.UserAgent != "" {
+		hreq.Header.Set("User-Agent", c.UserAgent)
+	}
+	for name, vals := range extra {
+		hreq.Header[name] = vals
+	}
 
 	if auth := c.Authorize; auth != nil {
 		if err := auth(hreq); err != nil {
@@ -110,6 +182,9 @@ func (c *Client) start(ctx context.Context, req *Request) (*http.Response, error
 		}
 	}
 
+	if c.DryRun {
+		return c.dryRun(hreq, req.Data), nil
+	}
 	rsp, err := c.httpClient().Do(hreq)
 	if err != nil {
 		return nil, &Error{Message: "issuing request", Err: err}
@@ -117,10 +192,57 @@ func (c *Client) start(ctx context.Context, req *Request) (*http.Response, error
 	return rsp, nil
 }
 
+// dryRun logs the contents of hreq, with the given body, and returns a
+// synthetic successful response with an empty JSON object as its body.
+func (c *Client) dryRun(hreq *http.Request, body []byte) *http.Response {
+	if c.wantLog(LogDryRun) {
+		var buf strings.Builder
+		fmt.Fprintf(&buf, "%s %s\n", hreq.Method, hreq.URL)
+		names := make([]string, 0, len(hreq.Header))
+		for name := range hreq.Header {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			val := strings.Join(hreq.Header[name], ", ")
+			if name == "Authorization" {
+				val = "[redacted]"
+			}
+			fmt.Fprintf(&buf, "%s: %s\n", name, val)
+		}
+		if len(body) != 0 {
+			fmt.Fprintf(&buf, "\n%s\n", body)
+		}
+		c.log(LogDryRun, buf.String())
+	}
+	return &http.Response{
+		Status:        "200 OK (dry run)",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}, DryRunHeader: {"true"}},
+		Body:          io.NopCloser(strings.NewReader("{}")),
+		ContentLength: 2,
+		Request:       hreq,
+	}
+}
+
 // ErrStopStreaming is a sentinel error that a stream callback can use to
 // signal it does not want any further results.
 var ErrStopStreaming = errors.New("stop streaming")
 
+// ErrStreamStalled is reported by Stream when no data, including keep-alive
+// messages, are received from the server within the StreamReadTimeout. This
+// usually means the connection has silently failed, and the caller should
+// reconnect. For compatibility, ErrStreamStalled also matches
+// context.DeadlineExceeded.
+var ErrStreamStalled = fmt.Errorf("stream stalled: %w", context.DeadlineExceeded)
+
+// ErrMalformedMessage is reported by Stream when the server sends a message
+// that is not valid JSON, unless StreamSkipMalformed is set.
+var ErrMalformedMessage = errors.New("invalid JSON message")
+
 // A Callback function is invoked for each reply received in a stream.  If the
 // callback reports a non-nil error, the stream is terminated. If the error is
 // anything other than ErrStopStreaming, it is reported to the caller.
@@ -143,7 +265,7 @@ func (c *Client) receive(rsp *http.Response) (http.Header, []byte, error) {
 		c.log(LogResponseBody, body.String())
 	}
 	switch rsp.StatusCode {
-	case http.StatusOK, http.StatusCreated:
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
 		// ok
 	default:
 		return rsp.Header, nil, &Error{
@@ -158,17 +280,75 @@ func (c *Client) receive(rsp *http.Response) (http.Header, []byte, error) {
 // Call issues the specified API request and returns the HTTP response headers
 // and response body without decoding. Errors from Call have type *jape.Error.
 func (c *Client) Call(ctx context.Context, req *Request) (http.Header, []byte, error) {
-	hrsp, err := c.start(ctx, req)
+	if c.Coalesce != nil && req.isRead() {
+		if key, err := req.URL(c.BaseURL); err == nil {
+			return c.Coalesce.do(key, func() (http.Header, []byte, error) {
+				return c.call(ctx, req)
+			})
+		}
+	}
+	return c.call(ctx, req)
+}
+
+func (c *Client) call(ctx context.Context, req *Request) (http.Header, []byte, error) {
+	if c.CallTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.CallTimeout)
+		defer cancel()
+	}
+
+	// If caching is enabled, check for a usable cached response.
+	var cacheKey string
+	var cached *CachedResponse
+	if c.Cache != nil && !c.DryRun && !req.NoCache && req.isRead() {
+		if key, err := req.URL(c.BaseURL); err == nil {
+			cacheKey = key
+			if e, ok := c.Cache.Store.Get(key); ok {
+				if c.Cache.isFresh(e, c.Now()) {
+					c.log(LogHTTPStatus, "cached")
+					return e.Header.Clone(), append([]byte(nil), e.Body...), nil
+				}
+				cached = e
+			}
+		}
+	}
+
+	start := time.Now() // latency is measured on the wall clock, not c.Clock
+	hrsp, err := c.start(ctx, req, cached.validators())
 	if err != nil {
+		c.observeRequest(req, 0, start, 0)
 		return nil, nil, err
 	}
-	return c.receive(hrsp)
+	if cached != nil && hrsp.StatusCode == http.StatusNotModified {
+		io.Copy(io.Discard, hrsp.Body)
+		hrsp.Body.Close()
+		c.log(LogHTTPStatus, hrsp.Status)
+		c.observeRequest(req, hrsp.StatusCode, start, 0)
+		c.Cache.Store.Put(cacheKey, &CachedResponse{
+			Header: cached.Header,
+			Body:   cached.Body,
+			Stored: c.Now(),
+		})
+		return cached.Header.Clone(), append([]byte(nil), cached.Body...), nil
+	}
+	header, body, err := c.receive(hrsp)
+	c.observeRequest(req, hrsp.StatusCode, start, len(body))
+	if err == nil && cacheKey != "" {
+		c.Cache.put(cacheKey, header, body, c.Now())
+	}
+	if err == nil && c.Shadow.wantMirror(req) {
+		c.Shadow.mirror(req, body)
+	}
+	return header, body, err
 }
 
 // stream streams results from a successful (non-nil) HTTP response returned by
 // a call to start. Results are delivered to the given callback until the
 // stream ends, ctx ends, or the callback reports a non-nil error.  The error
 // from the callback is propagated to the caller of stream.
+//
+// The stream body is a sequence of JSON messages, one per line. Blank lines
+// are keep-alives and are discarded.
 func (c *Client) stream(ctx context.Context, rsp *http.Response, f Callback) error {
 	if rsp == nil { // safety check
 		panic("cannot stream a nil *http.Response")
@@ -197,33 +377,114 @@ func (c *Client) stream(ctx context.Context, rsp *http.Response, f Callback) err
 		body.Close()
 	}()
 
-	dec := json.NewDecoder(body)
+	// If a read timeout is enabled, end the stream if the timer expires before
+	// the server sends more data.
+	var r io.Reader = body
+	var timedOut atomic.Bool
+	if c.StreamReadTimeout > 0 {
+		t := time.AfterFunc(c.StreamReadTimeout, func() {
+			timedOut.Store(true)
+			cancel()
+		})
+		defer t.Stop()
+		r = timeoutReader{r: body, t: t, d: c.StreamReadTimeout}
+	}
+
+	if c.StreamBuffer == nil || c.StreamBuffer.Size <= 0 {
+		return c.readStream(r, &timedOut, func(msg []byte) error {
+			if err := f(msg); err != nil {
+				return &Error{Message: "callback", Err: err}
+			}
+			return nil
+		})
+	}
+
+	// Read messages into a buffer in a separate goroutine, so that a slow
+	// callback does not stall reads from the server.
+	q := newStreamQueue(c.StreamBuffer)
+	go func() { q.close(c.readStream(r, &timedOut, q.push)) }()
 	for {
-		var next json.RawMessage
-		if err := dec.Decode(&next); err == io.EOF {
-			break
-		} else if err != nil {
-			return &Error{Message: "decoding message from stream", Err: err}
+		msg, err := q.pop()
+		if msg == nil {
+			return err
+		} else if err := f(msg); err != nil {
+			q.stop()
+			return &Error{Message: "callback", Err: err}
+		}
+	}
+}
+
+// readStream reads messages from r and delivers each to emit, until r is
+// exhausted or emit reports an error. The error from emit is returned without
+// wrapping.
+func (c *Client) readStream(r io.Reader, timedOut *atomic.Bool, emit func([]byte) error) error {
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if timedOut.Load() {
+			return &Error{Message: "stream read timed out", Err: ErrStreamStalled}
+		} else if err != nil && err != io.EOF {
+			return &Error{Message: "reading message from stream", Err: err}
+		}
+		eof := err == io.EOF
+
+		// The server sends blank lines as keep-alives; skip them.
+		next := bytes.TrimSpace(line)
+		if len(next) == 0 {
+			if eof {
+				break
+			}
+			continue
 		}
 		if c.wantLog(LogStreamBody) {
 			c.log(LogStreamBody, string(next))
 		}
-		if err := f(next); err != nil {
-			return &Error{Message: "callback", Err: err}
+		if !json.Valid(next) {
+			if !c.StreamSkipMalformed {
+				return &Error{Message: "decoding message from stream", Err: ErrMalformedMessage}
+			}
+			c.log(LogStreamMalformed, string(next))
+		} else if err := emit(next); err != nil {
+			return err
+		}
+		if eof {
+			break
 		}
 	}
 	return nil
 }
 
+// timeoutReader wraps an io.Reader to run a timer only while a read is
+// pending, so that the timer measures how long the server is idle and not how
+// long the caller takes between reads.
+type timeoutReader struct {
+	r io.Reader
+	t *time.Timer
+	d time.Duration
+}
+
+func (t timeoutReader) Read(data []byte) (int, error) {
+	t.t.Reset(t.d)
+	defer t.t.Stop()
+	return t.r.Read(data)
+}
+
 // Stream issues the specified API request and streams results to the given
 // callback. Errors from Stream have concrete type *jape.Error.
 func (c *Client) Stream(ctx context.Context, req *Request, f Callback) error {
-	hrsp, err := c.start(ctx, req)
+	start := time.Now() // latency is measured on the wall clock, not c.Clock
+	hrsp, err := c.start(ctx, req, nil)
 	if err != nil {
+		c.observeRequest(req, 0, start, 0)
 		return err
 	}
-	if err := c.stream(ctx, hrsp, f); errors.Is(err, ErrStopStreaming) {
-		return nil // the callback requested a stop
+	c.observeRequest(req, hrsp.StatusCode, start, 0)
+	if hrsp.StatusCode == http.StatusOK {
+		c.observeStream(req, true)
+		defer c.observeStream(req, false)
+	}
+	if err := c.stream(ctx, hrsp, f); err == nil || errors.Is(err, ErrStopStreaming) {
+		return nil // the stream ended, or the callback requested a stop
 	} else if !errors.Is(err, io.EOF) {
 		if _, ok := err.(*Error); ok {
 			return err
@@ -252,8 +513,16 @@ func BearerTokenAuthorizer(token string) Authorizer {
 type Request struct {
 	// The fully-expanded method path for the API to call, including parameters.
 	// For example: "service/method/12345".
+	//
+	// If PathParams is set, Method may instead be a template in which each
+	// path component of the form ":name" is replaced by the named path
+	// parameter. For example: "service/method/:id".
 	Method string
 
+	// Values for the path parameters of a Method template. Each value is
+	// escaped in the request URL, so it cannot alter the rest of the path.
+	PathParams map[string]string
+
 	// Additional request parameters, including optional fields and expansions.
 	Params Params
 
@@ -267,6 +536,17 @@ type Request struct {
 	// If unset, the value defaults to DefaultContentType (JSON).
 	// A content-type is only set if Data is non-empty.
 	ContentType string
+
+	// If true, the client's response cache (see Cache) is not used for this
+	// request: its response is neither served from nor stored in the cache.
+	// Set this for requests that poll for changes, such as status checks.
+	NoCache bool
+}
+
+// isRead reports whether r is a read-only request, having the GET method and
+// no request body.
+func (r *Request) isRead() bool {
+	return (r.HTTPMethod == "" || r.HTTPMethod == http.MethodGet) && len(r.Data) == 0
 }
 
 // SetBodyToParams encodes r.Params in the request body.  This replaces the
@@ -283,11 +563,44 @@ func (r *Request) URL(base string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	u.Path = path.Join(u.Path, r.Method)
+	if len(r.PathParams) == 0 {
+		u.Path = path.Join(u.Path, r.Method)
+	} else {
+		// Join the escaped path, so that the separators in parameter values
+		// are not cleaned away, and derive the plain path from it.
+		escaped, err := r.expandPath()
+		if err != nil {
+			return "", err
+		}
+		u.RawPath = path.Join(u.EscapedPath(), escaped)
+		if u.Path, err = url.PathUnescape(u.RawPath); err != nil {
+			return "", err
+		}
+	}
 	r.addQueryTerms(u)
 	return u.String(), nil
 }
 
+// expandPath returns the method path of r with its path parameters replaced
+// by their values, escaped for use in a URL.
+func (r *Request) expandPath() (string, error) {
+	segs := strings.Split(r.Method, "/")
+	for i, seg := range segs {
+		if !strings.HasPrefix(seg, ":") {
+			continue
+		}
+		name := seg[1:]
+		v, ok := r.PathParams[name]
+		if !ok {
+			return "", fmt.Errorf("missing path parameter %q", name)
+		} else if v == "" || v == "." || v == ".." {
+			return "", fmt.Errorf("invalid value %q for path parameter %q", v, name)
+		}
+		segs[i] = url.PathEscape(v)
+	}
+	return strings.Join(segs, "/"), nil
+}
+
 // Body returns the size and putative content-type of the request body, along
 // with a reader that will deliver its contents.
 //
@@ -325,6 +638,15 @@ func (p Params) Set(name, value string) { p[name] = []string{value} }
 // Reset removes any existing values for the specified parameter.
 func (p Params) Reset(name string) { delete(p, name) }
 
+// Get returns the first value of the specified parameter, or "" if it has no
+// values.
+func (p Params) Get(name string) string {
+	if vs := p[name]; len(vs) != 0 {
+		return vs[0]
+	}
+	return ""
+}
+
 // Encode encodes p as a query string. If len(p) == 0, Encode returns "".
 func (p Params) Encode() string {
 	query := make(url.Values)
@@ -364,14 +686,20 @@ const (
 	LogResponseBody
 	// The body of a stream response from the server
 	LogStreamBody
+	// A description of a request not sent because of dry-run mode
+	LogDryRun
+	// A message skipped from a stream because it is not valid JSON
+	LogStreamMalformed
 )
 
 var tagNames = map[LogTag]string{
-	LogRequestURL:    "RequestURL",
-	LogAuthorization: "Authorization",
-	LogHTTPStatus:    "HTTPStatus",
-	LogResponseBody:  "ResponseBody",
-	LogStreamBody:    "StreamBody",
+	LogRequestURL:      "RequestURL",
+	LogAuthorization:   "Authorization",
+	LogHTTPStatus:      "HTTPStatus",
+	LogResponseBody:    "ResponseBody",
+	LogStreamBody:      "StreamBody",
+	LogDryRun:          "DryRun",
+	LogStreamMalformed: "StreamMalformed",
 }
 
 func (t LogTag) String() string {
@@ -0,0 +1,237 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package jape_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/928799934/twitter/jape"
+)
+
+func TestTimeouts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			w.Write([]byte(`{}`))
+
+		case "/stream":
+			// Send messages with gaps shorter than the read timeout, followed
+			// by keep-alives, then stall.
+			w.WriteHeader(http.StatusOK)
+			for i := 0; i < 3; i++ {
+				w.Write([]byte(`{"ok":true}` + "\r\n"))
+				w.(http.Flusher).Flush()
+				time.Sleep(50 * time.Millisecond)
+			}
+			for i := 0; i < 3; i++ {
+				w.Write([]byte("\r\n"))
+				w.(http.Flusher).Flush()
+				time.Sleep(50 * time.Millisecond)
+			}
+			<-r.Context().Done()
+
+		case "/busy":
+			// Send messages promptly and end the stream; the client is slow.
+			w.WriteHeader(http.StatusOK)
+			for i := 0; i < 3; i++ {
+				w.Write([]byte(`{"ok":true}` + "\r\n"))
+				w.(http.Flusher).Flush()
+			}
+		}
+	}))
+	defer srv.Close()
+
+	cli := &jape.Client{
+		BaseURL:           srv.URL,
+		CallTimeout:       100 * time.Millisecond,
+		StreamReadTimeout: 200 * time.Millisecond,
+	}
+	ctx := context.Background()
+
+	t.Run("Call", func(t *testing.T) {
+		_, _, err := cli.Call(ctx, &jape.Request{Method: "slow"})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Call: got %v, want %v", err, context.DeadlineExceeded)
+		}
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		var n int
+		start := time.Now()
+		err := cli.Stream(ctx, &jape.Request{Method: "stream"}, func([]byte) error {
+			n++
+			return nil
+		})
+		if !errors.Is(err, jape.ErrStreamStalled) {
+			t.Errorf("Stream: got %v, want %v", err, jape.ErrStreamStalled)
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Stream: got %v, want %v", err, context.DeadlineExceeded)
+		}
+		if n != 3 {
+			t.Errorf("Stream: got %d messages, want 3", n)
+		}
+
+		// The stream should survive past the call timeout, and past the read
+		// timeout as long as keep-alives arrive.
+		if elapsed := time.Since(start); elapsed < 300*time.Millisecond {
+			t.Errorf("Stream ended after %v, too soon", elapsed)
+		}
+	})
+
+	t.Run("SlowCallback", func(t *testing.T) {
+		// Time spent in the callback does not count as a stalled stream.
+		var n int
+		err := cli.Stream(ctx, &jape.Request{Method: "busy"}, func([]byte) error {
+			n++
+			time.Sleep(300 * time.Millisecond)
+			return nil
+		})
+		if err != nil {
+			t.Errorf("Stream: unexpected error: %v", err)
+		}
+		if n != 3 {
+			t.Errorf("Stream: got %d messages, want 3", n)
+		}
+	})
+}
+
+func TestDryRun(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected request in dry-run mode: %s %s", r.Method, r.URL)
+	}))
+	defer srv.Close()
+
+	var logged []string
+	cli := &jape.Client{
+		BaseURL:   srv.URL,
+		Authorize: jape.BearerTokenAuthorizer("s3kr1t"),
+		DryRun:    true,
+		Log:       func(_ jape.LogTag, msg string) { logged = append(logged, msg) },
+		LogMask:   jape.LogDryRun,
+	}
+	ctx := context.Background()
+
+	hdr, body, err := cli.Call(ctx, &jape.Request{
+		Method:     "2/tweets/search/stream/rules",
+		HTTPMethod: "POST",
+		Params:     jape.Params{"dry_run": {"true"}},
+		Data:       []byte(`{"add":[{"value":"cats"}]}`),
+	})
+	if err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	if string(body) != "{}" || hdr.Get(jape.DryRunHeader) == "" {
+		t.Errorf("Call: got body %q header %v, want synthetic response", body, hdr)
+	}
+	if len(logged) != 1 {
+		t.Fatalf("Got %d log messages, want 1", len(logged))
+	}
+	msg := logged[0]
+	for _, want := range []string{"POST " + srv.URL + "/2/tweets/search/stream/rules?dry_run=true", "[redacted]", `{"add":`} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("Log message is missing %q:\n%s", want, msg)
+		}
+	}
+	if strings.Contains(msg, "s3kr1t") {
+		t.Errorf("Log message contains the secret:\n%s", msg)
+	}
+
+	// Streams end immediately in dry-run mode.
+	if err := cli.Stream(ctx, &jape.Request{Method: "2/tweets/sample/stream"}, func([]byte) error {
+		return nil
+	}); err != nil {
+		t.Errorf("Stream: unexpected error: %v", err)
+	}
+}
+
+func TestStreamFrames(t *testing.T) {
+	const body = "{\"id\":1}\r\n\r\n\r\n{\"id\":2\r\n{\"id\":3}\r\n\n{\"id\":4}"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	var logged []string
+	cli := &jape.Client{
+		BaseURL: srv.URL,
+		Log:     func(_ jape.LogTag, msg string) { logged = append(logged, msg) },
+		LogMask: jape.LogStreamMalformed,
+	}
+	ctx := context.Background()
+	stream := func() ([]string, error) {
+		var got []string
+		err := cli.Stream(ctx, &jape.Request{Method: "stream"}, func(msg []byte) error {
+			got = append(got, string(msg))
+			return nil
+		})
+		return got, err
+	}
+
+	t.Run("Strict", func(t *testing.T) {
+		got, err := stream()
+		if err == nil {
+			t.Error("Stream: got nil error for a malformed message")
+		}
+		if len(got) != 1 || got[0] != `{"id":1}` {
+			t.Errorf("Stream: got %q, want the first message only", got)
+		}
+	})
+
+	t.Run("Skip", func(t *testing.T) {
+		cli.StreamSkipMalformed = true
+		got, err := stream()
+		if err != nil {
+			t.Errorf("Stream: unexpected error: %v", err)
+		}
+		want := []string{`{"id":1}`, `{"id":3}`, `{"id":4}`}
+		if strings.Join(got, " ") != strings.Join(want, " ") {
+			t.Errorf("Stream: got %q, want %q", got, want)
+		}
+		if len(logged) != 1 || logged[0] != `{"id":2` {
+			t.Errorf("Logged: got %q, want the malformed message", logged)
+		}
+	})
+}
+
+func TestRequestURL(t *testing.T) {
+	tests := []struct {
+		req  *jape.Request
+		want string // "" for an error
+	}{
+		{&jape.Request{Method: "2/tweets/20"}, "https://api.example.com/2/tweets/20"},
+		{&jape.Request{
+			Method:     "2/users/:id/tweets",
+			PathParams: map[string]string{"id": "12"},
+			Params:     jape.Params{"max_results": {"5"}},
+		}, "https://api.example.com/2/users/12/tweets?max_results=5"},
+		{&jape.Request{
+			Method:     "2/users/by/username/:name",
+			PathParams: map[string]string{"name": "a/../b c"},
+		}, "https://api.example.com/2/users/by/username/a%2F..%2Fb%20c"},
+		{&jape.Request{Method: "2/tweets/:id", PathParams: map[string]string{"other": "1"}}, ""},
+		{&jape.Request{Method: "2/tweets/:id", PathParams: map[string]string{"id": ".."}}, ""},
+	}
+	for _, test := range tests {
+		got, err := test.req.URL("https://api.example.com")
+		if test.want == "" {
+			if err == nil {
+				t.Errorf("URL %q: got %q, want error", test.req.Method, got)
+			}
+		} else if err != nil {
+			t.Errorf("URL %q: unexpected error: %v", test.req.Method, err)
+		} else if got != test.want {
+			t.Errorf("URL %q: got %q, want %q", test.req.Method, got, test.want)
+		}
+	}
+}
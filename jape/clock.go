@@ -0,0 +1,44 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package jape
+
+import "time"
+
+// A Clock provides the current time and timers. Setting the Clock field of a
+// Client allows tests of time-dependent behavior, such as pacing and backoff,
+// to run deterministically without waiting in real time.
+//
+// The clock does not govern context deadlines or network timeouts (such as
+// CallTimeout and StreamReadTimeout), nor the latency reported to Metrics or
+// in call annotations, which always use real time.
+type Clock interface {
+	// Now reports the current time.
+	Now() time.Time
+
+	// After returns a channel that delivers the current time after at least
+	// duration d has elapsed.
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock is a Clock that uses the real time provided by the time package.
+// This is the default if a Client does not set a Clock.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Now reports the current time according to the Clock of c.
+func (c *Client) Now() time.Time { return c.clock().Now() }
+
+// After returns a channel that delivers the current time after duration d has
+// elapsed, according to the Clock of c.
+func (c *Client) After(d time.Duration) <-chan time.Time { return c.clock().After(d) }
+
+func (c *Client) clock() Clock {
+	if c.Clock != nil {
+		return c.Clock
+	}
+	return SystemClock
+}
@@ -0,0 +1,58 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package jape
+
+import (
+	"net/http"
+	"sync"
+)
+
+// A Coalescer merges identical concurrent requests into a single call.  When a
+// client with a Coalescer issues a GET request while an identical request
+// (same method path and parameters) is already in flight, the second caller
+// waits for the first call to finish and receives a copy of its result,
+// rather than issuing a request of its own. This protects rate limits when
+// many goroutines look up the same objects at once.
+//
+// The context of the first caller governs the shared request: If it ends, all
+// the callers waiting on that request receive its error.
+//
+// A zero Coalescer is ready for use. A Coalescer may be shared among multiple
+// clients, but only among clients that use the same base URL and credentials,
+// since their results are interchangeable.
+type Coalescer struct {
+	mu    sync.Mutex
+	calls map[string]*pendingCall
+}
+
+type pendingCall struct {
+	done   chan struct{}
+	header http.Header
+	body   []byte
+	err    error
+}
+
+// do calls f, unless a call with the same key is already in progress, in which
+// case do waits for that call to finish and returns its results.
+func (c *Coalescer) do(key string, f func() (http.Header, []byte, error)) (http.Header, []byte, error) {
+	c.mu.Lock()
+	if p, ok := c.calls[key]; ok {
+		c.mu.Unlock()
+		<-p.done
+		return p.header.Clone(), append([]byte(nil), p.body...), p.err
+	}
+	p := &pendingCall{done: make(chan struct{})}
+	if c.calls == nil {
+		c.calls = make(map[string]*pendingCall)
+	}
+	c.calls[key] = p
+	c.mu.Unlock()
+
+	p.header, p.body, p.err = f()
+
+	c.mu.Lock()
+	delete(c.calls, key)
+	c.mu.Unlock()
+	close(p.done)
+	return p.header, p.body, p.err
+}
@@ -0,0 +1,71 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package jape_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/928799934/twitter/jape"
+)
+
+func TestCoalesce(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.Method == http.MethodGet {
+			<-release
+		}
+		w.Write([]byte(`{"data":{"id":"` + r.URL.Query().Get("id") + `"}}`))
+	}))
+	defer srv.Close()
+
+	cli := &jape.Client{BaseURL: srv.URL, Coalesce: new(jape.Coalescer)}
+	ctx := context.Background()
+
+	const numCallers = 8
+	var wg sync.WaitGroup
+	bodies := make([]string, numCallers)
+	for i := 0; i < numCallers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, body, err := cli.Call(ctx, &jape.Request{Method: "2/tweets", Params: jape.Params{"id": {"1"}}})
+			if err != nil {
+				t.Errorf("Call %d failed: %v", i, err)
+			}
+			bodies[i] = string(body)
+		}(i)
+	}
+
+	// Wait for the first request to reach the server before releasing it, so
+	// that the remaining callers have a chance to join it.
+	for atomic.LoadInt32(&calls) == 0 {
+		// spin
+	}
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&calls); n >= numCallers {
+		t.Errorf("Server received %d calls, want fewer than %d", n, numCallers)
+	}
+	for i, body := range bodies {
+		if body != `{"data":{"id":"1"}}` {
+			t.Errorf("Caller %d: got body %q", i, body)
+		}
+	}
+
+	// Non-GET requests are never coalesced.
+	before := atomic.LoadInt32(&calls)
+	for i := 0; i < 2; i++ {
+		cli.Call(ctx, &jape.Request{Method: "2/tweets", HTTPMethod: "POST", Data: []byte(`{}`)})
+	}
+	if n := atomic.LoadInt32(&calls) - before; n != 2 {
+		t.Errorf("POST: server received %d calls, want 2", n)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package jape
+
+import (
+	"strings"
+	"time"
+)
+
+// Metrics receives measurements of client activity. A Metrics value may be
+// shared among multiple clients, so implementations must be safe for
+// concurrent use by multiple goroutines.
+//
+// The endpoint string passed to each method is the request method path with
+// the components that identify specific objects replaced by placeholders (see
+// Endpoint), so that it is suitable for use as a metric label.
+//
+// The module github.com/928799934/twitter/jape/promstats provides an
+// implementation that records measurements in Prometheus collectors.
+type Metrics interface {
+	// Request is called once for each request issued by the client, when the
+	// response headers and body (if any) have been received.  The status is
+	// the HTTP status code, or 0 if no response was received.  For streaming
+	// requests, latency is the time to receive the response headers, and size
+	// is 0.
+	Request(endpoint string, status int, latency time.Duration, size int64)
+
+	// Stream is called with connected == true when a streaming request
+	// receives a successful response, and again with connected == false when
+	// that stream ends for any reason.
+	Stream(endpoint string, connected bool)
+}
+
+// Endpoint returns a normalized version of the given request method path, in
+// which the path components that identify a specific object are replaced by a
+// placeholder, so that the result is suitable for use as a metric label:
+//
+//   - In an API v2 path, a component following the name of a collection, such
+//     as "users" or "lists", is replaced by ":id" (or ":name" for a user
+//     name), unless it is the name of a fixed route such as "search" or "me".
+//   - In any path, a component after the first consisting only of decimal
+//     digits is replaced by ":id".
+//
+// In either case an extension, such as ".json", is preserved. The first
+// component is preserved, since it is typically an API version.
+//
+// For example, "2/users/12/tweets" becomes "2/users/:id/tweets", and
+// "2/spaces/1DXxyRYNejbKM" becomes "2/spaces/:id".
+func Endpoint(method string) string {
+	parts := strings.Split(method, "/")
+	for i, p := range parts {
+		if i == 0 {
+			continue
+		}
+		base, ext := p, ""
+		if i := strings.Index(p, "."); i > 0 {
+			base, ext = p[:i], p[i:]
+		}
+		if ph, ok := endpointParam(parts[0], parts[i-1], base); ok {
+			parts[i] = ph + ext
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// endpointParam reports whether the path component base, following prev in
+// a path for the given API version, identifies an object, and if so returns
+// the placeholder for it.
+func endpointParam(version, prev, base string) (string, bool) {
+	if version == "2" && idCollections[prev] && base != "" && !fixedRoutes[base] {
+		if prev == "username" {
+			return ":name", true
+		}
+		return ":id", true
+	}
+	return ":id", isDigits(base)
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// idCollections are the components of an API v2 path that may be followed by
+// the ID or name of an object in that collection.
+var idCollections = map[string]bool{
+	"blocking": true, "bookmarks": true, "communities": true, "dm_conversations": true,
+	"folders": true, "followed_lists": true, "following": true, "jobs": true,
+	"likes": true, "lists": true, "media": true, "members": true, "muting": true,
+	"pinned_lists": true, "retweets": true, "spaces": true, "tweets": true,
+	"username": true, "users": true, "with": true, "woeid": true,
+}
+
+// fixedRoutes are the components of an API v2 path that may follow the name
+// of a collection, but do not identify an object.
+var fixedRoutes = map[string]bool{
+	"by": true, "compliance": true, "counts": true, "firehose": true,
+	"folders": true, "label": true, "me": true, "metadata": true,
+	"reposts_of_me": true, "sample": true, "sample10": true, "search": true,
+	"stream": true, "subtitles": true, "upload": true, "with": true,
+}
+
+func (c *Client) observeRequest(req *Request, status int, start time.Time, size int) {
+	if c.Metrics != nil {
+		c.Metrics.Request(Endpoint(req.Method), status, time.Since(start), int64(size))
+	}
+}
+
+func (c *Client) observeStream(req *Request, connected bool) {
+	if c.Metrics != nil {
+		c.Metrics.Stream(Endpoint(req.Method), connected)
+	}
+}
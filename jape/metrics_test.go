@@ -0,0 +1,102 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package jape_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/928799934/twitter/jape"
+)
+
+func TestEndpoint(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{"", ""},
+		{"2/tweets", "2/tweets"},
+		{"2/users/12/tweets", "2/users/:id/tweets"},
+		{"2/users/me", "2/users/me"},
+		{"2/users/by/username/jack", "2/users/by/username/:name"},
+		{"2/users/by/username/:name", "2/users/by/username/:name"},
+		{"2/tweets/:tid", "2/tweets/:id"},
+		{"2/tweets/search/stream/rules", "2/tweets/search/stream/rules"},
+		{"2/tweets/sample10/stream", "2/tweets/sample10/stream"},
+		{"2/spaces/1DXxyRYNejbKM/buyers", "2/spaces/:id/buyers"},
+		{"2/spaces/by/creator_ids", "2/spaces/by/creator_ids"},
+		{"2/users/12/bookmarks/folders/f1", "2/users/:id/bookmarks/folders/:id"},
+		{"2/dm_conversations/with/12/dm_events", "2/dm_conversations/with/:id/dm_events"},
+		{"2/compliance/jobs/j1", "2/compliance/jobs/:id"},
+		{"2/trends/by/woeid/1", "2/trends/by/woeid/:id"},
+		{"1.1/statuses/destroy/12345.json", "1.1/statuses/destroy/:id.json"},
+		{"1.1/lists/members.json", "1.1/lists/members.json"},
+		{"2/lists/1318922483496591360/members/16431281", "2/lists/:id/members/:id"},
+	}
+	for _, test := range tests {
+		if got := jape.Endpoint(test.input); got != test.want {
+			t.Errorf("Endpoint(%q): got %q, want %q", test.input, got, test.want)
+		}
+	}
+}
+
+type testMetrics struct {
+	mu       sync.Mutex
+	requests []string
+	streams  []bool
+}
+
+func (m *testMetrics) Request(endpoint string, status int, _ time.Duration, size int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = append(m.requests, endpoint+" "+http.StatusText(status))
+}
+
+func (m *testMetrics) Stream(endpoint string, connected bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.streams = append(m.streams, connected)
+}
+
+func TestMetrics(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing/5" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"ok":true}` + "\n"))
+	}))
+	defer srv.Close()
+
+	m := new(testMetrics)
+	cli := &jape.Client{BaseURL: srv.URL, Metrics: m}
+	ctx := context.Background()
+
+	if _, _, err := cli.Call(ctx, &jape.Request{Method: "found/1"}); err != nil {
+		t.Errorf("Call failed: %v", err)
+	}
+	if _, _, err := cli.Call(ctx, &jape.Request{Method: "missing/5"}); err == nil {
+		t.Error("Call for missing: got nil error, want error")
+	}
+	if err := cli.Stream(ctx, &jape.Request{Method: "stream"}, func([]byte) error {
+		return nil
+	}); err != nil {
+		t.Errorf("Stream failed: %v", err)
+	}
+
+	want := []string{"found/:id OK", "missing/:id Not Found", "stream OK"}
+	if len(m.requests) != len(want) {
+		t.Fatalf("Requests: got %q, want %q", m.requests, want)
+	}
+	for i, r := range m.requests {
+		if r != want[i] {
+			t.Errorf("Request %d: got %q, want %q", i+1, r, want[i])
+		}
+	}
+	if len(m.streams) != 2 || !m.streams[0] || m.streams[1] {
+		t.Errorf("Streams: got %v, want [true false]", m.streams)
+	}
+}
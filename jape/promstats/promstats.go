@@ -0,0 +1,108 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+// Package promstats implements the jape.Metrics interface using Prometheus
+// collectors. It is a separate module, so that programs using the client
+// without this package do not depend on Prometheus.
+//
+// Usage outline
+//
+//	m := promstats.New("mybot")
+//	prometheus.MustRegister(m)
+//
+//	cli := twitter.NewClient(&jape.Client{
+//	   Authorize: jape.BearerTokenAuthorizer(token),
+//	   Metrics:   m,
+//	})
+package promstats
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/928799934/twitter/jape"
+)
+
+// Metrics is a jape.Metrics that records measurements in Prometheus
+// collectors. It also implements prometheus.Collector, so that it can be
+// registered directly with a registry.
+type Metrics struct {
+	requests *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	size     *prometheus.HistogramVec
+	streams  *prometheus.GaugeVec
+}
+
+var _ jape.Metrics = (*Metrics)(nil)
+
+// New constructs a new Metrics whose metric names have the given namespace
+// prefix. If namespace == "", no prefix is used.
+//
+// The following metrics are defined:
+//
+//	api_requests_total          counter, by endpoint and status code
+//	api_request_latency_seconds histogram, by endpoint
+//	api_response_size_bytes     histogram, by endpoint
+//	api_streams_connected       gauge, by endpoint
+func New(namespace string) *Metrics {
+	return &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "api_requests_total",
+			Help:      "Number of API requests issued, by endpoint and HTTP status.",
+		}, []string{"endpoint", "status"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "api_request_latency_seconds",
+			Help:      "Latency of API requests, by endpoint.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"endpoint"}),
+		size: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "api_response_size_bytes",
+			Help:      "Size of API response bodies, by endpoint.",
+			Buckets:   prometheus.ExponentialBuckets(256, 4, 8), // 256B .. 4MiB
+		}, []string{"endpoint"}),
+		streams: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "api_streams_connected",
+			Help:      "Number of streams currently connected, by endpoint.",
+		}, []string{"endpoint"}),
+	}
+}
+
+// Request implements part of the jape.Metrics interface.
+func (m *Metrics) Request(endpoint string, status int, latency time.Duration, size int64) {
+	m.requests.WithLabelValues(endpoint, strconv.Itoa(status)).Inc()
+	m.latency.WithLabelValues(endpoint).Observe(latency.Seconds())
+	if size > 0 {
+		m.size.WithLabelValues(endpoint).Observe(float64(size))
+	}
+}
+
+// Stream implements part of the jape.Metrics interface.
+func (m *Metrics) Stream(endpoint string, connected bool) {
+	g := m.streams.WithLabelValues(endpoint)
+	if connected {
+		g.Inc()
+	} else {
+		g.Dec()
+	}
+}
+
+// Describe implements part of the prometheus.Collector interface.
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.requests.Describe(ch)
+	m.latency.Describe(ch)
+	m.size.Describe(ch)
+	m.streams.Describe(ch)
+}
+
+// Collect implements part of the prometheus.Collector interface.
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.requests.Collect(ch)
+	m.latency.Collect(ch)
+	m.size.Collect(ch)
+	m.streams.Collect(ch)
+}
@@ -0,0 +1,76 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package promstats_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/928799934/twitter/jape"
+	"github.com/928799934/twitter/jape/promstats"
+)
+
+func TestMetrics(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/missing") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"data":{}}`))
+	}))
+	defer srv.Close()
+
+	m := promstats.New("test")
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(m)
+
+	cli := &jape.Client{BaseURL: srv.URL, Metrics: m}
+	ctx := context.Background()
+	for _, method := range []string{"2/spaces/1DXxyRYNejbKM", "2/spaces/1YqKDqWqdPLsV", "2/spaces/1DXxyRYNejbKM/missing"} {
+		cli.Call(ctx, &jape.Request{Method: method})
+	}
+	m.Stream("2/tweets/sample/stream", true)
+
+	fams, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather failed: %v", err)
+	}
+	var got []string
+	for _, fam := range fams {
+		for _, s := range fam.GetMetric() {
+			var labels []string
+			for _, lp := range s.GetLabel() {
+				labels = append(labels, lp.GetName()+"="+lp.GetValue())
+			}
+			var v float64
+			switch {
+			case s.Counter != nil:
+				v = s.GetCounter().GetValue()
+			case s.Gauge != nil:
+				v = s.GetGauge().GetValue()
+			case s.Histogram != nil:
+				v = float64(s.GetHistogram().GetSampleCount())
+			}
+			got = append(got, fam.GetName()+"{"+strings.Join(labels, ",")+"} "+fmt.Sprint(v))
+		}
+	}
+	sort.Strings(got)
+	want := []string{
+		"test_api_request_latency_seconds{endpoint=2/spaces/:id/missing} 1",
+		"test_api_request_latency_seconds{endpoint=2/spaces/:id} 2",
+		"test_api_requests_total{endpoint=2/spaces/:id,status=200} 2",
+		"test_api_requests_total{endpoint=2/spaces/:id/missing,status=404} 1",
+		"test_api_response_size_bytes{endpoint=2/spaces/:id} 2",
+		"test_api_streams_connected{endpoint=2/tweets/sample/stream} 1",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Metrics:\ngot  %q\nwant %q", got, want)
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package jape
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// A Shadow configures a client to mirror selected read requests to a second
+// "shadow" client, and to compare the results. This is useful to validate a
+// migration to a new gateway or base URL, or to compare the results seen by
+// different credentials, without affecting the primary request path.
+//
+// Shadow requests are issued asynchronously, after the primary request has
+// succeeded. The results of the primary request are returned to the caller of
+// Call without waiting for the shadow. Only requests issued by Call are
+// mirrored; streaming requests are not.
+type Shadow struct {
+	// The client to which requests are mirrored. This field must be set.
+	// The shadow client uses its own configuration (including authorization
+	// and logging) to issue the mirrored requests.
+	Client *Client
+
+	// If set, this function selects which requests to mirror. A request is
+	// mirrored only if Match returns true. If nil, all requests are eligible.
+	// Regardless of Match, only GET requests are mirrored.
+	Match func(*Request) bool
+
+	// If positive, this is the maximum time allowed for a shadow request.
+	Timeout time.Duration
+
+	// This function is called with the result of each shadow request. It may
+	// be called concurrently from multiple goroutines. This field must be set.
+	Report func(*ShadowReport)
+}
+
+// A ShadowReport describes the result of comparing the primary and shadow
+// responses for a single request.
+type ShadowReport struct {
+	Request *Request // a copy of the mirrored request
+	Primary []byte   // the primary response body
+	Shadow  []byte   // the shadow response body, if the shadow succeeded
+
+	// If the shadow request failed, this is the error it reported.
+	Err error
+
+	// A description of each difference between the decoded primary and shadow
+	// responses, identified by JSON path, e.g., `data[0].text: "a" ≠ "b"`.
+	// This is empty if the responses are equivalent.
+	Diffs []string
+}
+
+func (s *Shadow) wantMirror(req *Request) bool {
+	if s == nil || s.Client == nil || s.Report == nil {
+		return false
+	} else if !req.isRead() {
+		return false
+	}
+	return s.Match == nil || s.Match(req)
+}
+
+// mirror issues a copy of req to the shadow client in a separate goroutine,
+// and reports the comparison of its response with primary.
+func (s *Shadow) mirror(req *Request, primary []byte) {
+	cp := *req
+	if req.Params != nil {
+		cp.Params = make(Params, len(req.Params))
+		for name, vals := range req.Params {
+			cp.Params[name] = append([]string(nil), vals...)
+		}
+	}
+	go func() {
+		ctx := context.Background()
+		if s.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+			defer cancel()
+		}
+		rpt := &ShadowReport{Request: &cp, Primary: primary}
+		_, body, err := s.Client.Call(ctx, &cp)
+		if err != nil {
+			rpt.Err = err
+		} else {
+			rpt.Shadow = body
+			rpt.Diffs, rpt.Err = diffJSON(primary, body)
+		}
+		s.Report(rpt)
+	}()
+}
+
+// diffJSON decodes a and b as JSON and reports the differences between them.
+func diffJSON(a, b []byte) ([]string, error) {
+	var va, vb interface{}
+	if err := json.Unmarshal(a, &va); err != nil {
+		return nil, fmt.Errorf("decoding primary: %w", err)
+	}
+	if err := json.Unmarshal(b, &vb); err != nil {
+		return nil, fmt.Errorf("decoding shadow: %w", err)
+	}
+	var diffs []string
+	diffValues("", va, vb, &diffs)
+	return diffs, nil
+}
+
+func diffValues(path string, a, b interface{}, diffs *[]string) {
+	switch ta := a.(type) {
+	case map[string]interface{}:
+		tb, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make(map[string]bool)
+		for key := range ta {
+			keys[key] = true
+		}
+		for key := range tb {
+			keys[key] = true
+		}
+		names := make([]string, 0, len(keys))
+		for key := range keys {
+			names = append(names, key)
+		}
+		sort.Strings(names)
+		for _, key := range names {
+			sub := key
+			if path != "" {
+				sub = path + "." + key
+			}
+			va, oka := ta[key]
+			vb, okb := tb[key]
+			if !oka {
+				*diffs = append(*diffs, sub+": missing in primary")
+			} else if !okb {
+				*diffs = append(*diffs, sub+": missing in shadow")
+			} else {
+				diffValues(sub, va, vb, diffs)
+			}
+		}
+		return
+
+	case []interface{}:
+		tb, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		if len(ta) != len(tb) {
+			*diffs = append(*diffs, fmt.Sprintf("%s: length %d ≠ %d", path, len(ta), len(tb)))
+		}
+		n := len(ta)
+		if len(tb) < n {
+			n = len(tb)
+		}
+		for i := 0; i < n; i++ {
+			diffValues(path+"["+strconv.Itoa(i)+"]", ta[i], tb[i], diffs)
+		}
+		return
+	}
+	if !reflect.DeepEqual(a, b) {
+		ja, _ := json.Marshal(a)
+		jb, _ := json.Marshal(b)
+		*diffs = append(*diffs, fmt.Sprintf("%s: %s ≠ %s", path, ja, jb))
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package jape_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/928799934/twitter/jape"
+)
+
+func TestShadow(t *testing.T) {
+	newServer := func(text string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"data":[{"id":"1","text":"` + text + `"}],"meta":{"result_count":1}}`))
+		}))
+	}
+	primary := newServer("hello")
+	defer primary.Close()
+	shadow := newServer("goodbye")
+	defer shadow.Close()
+
+	reports := make(chan *jape.ShadowReport, 1)
+	cli := &jape.Client{
+		BaseURL: primary.URL,
+		Shadow: &jape.Shadow{
+			Client: &jape.Client{BaseURL: shadow.URL},
+			Match:  func(req *jape.Request) bool { return req.Method != "skip" },
+			Report: func(r *jape.ShadowReport) { reports <- r },
+		},
+	}
+	ctx := context.Background()
+
+	req := &jape.Request{Method: "2/tweets", Params: jape.Params{"ids": {"1"}}}
+	if _, body, err := cli.Call(ctx, req); err != nil {
+		t.Fatalf("Call failed: %v", err)
+	} else if string(body) == "" {
+		t.Fatal("Call: empty body")
+	}
+	req.Params.Set("ids", "changed") // should not affect the shadow copy
+
+	select {
+	case r := <-reports:
+		if r.Err != nil {
+			t.Fatalf("Shadow failed: %v", r.Err)
+		}
+		if got := r.Request.Params["ids"]; len(got) != 1 || got[0] != "1" {
+			t.Errorf("Shadow request: got ids %q, want [1]", got)
+		}
+		want := `data[0].text: "hello" ≠ "goodbye"`
+		if len(r.Diffs) != 1 || r.Diffs[0] != want {
+			t.Errorf("Diffs: got %q, want [%q]", r.Diffs, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for shadow report")
+	}
+
+	// Requests that are not matched, and non-GET requests, are not mirrored.
+	cli.Call(ctx, &jape.Request{Method: "skip"})
+	cli.Call(ctx, &jape.Request{Method: "2/tweets", HTTPMethod: "POST", Data: []byte(`{}`)})
+	select {
+	case r := <-reports:
+		t.Errorf("Unexpected shadow report for %q", r.Request.Method)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -28,6 +28,9 @@ func (e Edit) Invoke(ctx context.Context, cli *twitter.Client) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	if len(rsp.Data) == 0 {
+		return false, nil // no result, e.g., in dry-run mode
+	}
 	m := make(map[string]*bool)
 	if err := json.Unmarshal(rsp.Data, &m); err != nil {
 		return false, &jape.Error{Data: rsp.Data, Message: "decoding response", Err: err}
@@ -194,6 +194,26 @@ func (q Query) HasMorePages() bool {
 // invoking the query will then fetch the first page of results.
 func (q Query) ResetPageToken() { q.Request.Params.Reset(twitter.NextTokenParam) }
 
+// PageToken returns the page token the query will send when it is next
+// invoked, or "" if the query is fresh or has no more pages.
+func (q Query) PageToken() string { return q.Request.Params.Get(twitter.NextTokenParam) }
+
+// Iterate returns an iterator over the lists reported by q, which fetches
+// pages of results from cli as needed.
+func (q Query) Iterate(cli *twitter.Client) Iterator {
+	return Iterator{twitter.NewIterator[*Reply, *types.List](cli, q, func(r *Reply) []*types.List {
+		return r.Lists
+	})}
+}
+
+// An Iterator yields the lists reported by a paginated query.
+type Iterator struct {
+	*twitter.Iterator[*Reply, *types.List]
+}
+
+// List returns the current list. It is valid only after Next returns true.
+func (it Iterator) List() *types.List { return it.Item() }
+
 // A Reply is the response from a Query.
 type Reply struct {
 	*twitter.Reply
@@ -0,0 +1,444 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package twitter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/928799934/twitter/jape"
+)
+
+// Reconnection backoff parameters, following the schedule documented for the
+// streaming endpoints of the API:
+//
+//   - For network errors, back off linearly by 250ms per attempt, up to 16s.
+//   - For HTTP errors, back off exponentially from 5s, doubling up to 320s.
+//   - For HTTP 429 (rate limited), back off exponentially from 1m, doubling
+//     on each attempt. The API does not document a cap, so we cap at 16m.
+//
+// See https://developer.twitter.com/en/docs/twitter-api/tweets/filtered-stream/integrate/handling-disconnections
+const (
+	networkBackoffStep = 250 * time.Millisecond
+	networkBackoffMax  = 16 * time.Second
+	httpBackoffStart   = 5 * time.Second
+	httpBackoffMax     = 320 * time.Second
+	rateBackoffStart   = 1 * time.Minute
+	rateBackoffMax     = 16 * time.Minute
+)
+
+// DefaultStallTimeout is a reasonable StallTimeout for a managed stream. The
+// API sends a keep-alive message at least every 20 seconds on an idle stream.
+const DefaultStallTimeout = 30 * time.Second
+
+// MaxBackfillMinutes is the maximum number of minutes of backfill the API
+// accepts when reconnecting to a stream.
+const MaxBackfillMinutes = 5
+
+// StreamConfig provides parameters for a managed stream. A nil *StreamConfig
+// provides default values for all fields.
+type StreamConfig struct {
+	// If positive, request this many minutes of backfill when reconnecting
+	// after a disconnect, to recover messages sent during the outage.  Values
+	// greater than MaxBackfillMinutes are capped. Backfill requires Academic
+	// Research or Enterprise access.
+	BackfillMinutes int
+
+	// If positive, give up after this many consecutive failed attempts to
+	// reconnect. The initial attempt is not a retry, so a stream that never
+	// connects is tried MaxRetries+1 times. If zero, retry indefinitely.
+	MaxRetries int
+
+	// If positive, treat a connection as dead if no data (including
+	// keep-alive messages) arrive for this duration, and reconnect. This
+	// overrides the StreamReadTimeout of the client. If zero, the client
+	// setting is used. See also DefaultStallTimeout.
+	StallTimeout time.Duration
+
+	// If set, this function is called synchronously to report changes in the
+	// state of the connection.
+	OnEvent func(StreamEvent)
+
+	// If set, this function is called before each attempt to reconnect, after
+	// the backoff delay has elapsed, for example to recover messages missed
+	// during the outage by other means. If it reports an error, Run ends and
+	// returns that error, or nil if the error is jape.ErrStopStreaming.
+	BeforeReconnect func(context.Context, *Client) error
+}
+
+func (c *StreamConfig) backfill() int {
+	if c == nil || c.BackfillMinutes <= 0 {
+		return 0
+	} else if c.BackfillMinutes > MaxBackfillMinutes {
+		return MaxBackfillMinutes
+	}
+	return c.BackfillMinutes
+}
+
+// A StreamEvent reports a change in the connection state of a managed stream.
+type StreamEvent struct {
+	Kind StreamEventKind
+
+	// The number of consecutive failed connection attempts before this event.
+	Attempt int
+
+	// For StreamReconnecting, how long the stream will wait before the next
+	// attempt to connect.
+	Delay time.Duration
+
+	// For StreamDisconnected, the error that ended the connection, or nil if
+	// the server closed the stream without error.
+	Err error
+}
+
+// StreamEventKind enumerates the kinds of stream events.
+type StreamEventKind int
+
+// Constants for StreamEventKind.
+const (
+	StreamConnected    StreamEventKind = iota // the first message arrived on a new connection
+	StreamDisconnected                        // a connection ended, or failed to connect
+	StreamReconnecting                        // waiting to reconnect
+)
+
+var eventKindNames = [...]string{"connected", "disconnected", "reconnecting"}
+
+func (k StreamEventKind) String() string {
+	if k >= 0 && int(k) < len(eventKindNames) {
+		return eventKindNames[k]
+	}
+	return "StreamEventKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// A ManagedStream is a streaming request that automatically reconnects after
+// a disconnect, delivering a continuous run of messages to its callback.
+//
+// A disconnect caused by a network error, or by an HTTP error that may be
+// transient (such as a server error or rate limit), is retried following the
+// backoff schedule documented by the API. The stream ends when the callback
+// reports an error, when ctx ends, when the server reports an error that
+// cannot be resolved by retrying (such as an authorization failure), or when
+// the configured retry limit is exceeded. A connection that stalls (see
+// StreamConfig.StallTimeout) is treated as a network error.
+//
+// To shut down a running stream gracefully, use Drain or Stop.
+type ManagedStream struct {
+	req *jape.Request
+	f   Callback
+	cfg *StreamConfig
+
+	mu       sync.Mutex
+	stats    StreamStats
+	stop     context.CancelFunc // stops reading, if Run is active
+	done     chan struct{}      // closed when Run returns, if Run is active
+	draining bool               // Drain or Stop has been called
+	deadline <-chan struct{}    // closed when buffered messages are abandoned
+}
+
+// StreamStats records statistics about the activity of a managed stream.
+type StreamStats struct {
+	Connected    bool      // whether a connection is currently established
+	Messages     int64     // the number of messages delivered
+	Bytes        int64     // the total size in bytes of messages delivered
+	DecodeErrors int64     // the number of connections ended by an undecodable message
+	Reconnects   int64     // the number of attempts to reconnect
+	Abandoned    int64     // the number of messages discarded during shutdown
+	LastMessage  time.Time // when the latest message arrived (zero if none)
+}
+
+// SinceLastMessage reports the time elapsed between the latest message and
+// now, or 0 if no message has arrived.
+func (s StreamStats) SinceLastMessage(now time.Time) time.Duration {
+	if s.LastMessage.IsZero() {
+		return 0
+	}
+	return now.Sub(s.LastMessage)
+}
+
+// Stats returns a snapshot of the statistics for m. It is safe to call Stats
+// concurrently with Run, for example to export metrics or to alert on a
+// stream that has gone quiet.
+func (m *ManagedStream) Stats() StreamStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stats
+}
+
+func (m *ManagedStream) update(f func(*StreamStats)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f(&m.stats)
+}
+
+// NewManagedStream constructs a managed stream that issues req and delivers
+// each reply to f. If cfg == nil, default settings are used.
+func NewManagedStream(req *jape.Request, f Callback, cfg *StreamConfig) *ManagedStream {
+	return &ManagedStream{req: req, f: f, cfg: cfg}
+}
+
+// Run streams results from the server until the stream ends (see
+// ManagedStream). If the callback returns jape.ErrStopStreaming, Run returns
+// nil; otherwise Run returns the error that ended the stream.
+//
+// If m is stopped by Drain or Stop, Run returns nil.
+func (m *ManagedStream) Run(ctx context.Context, cli *Client) error {
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+	done := make(chan struct{})
+	defer close(done)
+	m.mu.Lock()
+	m.stop, m.done = stop, done
+	if m.draining {
+		stop()
+	}
+	m.mu.Unlock()
+
+	if m.cfg != nil && m.cfg.StallTimeout > 0 {
+		jc := *(*jape.Client)(cli)
+		jc.StreamReadTimeout = m.cfg.StallTimeout
+		cli = (*Client)(&jc)
+	}
+	var b backoff
+	for attempt := 0; ; attempt++ {
+		req := m.request(attempt > 0)
+
+		var cbErr error
+		var connected bool
+		err := cli.Stream(ctx, req, func(rsp *Reply) error {
+			if m.abandon() {
+				return nil
+			}
+			now := (*jape.Client)(cli).Now()
+			m.update(func(s *StreamStats) {
+				s.Connected = true
+				s.Messages++
+				if rsp.Info != nil {
+					s.Bytes += int64(rsp.Info.Bytes)
+				}
+				s.LastMessage = now
+			})
+			if !connected {
+				connected = true
+				b.reset()
+				m.event(StreamEvent{Kind: StreamConnected, Attempt: attempt})
+				attempt = 0
+			}
+			if err := m.f(rsp); err != nil {
+				cbErr = err
+				return err
+			}
+			return nil
+		})
+		m.update(func(s *StreamStats) {
+			s.Connected = false
+			if isDecodeError(err) {
+				s.DecodeErrors++
+			}
+		})
+		if errors.Is(cbErr, jape.ErrStopStreaming) {
+			return nil
+		} else if cbErr != nil {
+			return err
+		} else if m.isDraining() {
+			return nil
+		} else if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		m.event(StreamEvent{Kind: StreamDisconnected, Attempt: attempt, Err: err})
+
+		delay, ok := b.next(err)
+		if !ok {
+			return err
+		} else if n := m.maxRetries(); n > 0 && attempt+1 > n {
+			return err
+		}
+		m.update(func(s *StreamStats) { s.Reconnects++ })
+		m.event(StreamEvent{Kind: StreamReconnecting, Attempt: attempt + 1, Delay: delay})
+		select {
+		case <-ctx.Done():
+			if m.isDraining() {
+				return nil
+			}
+			return ctx.Err()
+		case <-(*jape.Client)(cli).After(delay):
+		}
+		if m.cfg != nil && m.cfg.BeforeReconnect != nil {
+			if err := m.cfg.BeforeReconnect(ctx, cli); errors.Is(err, jape.ErrStopStreaming) {
+				return nil
+			} else if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Drain shuts down m gracefully. It stops reading new data from the server,
+// and lets the callback finish processing messages already received, including
+// any held in a stream buffer (see jape.StreamBuffer), until ctx ends. Once
+// ctx ends, any remaining buffered messages are discarded without being
+// delivered. Drain waits for Run to return, and reports the number of
+// messages discarded.
+//
+// If Run has not returned when ctx ends, for example because the callback
+// is blocked, Drain returns ctx.Err() without waiting further, and the count
+// may be incomplete. If m is not running, Drain returns immediately, and a
+// subsequent call to Run will return nil without connecting.
+func (m *ManagedStream) Drain(ctx context.Context) (int64, error) {
+	done := m.shutdown(ctx.Done())
+	if done != nil {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			select {
+			case <-done:
+			default:
+				return m.Stats().Abandoned, ctx.Err()
+			}
+		}
+	}
+	return m.Stats().Abandoned, nil
+}
+
+// Stop shuts down m without delivering any further messages. It stops
+// reading new data from the server, waits for a callback already in progress
+// to complete, discards any remaining buffered messages, and waits for Run to
+// return. It reports the number of messages discarded.
+func (m *ManagedStream) Stop() int64 {
+	closed := make(chan struct{})
+	close(closed)
+	if done := m.shutdown(closed); done != nil {
+		<-done
+	}
+	return m.Stats().Abandoned
+}
+
+// shutdown marks m as draining with the given deadline, stops reading from
+// the server, and returns a channel that is closed when Run returns, or nil
+// if Run is not active.
+func (m *ManagedStream) shutdown(deadline <-chan struct{}) <-chan struct{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.draining = true
+	m.deadline = deadline
+	if m.stop != nil {
+		m.stop()
+	}
+	return m.done
+}
+
+func (m *ManagedStream) isDraining() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.draining
+}
+
+// abandon reports whether a message should be discarded because m is
+// shutting down and its drain deadline has passed. If so, the message is
+// counted as abandoned.
+func (m *ManagedStream) abandon() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.draining {
+		return false
+	}
+	select {
+	case <-m.deadline:
+		m.stats.Abandoned++
+		return true
+	default:
+		return false
+	}
+}
+
+// request returns the request to issue for a connection attempt. When
+// reconnecting, a backfill parameter is added if requested.
+func (m *ManagedStream) request(reconnect bool) *jape.Request {
+	n := m.cfg.backfill()
+	if !reconnect || n == 0 {
+		return m.req
+	}
+	cp := *m.req
+	cp.Params = make(jape.Params, len(m.req.Params)+1)
+	for name, vals := range m.req.Params {
+		cp.Params[name] = vals
+	}
+	cp.Params.Set("backfill_minutes", strconv.Itoa(n))
+	return &cp
+}
+
+func (m *ManagedStream) maxRetries() int {
+	if m.cfg == nil {
+		return 0
+	}
+	return m.cfg.MaxRetries
+}
+
+func (m *ManagedStream) event(e StreamEvent) {
+	if m.cfg != nil && m.cfg.OnEvent != nil {
+		m.cfg.OnEvent(e)
+	}
+}
+
+// backoff tracks the reconnection delays for a managed stream.
+type backoff struct {
+	network, http, rate time.Duration
+}
+
+func (b *backoff) reset() { *b = backoff{} }
+
+// next returns the delay before reconnecting after the given error, and
+// reports whether reconnecting is appropriate.
+func (b *backoff) next(err error) (time.Duration, bool) {
+	var jerr *jape.Error
+	status := 0
+	if errors.As(err, &jerr) {
+		status = jerr.Status
+	}
+	if errors.Is(err, ProblemUsageCapped) {
+		return 0, false // retrying will not help until the cap resets
+	} else if errors.Is(err, ProblemOperationalDisconnect) {
+		status = http.StatusServiceUnavailable // back off as for a server error
+	}
+	switch {
+	case status == 0:
+		// A network error, or the server closed the stream.
+		b.network += networkBackoffStep
+		if b.network > networkBackoffMax {
+			b.network = networkBackoffMax
+		}
+		return b.network, true
+
+	case status == http.StatusTooManyRequests:
+		b.rate = nextExponential(b.rate, rateBackoffStart, rateBackoffMax)
+		return b.rate, true
+
+	case status >= 500, status == http.StatusRequestTimeout, status == http.StatusConflict:
+		// N.B. The API reports 409 when a stream is reopened before the
+		// server has noticed the previous connection closed.
+		b.http = nextExponential(b.http, httpBackoffStart, httpBackoffMax)
+		return b.http, true
+	}
+	return 0, false // e.g., 400, 401, 403: retrying will not help
+}
+
+// isDecodeError reports whether err indicates that a message from the server
+// could not be decoded.
+func isDecodeError(err error) bool {
+	var serr *json.SyntaxError
+	var terr *json.UnmarshalTypeError
+	return errors.Is(err, jape.ErrMalformedMessage) || errors.As(err, &serr) || errors.As(err, &terr)
+}
+
+func nextExponential(cur, start, max time.Duration) time.Duration {
+	if cur == 0 {
+		return start
+	} else if cur *= 2; cur > max {
+		return max
+	}
+	return cur
+}
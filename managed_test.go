@@ -0,0 +1,274 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package twitter_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/jape"
+	"github.com/928799934/twitter/tweets"
+)
+
+func TestManagedStream(t *testing.T) {
+	var conns int
+	var backfill []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conns++
+		backfill = append(backfill, r.URL.Query().Get("backfill_minutes"))
+		switch conns {
+		case 1:
+			// Deliver one message, then a garbled one that ends the connection.
+			w.Write([]byte(`{"data":{"id":"1","text":"one"}}` + "\r\n"))
+			w.Write([]byte(`{"data":{"id":` + "\r\n"))
+		case 2:
+			http.Error(w, `{"title":"Service Unavailable"}`, http.StatusServiceUnavailable)
+		case 3:
+			w.Write([]byte(`{"data":{"id":"2","text":"two"}}` + "\r\n"))
+			w.Write([]byte(`{"data":{"id":"3","text":"three"}}` + "\r\n"))
+		default:
+			http.Error(w, `{"title":"Unauthorized"}`, http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+
+	clock := newFakeClock()
+	cli := twitter.NewClient(&jape.Client{BaseURL: srv.URL, Clock: clock})
+	ctx := context.Background()
+
+	var events []twitter.StreamEvent
+	var got []string
+	ms := tweets.SearchStream(func(rsp *tweets.Reply) error {
+		for _, tw := range rsp.Tweets {
+			got = append(got, tw.ID)
+		}
+		return nil
+	}, &tweets.StreamOpts{MaxResults: 3}).Managed(&twitter.StreamConfig{
+		BackfillMinutes: 10, // capped at MaxBackfillMinutes
+		OnEvent:         func(e twitter.StreamEvent) { events = append(events, e) },
+	})
+	err := ms.Run(ctx, cli)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if want := []string{"1", "2", "3"}; !equalStrings(got, want) {
+		t.Errorf("Tweets: got %q, want %q", got, want)
+	}
+	if want := []string{"", "5", "5"}; !equalStrings(backfill, want) {
+		t.Errorf("Backfill: got %q, want %q", backfill, want)
+	}
+
+	var kinds []string
+	for _, e := range events {
+		kinds = append(kinds, e.Kind.String())
+	}
+	want := []string{
+		"connected", "disconnected", "reconnecting", // malformed message
+		"disconnected", "reconnecting", // HTTP 503
+		"connected",
+	}
+	if !equalStrings(kinds, want) {
+		t.Errorf("Events: got %q, want %q", kinds, want)
+	}
+	if len(events) == len(want) {
+		if d := events[2].Delay; d != 250*time.Millisecond {
+			t.Errorf("Network backoff: got %v, want 250ms", d)
+		}
+		if d := events[4].Delay; d != 5*time.Second {
+			t.Errorf("HTTP backoff: got %v, want 5s", d)
+		}
+	}
+
+	st := ms.Stats()
+	if st.Connected || st.Messages != 3 || st.Reconnects != 2 || st.DecodeErrors != 1 || st.Bytes == 0 {
+		t.Errorf("Stats: got %+v, want 3 messages, 2 reconnects, 1 decode error", st)
+	}
+	if d := st.SinceLastMessage(clock.Now()); st.LastMessage.IsZero() || d != 0 {
+		t.Errorf("Stats: last message at %v (%v ago), want now", st.LastMessage, d)
+	}
+
+	t.Run("Fatal", func(t *testing.T) {
+		conns = 3 // the next connection is unauthorized
+		err := twitter.NewManagedStream(&jape.Request{Method: "2/tweets/search/stream"},
+			func(*twitter.Reply) error { return nil }, nil).Run(ctx, cli)
+		var jerr *jape.Error
+		if !errors.As(err, &jerr) || jerr.Status != http.StatusUnauthorized {
+			t.Errorf("Run: got %v, want unauthorized", err)
+		}
+		if conns != 4 {
+			t.Errorf("Run: made %d connections, want 4", conns)
+		}
+	})
+}
+
+func joinStrings(ss []string) string { return strings.Join(ss, " ") }
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestManagedStreamStall(t *testing.T) {
+	var conns int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&conns, 1)
+		w.Write([]byte(`{"data":{"id":"` + strconv.Itoa(int(n)) + `","text":"hi"}}` + "\r\n"))
+		w.(http.Flusher).Flush()
+		if n == 1 {
+			<-r.Context().Done() // stall the first connection
+		}
+	}))
+	defer srv.Close()
+
+	cli := twitter.NewClient(&jape.Client{BaseURL: srv.URL, Clock: newFakeClock()})
+	var errs []error
+	var got []string
+	err := tweets.SearchStream(func(rsp *tweets.Reply) error {
+		for _, tw := range rsp.Tweets {
+			got = append(got, tw.ID)
+		}
+		return nil
+	}, &tweets.StreamOpts{MaxResults: 2}).Managed(&twitter.StreamConfig{
+		StallTimeout: 100 * time.Millisecond,
+		OnEvent: func(e twitter.StreamEvent) {
+			if e.Kind == twitter.StreamDisconnected {
+				errs = append(errs, e.Err)
+			}
+		},
+	}).Run(context.Background(), cli)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if want := []string{"1", "2"}; !equalStrings(got, want) {
+		t.Errorf("Tweets: got %q, want %q", got, want)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], jape.ErrStreamStalled) {
+		t.Errorf("Disconnects: got %v, want %v", errs, jape.ErrStreamStalled)
+	}
+}
+
+func TestManagedStreamMaxRetries(t *testing.T) {
+	var conns int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&conns, 1)
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	cli := twitter.NewClient(&jape.Client{BaseURL: srv.URL, Clock: newFakeClock()})
+	var retries int
+	err := tweets.SearchStream(func(*tweets.Reply) error {
+		t.Error("Unexpected message")
+		return nil
+	}, nil).Managed(&twitter.StreamConfig{
+		MaxRetries: 2,
+		OnEvent: func(e twitter.StreamEvent) {
+			if e.Kind == twitter.StreamReconnecting {
+				retries++
+			}
+		},
+	}).Run(context.Background(), cli)
+	var jerr *jape.Error
+	if !errors.As(err, &jerr) || jerr.Status != http.StatusServiceUnavailable {
+		t.Errorf("Run: got error %v, want status %d", err, http.StatusServiceUnavailable)
+	}
+	if n := atomic.LoadInt32(&conns); n != 3 || retries != 2 {
+		t.Errorf("Run: got %d attempts and %d retries, want 3 and 2", n, retries)
+	}
+}
+
+func TestManagedStreamDrain(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 1; i <= 5; i++ {
+			w.Write([]byte(`{"data":{"id":"` + strconv.Itoa(i) + `"}}` + "\r\n"))
+		}
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	cli := twitter.NewClient(&jape.Client{
+		BaseURL:      srv.URL,
+		StreamBuffer: &jape.StreamBuffer{Size: 10},
+	})
+
+	// start runs a managed stream whose callback blocks on the first message
+	// until release is closed.
+	start := func() (ms *twitter.ManagedStream, release chan struct{}, delivered *int32, errc chan error) {
+		started := make(chan struct{})
+		release = make(chan struct{})
+		delivered = new(int32)
+		ms = twitter.NewManagedStream(&jape.Request{Method: "stream"}, func(*twitter.Reply) error {
+			if atomic.AddInt32(delivered, 1) == 1 {
+				close(started)
+				<-release
+			}
+			return nil
+		}, nil)
+		errc = make(chan error, 1)
+		go func() { errc <- ms.Run(context.Background(), cli) }()
+		<-started
+		time.Sleep(50 * time.Millisecond) // let the reader fill the buffer
+		return
+	}
+
+	t.Run("Drain", func(t *testing.T) {
+		ms, release, delivered, errc := start()
+		type result struct {
+			n   int64
+			err error
+		}
+		drained := make(chan result, 1)
+		go func() {
+			n, err := ms.Drain(context.Background())
+			drained <- result{n, err}
+		}()
+		time.Sleep(20 * time.Millisecond) // let Drain begin
+		close(release)
+
+		if r := <-drained; r.n != 0 || r.err != nil {
+			t.Errorf("Drain: got (%d, %v), want (0, nil)", r.n, r.err)
+		}
+		if err := <-errc; err != nil {
+			t.Errorf("Run: unexpected error: %v", err)
+		}
+		if n := atomic.LoadInt32(delivered); n != 5 {
+			t.Errorf("Delivered %d messages, want 5", n)
+		}
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		ms, release, delivered, errc := start()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := ms.Drain(ctx); !errors.Is(err, context.Canceled) {
+			t.Errorf("Drain: got %v, want %v", err, context.Canceled)
+		}
+		close(release)
+		if err := <-errc; err != nil {
+			t.Errorf("Run: unexpected error: %v", err)
+		}
+		if n := atomic.LoadInt32(delivered); n != 1 {
+			t.Errorf("Delivered %d messages, want 1", n)
+		}
+		if n := ms.Stop(); n != 4 {
+			t.Errorf("Abandoned %d messages, want 4", n)
+		}
+	})
+}
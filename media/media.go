@@ -0,0 +1,234 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+// Package media implements chunked media upload using the Twitter API v1.1.
+//
+// To attach images or video to a tweet, first upload the media, then pass the
+// media ID to the tweet:
+//
+//	info, err := media.Upload(ctx, cli, f, size, "image/png", nil)
+//	...
+//	rsp, err := tweets.Create(tweets.CreateOpts{
+//	   Text:     "look at this",
+//	   MediaIDs: []string{info.ID},
+//	}).Invoke(ctx, cli)
+//
+// To upload several files and post a tweet with them in one call, use
+// media.Post. Uploads require user-context authorization.
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"strconv"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/jape"
+)
+
+// UploadURL is the default base URL for the media upload API.
+const UploadURL = "https://upload.twitter.com"
+
+// DefaultChunkSize is the default size of each chunk of an upload.
+const DefaultChunkSize = 1 << 20
+
+// MaxChunkSize is the largest chunk the upload API accepts.
+const MaxChunkSize = 5 << 20
+
+// Constants for the media category of an upload.
+const (
+	CategoryImage = "tweet_image"
+	CategoryGIF   = "tweet_gif"
+	CategoryVideo = "tweet_video"
+)
+
+// Upload uploads size bytes of media of the given MIME type from r, and
+// reports the resulting media object. The media are sent in chunks (see
+// UploadOpts.ChunkSize), using the INIT, APPEND, and FINALIZE commands.
+//
+// Media that require processing by the server, such as video, may not be
+// usable as soon as Upload returns. In that case the Processing field of the
+// result is set (see ProcessingInfo); use Await to wait for processing.
+//
+// API: 1.1/media/upload.json
+func Upload(ctx context.Context, cli *twitter.Client, r io.Reader, size int64, mimeType string, opts *UploadOpts) (*Info, error) {
+	ucli := opts.client(cli)
+	init := jape.Params{
+		"command":     []string{"INIT"},
+		"total_bytes": []string{strconv.FormatInt(size, 10)},
+		"media_type":  []string{mimeType},
+	}
+	opts.addInitParams(init)
+	info, err := command(ctx, ucli, init)
+	if err != nil {
+		return nil, err
+	} else if info.ID == "" {
+		return nil, &jape.Error{Message: "upload did not report a media ID"}
+	}
+
+	buf := make([]byte, opts.chunkSize())
+	var sent int64
+	for seg := 0; sent < size; seg++ {
+		chunk := buf
+		if left := size - sent; left < int64(len(chunk)) {
+			chunk = chunk[:left]
+		}
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			return nil, fmt.Errorf("reading media: %w", err)
+		}
+		if err := appendChunk(ctx, ucli, info.ID, seg, chunk); err != nil {
+			return nil, err
+		}
+		sent += int64(len(chunk))
+		if opts != nil && opts.Progress != nil {
+			opts.Progress(Progress{Chunk: seg, Sent: sent, Total: size})
+		}
+	}
+
+	return command(ctx, ucli, jape.Params{
+		"command":  []string{"FINALIZE"},
+		"media_id": []string{info.ID},
+	})
+}
+
+// command issues an upload command with the given form parameters, and
+// decodes the resulting media object.
+func command(ctx context.Context, cli *twitter.Client, params jape.Params) (*Info, error) {
+	req := &jape.Request{
+		Method:     "1.1/media/upload.json",
+		HTTPMethod: "POST",
+		Params:     params,
+	}
+	req.SetBodyToParams()
+	data, err := cli.CallRaw(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return decodeInfo(data)
+}
+
+// decodeInfo decodes a media object from a response body.
+func decodeInfo(data []byte) (*Info, error) {
+	info := new(Info)
+	if len(data) != 0 {
+		if err := json.Unmarshal(data, info); err != nil {
+			return nil, &jape.Error{Data: data, Message: "decoding response body", Err: err}
+		}
+	}
+	return info, nil
+}
+
+// appendChunk uploads one chunk of media with the APPEND command. The chunk
+// is sent as multipart form data.
+func appendChunk(ctx context.Context, cli *twitter.Client, id string, seg int, chunk []byte) error {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	w.WriteField("command", "APPEND")
+	w.WriteField("media_id", id)
+	w.WriteField("segment_index", strconv.Itoa(seg))
+	part, err := w.CreateFormFile("media", "media")
+	if err != nil {
+		return err
+	}
+	part.Write(chunk)
+	if err := w.Close(); err != nil {
+		return err
+	}
+	_, err = cli.CallRaw(ctx, &jape.Request{
+		Method:      "1.1/media/upload.json",
+		HTTPMethod:  "POST",
+		Data:        body.Bytes(),
+		ContentType: w.FormDataContentType(),
+	})
+	return err
+}
+
+// An Info reports the state of an uploaded media object.
+type Info struct {
+	ID           string          `json:"media_id_string"`
+	Size         int64           `json:"size,omitempty"`
+	ExpiresAfter int             `json:"expires_after_secs,omitempty"` // seconds
+	Processing   *ProcessingInfo `json:"processing_info,omitempty"`
+}
+
+// ProcessingInfo reports the progress of server-side processing of uploaded
+// media. The media may not be attached to a tweet until processing succeeds.
+type ProcessingInfo struct {
+	State          string `json:"state"` // "pending", "in_progress", "succeeded", or "failed"
+	CheckAfterSecs int    `json:"check_after_secs,omitempty"`
+	Progress       int    `json:"progress_percent,omitempty"`
+
+	// If processing failed, the reason for the failure.
+	Error *struct {
+		Code    int    `json:"code"`
+		Name    string `json:"name"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// UploadOpts provides parameters for media upload. A nil *UploadOpts provides
+// empty or zero values for all fields.
+type UploadOpts struct {
+	// The media category, e.g., CategoryVideo. Some kinds of media, such as
+	// long videos, are accepted only with the matching category.
+	Category string
+
+	// User IDs, other than the uploader, permitted to use the media.
+	AdditionalOwners []string
+
+	// The size of each chunk; if zero, use DefaultChunkSize.
+	// Values greater than MaxChunkSize are capped.
+	ChunkSize int
+
+	// The base URL for the upload API; if empty, use UploadURL.
+	BaseURL string
+
+	// If set, this function is called synchronously after each chunk is
+	// uploaded, to report the progress of the upload.
+	Progress func(Progress)
+}
+
+// Progress reports the progress of an upload, after a chunk is sent.
+type Progress struct {
+	Chunk int   // the index of the chunk just sent, from 0
+	Sent  int64 // the number of bytes sent so far
+	Total int64 // the total size of the upload
+}
+
+func (o *UploadOpts) addInitParams(params jape.Params) {
+	if o == nil {
+		return // nothing to do
+	}
+	if o.Category != "" {
+		params.Set("media_category", o.Category)
+	}
+	params.Add("additional_owners", o.AdditionalOwners...)
+}
+
+func (o *UploadOpts) chunkSize() int {
+	if o == nil || o.ChunkSize <= 0 {
+		return DefaultChunkSize
+	} else if o.ChunkSize > MaxChunkSize {
+		return MaxChunkSize
+	}
+	return o.ChunkSize
+}
+
+// client returns a copy of cli that sends requests to the upload API.
+// Requests are not mirrored to a shadow client, since uploads are not
+// idempotent, and are not cached or coalesced, since status checks must see
+// the current state of the upload.
+func (o *UploadOpts) client(cli *twitter.Client) *twitter.Client {
+	uc := *(*jape.Client)(cli)
+	uc.BaseURL = UploadURL
+	if o != nil && o.BaseURL != "" {
+		uc.BaseURL = o.BaseURL
+	}
+	uc.Shadow = nil
+	uc.Cache = nil
+	uc.Coalesce = nil
+	return (*twitter.Client)(&uc)
+}
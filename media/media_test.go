@@ -0,0 +1,179 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package media_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/jape"
+	"github.com/928799934/twitter/media"
+	"github.com/928799934/twitter/tweets"
+)
+
+func TestUpload(t *testing.T) {
+	const content = "0123456789"
+	var got strings.Builder
+	var cmds, progress []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/1.1/media/upload.json" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil && err != http.ErrNotMultipart {
+			t.Fatalf("Parsing form: %v", err)
+		}
+		cmd := r.FormValue("command")
+		cmds = append(cmds, cmd+r.FormValue("segment_index"))
+		switch cmd {
+		case "INIT":
+			if r.FormValue("total_bytes") != "10" || r.FormValue("media_type") != "image/png" ||
+				r.FormValue("media_category") != media.CategoryImage {
+				t.Errorf("INIT: unexpected form %v", r.Form)
+			}
+			io.WriteString(w, `{"media_id":99,"media_id_string":"99","expires_after_secs":86400}`)
+		case "APPEND":
+			if id := r.FormValue("media_id"); id != "99" {
+				t.Errorf("APPEND: got media ID %q, want 99", id)
+			}
+			f, _, err := r.FormFile("media")
+			if err != nil {
+				t.Fatalf("APPEND: reading media: %v", err)
+			}
+			io.Copy(&got, f)
+			w.WriteHeader(http.StatusNoContent)
+		case "FINALIZE":
+			io.WriteString(w, `{"media_id":99,"media_id_string":"99","size":10}`)
+		default:
+			t.Errorf("Unexpected command %q", cmd)
+		}
+	}))
+	defer srv.Close()
+	cli := twitter.NewClient(&jape.Client{BaseURL: "http://api.invalid"})
+
+	info, err := media.Upload(context.Background(), cli, strings.NewReader(content),
+		int64(len(content)), "image/png", &media.UploadOpts{
+			Category:  media.CategoryImage,
+			ChunkSize: 4,
+			BaseURL:   srv.URL,
+			Progress: func(p media.Progress) {
+				progress = append(progress, fmt.Sprintf("%d:%d/%d", p.Chunk, p.Sent, p.Total))
+			},
+		})
+	if err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+	if info.ID != "99" || info.Size != 10 {
+		t.Errorf("Upload: got %+v, want ID 99 and size 10", info)
+	}
+	if got.String() != content {
+		t.Errorf("Uploaded content: got %q, want %q", got.String(), content)
+	}
+	if want := "INIT APPEND0 APPEND1 APPEND2 FINALIZE"; strings.Join(cmds, " ") != want {
+		t.Errorf("Commands: got %q, want %q", cmds, want)
+	}
+	if want := "0:4/10 1:8/10 2:10/10"; strings.Join(progress, " ") != want {
+		t.Errorf("Progress: got %q, want %q", progress, want)
+	}
+}
+
+// instantClock is a jape.Clock whose timers fire immediately.
+type instantClock struct{}
+
+func (instantClock) Now() time.Time { return time.Now() }
+
+func (instantClock) After(time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	ch <- time.Now()
+	return ch
+}
+
+func TestPost(t *testing.T) {
+	var mu sync.Mutex
+	var nextID int
+	var status int    // number of STATUS checks
+	var posted string // the tweet body
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path == "/2/tweets" {
+			body, _ := io.ReadAll(r.Body)
+			posted = string(body)
+			io.WriteString(w, `{"data":{"id":"t1","text":"sunset"}}`)
+			return
+		}
+		r.ParseMultipartForm(1 << 20)
+		switch r.FormValue("command") {
+		case "INIT":
+			if r.FormValue("total_bytes") == "0" {
+				http.Error(w, `{"errors":[{"message":"empty"}]}`, http.StatusBadRequest)
+				return
+			}
+			nextID++
+			fmt.Fprintf(w, `{"media_id_string":"m%d"}`, nextID)
+		case "APPEND":
+			w.WriteHeader(http.StatusNoContent)
+		case "FINALIZE":
+			fmt.Fprintf(w, `{"media_id_string":%q,"processing_info":{"state":"pending","check_after_secs":5}}`,
+				r.FormValue("media_id"))
+		case "STATUS":
+			status++
+			fmt.Fprintf(w, `{"media_id_string":%q,"processing_info":{"state":"succeeded"}}`,
+				r.FormValue("media_id"))
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL)
+		}
+	}))
+	defer srv.Close()
+	cli := twitter.NewClient(&jape.Client{BaseURL: srv.URL, Clock: instantClock{}})
+	opts := &media.PostOpts{Concurrency: 2, Upload: &media.UploadOpts{BaseURL: srv.URL}}
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "sunset.png")
+	if err := os.WriteFile(path, []byte("png data"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	rsp, err := media.Post(ctx, cli, tweets.CreateOpts{Text: "sunset"}, []media.File{
+		{Path: path},
+		{Reader: strings.NewReader("gif data"), Size: 8, MIMEType: "image/gif", Category: media.CategoryGIF},
+	}, opts)
+	if err != nil {
+		t.Fatalf("Post failed: %v", err)
+	}
+	if len(rsp.Tweets) != 1 || rsp.Tweets[0].ID != "t1" {
+		t.Errorf("Post: got %+v, want tweet t1", rsp.Tweets)
+	}
+	if !strings.Contains(posted, `"media_ids":["m1","m2"]`) && !strings.Contains(posted, `"media_ids":["m2","m1"]`) {
+		t.Errorf("Post: tweet body %s does not attach both media", posted)
+	}
+	if status != 2 {
+		t.Errorf("Post: got %d status checks, want 2", status)
+	}
+
+	// If an upload fails, the tweet is not posted.
+	posted = ""
+	_, err = media.Post(ctx, cli, tweets.CreateOpts{Text: "oops"}, []media.File{
+		{Reader: strings.NewReader("ok"), Size: 2, MIMEType: "image/png"},
+		{Reader: strings.NewReader(""), Size: 0, MIMEType: "image/png"},
+	}, opts)
+	var berr *twitter.BatchError
+	if !errors.As(err, &berr) {
+		t.Fatalf("Post: got error %v, want *twitter.BatchError", err)
+	}
+	if berr.Errors[0] != nil || berr.Errors[1] == nil {
+		t.Errorf("Post: got per-file errors %v, want only the second", berr.Errors)
+	}
+	if posted != "" {
+		t.Errorf("Post: tweet was posted after a failed upload: %s", posted)
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package media
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/jape"
+	"github.com/928799934/twitter/tweets"
+)
+
+// Status reports the current state of the media object with the given ID.
+// This is used to check the progress of server-side processing.
+//
+// API: 1.1/media/upload.json?command=STATUS
+func Status(ctx context.Context, cli *twitter.Client, id string, opts *UploadOpts) (*Info, error) {
+	req := &jape.Request{
+		Method: "1.1/media/upload.json",
+		Params: jape.Params{
+			"command":  []string{"STATUS"},
+			"media_id": []string{id},
+		},
+		NoCache: true,
+	}
+	data, err := opts.client(cli).CallRaw(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return decodeInfo(data)
+}
+
+// Await waits until server-side processing of uploaded media is complete,
+// checking its status at the intervals the server requests, and reports the
+// final state. If info does not require processing, Await returns it
+// unchanged. If processing fails, Await reports an error.
+func Await(ctx context.Context, cli *twitter.Client, info *Info, opts *UploadOpts) (*Info, error) {
+	jc := (*jape.Client)(cli)
+	for info.Processing != nil {
+		p := info.Processing
+		switch p.State {
+		case "succeeded":
+			return info, nil
+		case "failed":
+			msg := "processing failed"
+			if p.Error != nil {
+				msg += ": " + p.Error.Message
+			}
+			return nil, &jape.Error{Message: "media " + info.ID + ": " + msg}
+		}
+		wait := time.Duration(p.CheckAfterSecs) * time.Second
+		if wait <= 0 {
+			wait = time.Second
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-jc.After(wait):
+		}
+		next, err := Status(ctx, cli, info.ID, opts)
+		if err != nil {
+			return nil, err
+		}
+		info = next
+	}
+	return info, nil
+}
+
+// A File is a media attachment to upload with Post.
+type File struct {
+	// The path of a local file to upload. This is used only if Reader is nil,
+	// in which case Size and MIMEType default to the size of the file and the
+	// type implied by its extension.
+	Path string
+
+	// If set, the media are read from here, and Size and MIMEType must be set.
+	Reader   io.Reader
+	Size     int64
+	MIMEType string
+
+	// If set, this overrides the media category of the upload options.
+	Category string
+}
+
+// open returns a reader for the contents of f, and a function to release it.
+func (f File) open() (File, func(), error) {
+	if f.Reader != nil {
+		return f, func() {}, nil
+	}
+	fp, err := os.Open(f.Path)
+	if err != nil {
+		return f, nil, err
+	}
+	fi, err := fp.Stat()
+	if err != nil {
+		fp.Close()
+		return f, nil, err
+	}
+	f.Reader = fp
+	if f.Size == 0 {
+		f.Size = fi.Size()
+	}
+	if f.MIMEType == "" {
+		f.MIMEType = mime.TypeByExtension(filepath.Ext(f.Path))
+	}
+	return f, func() { fp.Close() }, nil
+}
+
+// Post uploads the given media files, waits for any processing to complete,
+// and then posts a tweet with the settings in tweet, attaching the uploaded
+// media in the order given. Any media IDs already in tweet.MediaIDs are
+// attached before the new ones.
+//
+//	rsp, err := media.Post(ctx, cli, tweets.CreateOpts{Text: "sunset"}, []media.File{
+//	   {Path: "sunset1.jpg"},
+//	   {Path: "sunset2.jpg"},
+//	}, &media.PostOpts{Concurrency: 2})
+//
+// If any of the files cannot be uploaded, the tweet is not posted, and the
+// error has concrete type *twitter.BatchError, whose Errors slice reports
+// the failure (if any) for each file in the same order as files.
+//
+// API: 1.1/media/upload.json, POST 2/tweets
+func Post(ctx context.Context, cli *twitter.Client, tweet tweets.CreateOpts, files []File, opts *PostOpts) (*tweets.Reply, error) {
+	qs := make([]twitter.Query[*Info], len(files))
+	for i, f := range files {
+		qs[i] = uploadQuery{file: f, opts: opts.uploadOpts(f)}
+	}
+	infos, err := twitter.Batch[*Info]{Concurrency: opts.concurrency()}.Run(ctx, cli, qs...)
+	if err != nil {
+		return nil, err
+	}
+	ids := append([]string(nil), tweet.MediaIDs...)
+	for _, info := range infos {
+		ids = append(ids, info.ID)
+	}
+	tweet.MediaIDs = ids
+	return tweets.Create(tweet).Invoke(ctx, cli)
+}
+
+// uploadQuery is a twitter.Query that uploads a file and awaits processing.
+type uploadQuery struct {
+	file File
+	opts *UploadOpts
+}
+
+func (q uploadQuery) Invoke(ctx context.Context, cli *twitter.Client) (*Info, error) {
+	name := q.file.Path
+	if name == "" {
+		name = "media"
+	}
+	f, done, err := q.file.open()
+	if err != nil {
+		return nil, err // the error from os includes the path
+	}
+	defer done()
+	info, err := Upload(ctx, cli, f.Reader, f.Size, f.MIMEType, q.opts)
+	if err == nil {
+		info, err = Await(ctx, cli, info, q.opts)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("upload %s: %w", name, err)
+	}
+	return info, nil
+}
+
+// PostOpts provides parameters for Post. A nil *PostOpts provides empty or
+// zero values for all fields.
+type PostOpts struct {
+	// Upload up to this many files concurrently. If zero, files are uploaded
+	// one at a time.
+	Concurrency int
+
+	// Options for each upload. Note that if Concurrency > 1, the Progress
+	// function may be called concurrently by different uploads.
+	Upload *UploadOpts
+}
+
+func (o *PostOpts) concurrency() int {
+	if o == nil {
+		return 0
+	}
+	return o.Concurrency
+}
+
+// uploadOpts returns the upload options for f.
+func (o *PostOpts) uploadOpts(f File) *UploadOpts {
+	var uo UploadOpts
+	if o != nil && o.Upload != nil {
+		uo = *o.Upload
+	}
+	if f.Category != "" {
+		uo.Category = f.Category
+	}
+	return &uo
+}
@@ -59,6 +59,10 @@ func (q Query) HasMorePages() bool { return ocall.HasMorePages(q.Request) }
 // invoking the query will then fetch the first page of results.
 func (q Query) ResetPageToken() { ocall.ResetPageToken(q.Request) }
 
+// PageToken returns the page token the query will send when it is next
+// invoked, or "" if the query is fresh or has no more pages.
+func (q Query) PageToken() string { return ocall.PageToken(q.Request) }
+
 // Invoke executes the query and returns the matching users.
 func (q Query) Invoke(ctx context.Context, cli *twitter.Client) (*Reply, error) {
 	return ocall.GetUsers(ctx, q.Request, q.opts, cli)
@@ -0,0 +1,215 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+// Package openapi exposes metadata about the endpoints of the Twitter API v2,
+// from the OpenAPI specification the API publishes.
+//
+// The package ships a snapshot of the specification, covering the endpoints
+// used most often, which openapi.Default returns. To fetch the current
+// specification from the server, use openapi.Fetch; to load a copy saved
+// earlier, use openapi.Parse. Then look up endpoints by
+// method and path, using either the notation of this module's documentation
+// or that of the specification:
+//
+//	spec, err := openapi.Fetch(ctx, cli)
+//	...
+//	ep := spec.Lookup("GET", "2/tweets/:id")
+//	fmt.Println(ep.Scopes())
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/jape"
+)
+
+//go:embed spec.json
+var snapshot []byte
+
+var defaultSpec struct {
+	once sync.Once
+	spec *Spec
+}
+
+// Default returns the specification parsed from the snapshot built into the
+// package. The snapshot may lag the live API and describes only a subset of
+// its endpoints; use Fetch to obtain the current specification. The result is
+// shared, and the caller must not modify it.
+func Default() *Spec {
+	defaultSpec.once.Do(func() {
+		spec, err := Parse(snapshot)
+		if err != nil {
+			panic("openapi: invalid built-in specification: " + err.Error())
+		}
+		defaultSpec.spec = spec
+	})
+	return defaultSpec.spec
+}
+
+// Fetch fetches and parses the current API specification from the server.
+//
+// API: 2/openapi.json
+func Fetch(ctx context.Context, cli *twitter.Client) (*Spec, error) {
+	data, err := cli.CallRaw(ctx, &jape.Request{Method: "2/openapi.json"})
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// A Spec is the parsed metadata of an API specification.
+type Spec struct {
+	Version   string      // the API version reported by the specification
+	Endpoints []*Endpoint // ordered by path, then method
+
+	index map[string]*Endpoint
+}
+
+// An Endpoint describes a single API operation.
+type Endpoint struct {
+	Method      string // e.g., "GET"
+	Path        string // as written in the specification, e.g., "/2/tweets/{id}"
+	OperationID string
+	Summary     string
+	Parameters  []*Parameter
+
+	// The security schemes that permit calling the endpoint. Each scheme
+	// maps to the OAuth scopes it requires, if any.
+	Security map[string][]string
+
+	// Specification extensions (x-* fields) of the operation, undecoded.
+	Extensions map[string]json.RawMessage
+}
+
+// Scopes returns the OAuth 2.0 scopes required to call e with user-context
+// authorization, or nil if the specification does not list any.
+func (e *Endpoint) Scopes() []string { return e.Security["OAuth2UserToken"] }
+
+// A Parameter describes a parameter of an endpoint.
+type Parameter struct {
+	Name        string `json:"name"`
+	In          string `json:"in"` // "path", "query", or "header"
+	Required    bool   `json:"required"`
+	Description string `json:"description"`
+}
+
+// Lookup returns the endpoint with the given HTTP method and path, or nil if
+// there is none. The path may be written as in the specification (e.g.,
+// "/2/users/{id}/tweets") or as in the documentation of this module (e.g.,
+// "2/users/:id/tweets"); the names of path parameters need not match.
+func (s *Spec) Lookup(method, path string) *Endpoint {
+	return s.index[indexKey(method, path)]
+}
+
+// indexKey returns a key for method and path that ignores the notation and
+// names of path parameters.
+func indexKey(method, path string) string {
+	segs := strings.Split(strings.Trim(path, "/"), "/")
+	for i, seg := range segs {
+		if strings.HasPrefix(seg, ":") || strings.HasPrefix(seg, "{") {
+			segs[i] = "*"
+		}
+	}
+	return strings.ToUpper(method) + " " + strings.Join(segs, "/")
+}
+
+// operationMethods are the fields of a path item that describe operations.
+var operationMethods = []string{"get", "put", "post", "delete", "patch"}
+
+// Parse parses an API specification in OpenAPI 3 JSON format.
+func Parse(data []byte) (*Spec, error) {
+	var doc struct {
+		Info struct {
+			Version string `json:"version"`
+		} `json:"info"`
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Parameters map[string]*Parameter `json:"parameters"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, &jape.Error{Message: "decoding specification", Err: err}
+	}
+
+	// Parameters are given inline, or by reference to a shared definition.
+	type paramRef struct {
+		Parameter
+		Ref string `json:"$ref"`
+	}
+	resolve := func(ps []*paramRef) []*Parameter {
+		var out []*Parameter
+		for _, p := range ps {
+			if p.Ref == "" {
+				out = append(out, &p.Parameter)
+			} else if def := doc.Components.Parameters[strings.TrimPrefix(p.Ref, "#/components/parameters/")]; def != nil {
+				out = append(out, def)
+			}
+		}
+		return out
+	}
+
+	spec := &Spec{Version: doc.Info.Version, index: make(map[string]*Endpoint)}
+	for path, item := range doc.Paths {
+		var common []*paramRef
+		if raw, ok := item["parameters"]; ok {
+			if err := json.Unmarshal(raw, &common); err != nil {
+				return nil, &jape.Error{Data: raw, Message: "decoding parameters of " + path, Err: err}
+			}
+		}
+		for _, method := range operationMethods {
+			raw, ok := item[method]
+			if !ok {
+				continue
+			}
+			var op struct {
+				OperationID string                `json:"operationId"`
+				Summary     string                `json:"summary"`
+				Parameters  []*paramRef           `json:"parameters"`
+				Security    []map[string][]string `json:"security"`
+			}
+			if err := json.Unmarshal(raw, &op); err != nil {
+				return nil, &jape.Error{Data: raw, Message: "decoding operation " + method + " " + path, Err: err}
+			}
+			ep := &Endpoint{
+				Method:      strings.ToUpper(method),
+				Path:        path,
+				OperationID: op.OperationID,
+				Summary:     op.Summary,
+				Parameters:  resolve(append(common, op.Parameters...)),
+			}
+			for _, req := range op.Security {
+				for scheme, scopes := range req {
+					if ep.Security == nil {
+						ep.Security = make(map[string][]string)
+					}
+					ep.Security[scheme] = scopes
+				}
+			}
+			var fields map[string]json.RawMessage
+			json.Unmarshal(raw, &fields) // already known to be a valid object
+			for name, val := range fields {
+				if strings.HasPrefix(name, "x-") {
+					if ep.Extensions == nil {
+						ep.Extensions = make(map[string]json.RawMessage)
+					}
+					ep.Extensions[name] = val
+				}
+			}
+			spec.Endpoints = append(spec.Endpoints, ep)
+			spec.index[indexKey(method, path)] = ep
+		}
+	}
+	sort.Slice(spec.Endpoints, func(i, j int) bool {
+		a, b := spec.Endpoints[i], spec.Endpoints[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Method < b.Method
+	})
+	return spec, nil
+}
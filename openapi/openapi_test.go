@@ -0,0 +1,116 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package openapi_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/jape"
+	"github.com/928799934/twitter/openapi"
+)
+
+// testSpec is a fragment in the shape of the published specification.
+const testSpec = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Twitter API v2", "version": "2.54"},
+  "paths": {
+    "/2/tweets/{id}": {
+      "get": {
+        "operationId": "findTweetById",
+        "summary": "Tweet lookup by Tweet ID",
+        "security": [{"BearerToken": []}, {"OAuth2UserToken": ["tweet.read", "users.read"]}, {"UserToken": []}],
+        "parameters": [
+          {"$ref": "#/components/parameters/TweetFieldsParameter"},
+          {"name": "id", "in": "path", "required": true, "description": "A single Tweet ID."}
+        ],
+        "x-twitter-streaming": false
+      },
+      "delete": {
+        "operationId": "deleteTweetById",
+        "security": [{"OAuth2UserToken": ["tweet.read", "tweet.write", "users.read"]}],
+        "parameters": [{"name": "id", "in": "path", "required": true}]
+      }
+    }
+  },
+  "components": {
+    "parameters": {
+      "TweetFieldsParameter": {"name": "tweet.fields", "in": "query", "required": false}
+    }
+  }
+}`
+
+func TestSpec(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2/openapi.json" {
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+		io.WriteString(w, testSpec)
+	}))
+	defer srv.Close()
+	cli := twitter.NewClient(&jape.Client{BaseURL: srv.URL})
+
+	spec, err := openapi.Fetch(context.Background(), cli)
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if spec.Version != "2.54" || len(spec.Endpoints) != 2 {
+		t.Errorf("Fetch: got version %q with %d endpoints", spec.Version, len(spec.Endpoints))
+	}
+
+	ep := spec.Lookup("get", "2/tweets/:tweetID")
+	if ep == nil {
+		t.Fatal("Lookup GET 2/tweets/:tweetID: not found")
+	}
+	if ep != spec.Lookup("GET", "/2/tweets/{id}") {
+		t.Error("Lookup: notations do not find the same endpoint")
+	}
+	if ep.OperationID != "findTweetById" {
+		t.Errorf("OperationID: got %q", ep.OperationID)
+	}
+	if got := strings.Join(ep.Scopes(), " "); got != "tweet.read users.read" {
+		t.Errorf("Scopes: got %q", got)
+	}
+	var params []string
+	for _, p := range ep.Parameters {
+		params = append(params, p.In+":"+p.Name)
+	}
+	if got, want := strings.Join(params, " "), "query:tweet.fields path:id"; got != want {
+		t.Errorf("Parameters: got %q, want %q", got, want)
+	}
+	if string(ep.Extensions["x-twitter-streaming"]) != "false" {
+		t.Errorf("Extensions: got %v", ep.Extensions)
+	}
+
+	if ep := spec.Lookup("DELETE", "2/tweets/:id"); ep == nil || ep.Scopes()[1] != "tweet.write" {
+		t.Errorf("Lookup DELETE 2/tweets/:id: got %+v", ep)
+	}
+	if ep := spec.Lookup("POST", "2/tweets/:id"); ep != nil {
+		t.Errorf("Lookup POST 2/tweets/:id: got %+v, want nil", ep)
+	}
+}
+
+func TestDefault(t *testing.T) {
+	spec := openapi.Default()
+	if spec != openapi.Default() {
+		t.Error("Default: results are not shared")
+	}
+	if ep := spec.Lookup("GET", "2/users/:id/tweets"); ep == nil || ep.OperationID != "usersIdTweets" {
+		t.Errorf("Lookup GET 2/users/:id/tweets: got %+v", ep)
+	}
+	ep := spec.Lookup("GET", "2/tweets/search/stream")
+	if ep == nil {
+		t.Fatal("Lookup GET 2/tweets/search/stream: not found")
+	}
+	if string(ep.Extensions["x-twitter-streaming"]) != "true" {
+		t.Errorf("Extensions: got %v", ep.Extensions)
+	}
+	if got := strings.Join(spec.Lookup("GET", "2/users/me").Scopes(), " "); got != "tweet.read users.read" {
+		t.Errorf("Scopes of GET 2/users/me: got %q", got)
+	}
+}
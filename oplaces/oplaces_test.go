@@ -0,0 +1,75 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package oplaces_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/jape"
+	"github.com/928799934/twitter/oplaces"
+)
+
+const testPlace = `{"id":"01a9a39529b27f36","name":"Manhattan","full_name":"Manhattan, NY",` +
+	`"place_type":"city","country":"United States","country_code":"US",` +
+	`"contained_within":[{"id":"94965b2c45386f87","name":"New York"}],` +
+	`"bounding_box":{"type":"Polygon","coordinates":[[[-74.02,40.68],[-73.90,40.68],[-73.90,40.88],[-74.02,40.88]]]}}`
+
+func TestPlaces(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/1.1/geo/id/01a9a39529b27f36.json":
+			io.WriteString(w, testPlace)
+		case "/1.1/geo/search.json":
+			q := r.URL.Query()
+			if q.Get("query") != "Manhattan" || q.Get("lat") != "40.75" || q.Get("granularity") != "city" {
+				t.Errorf("Search: unexpected query %q", r.URL.RawQuery)
+			}
+			io.WriteString(w, `{"query":{},"result":{"places":[`+testPlace+`]}}`)
+		default:
+			t.Errorf("Unexpected path %q", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	cli := twitter.NewClient(&jape.Client{BaseURL: srv.URL})
+	ctx := context.Background()
+
+	for _, test := range []struct {
+		name string
+		q    oplaces.Query
+	}{
+		{"Lookup", oplaces.Lookup("01a9a39529b27f36")},
+		{"Search", oplaces.Search(&oplaces.SearchOpts{
+			Query:          "Manhattan",
+			Lat:            40.75,
+			Long:           -73.99,
+			HasCoordinates: true,
+			Granularity:    "city",
+		})},
+	} {
+		rsp, err := test.q.Invoke(ctx, cli)
+		if err != nil {
+			t.Errorf("%s failed: %v", test.name, err)
+			continue
+		}
+		if len(rsp.Places) != 1 {
+			t.Errorf("%s: got %d places, want 1", test.name, len(rsp.Places))
+			continue
+		}
+		p := rsp.Places[0]
+		if p.ID != "01a9a39529b27f36" || p.Type != "city" || p.CountryCode != "US" {
+			t.Errorf("%s: got place %+v", test.name, p)
+		}
+		if len(p.ContainedIn) != 1 || p.ContainedIn[0] != "94965b2c45386f87" {
+			t.Errorf("%s: contained in %q", test.name, p.ContainedIn)
+		}
+		if len(p.Location) == 0 {
+			t.Errorf("%s: missing location", test.name)
+		}
+	}
+}
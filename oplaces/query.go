@@ -0,0 +1,137 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+// Package oplaces implements queries that search for and look up places
+// using the Twitter API v1.1.
+//
+// The v2 API reports places only as expansions of tweets. Use oplaces.Lookup
+// to resolve the place ID of a tweet to more complete place data, or
+// oplaces.Search to find a place ID to geo-tag a new tweet (see the PlaceID
+// field of tweets.CreateOpts).
+package oplaces
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/internal/otypes"
+	"github.com/928799934/twitter/jape"
+	"github.com/928799934/twitter/types"
+)
+
+// Lookup constructs a query for the place with the given ID.
+//
+// API: 1.1/geo/id/:place_id.json
+func Lookup(placeID string) Query {
+	return Query{Request: &jape.Request{
+		Method: "1.1/geo/id/" + placeID + ".json", // N.B. parameter in path
+		Params: make(jape.Params),
+	}}
+}
+
+// Search constructs a query for places matching the given options. At least
+// one of the Query, coordinates, or IP address options must be set.
+//
+// API: 1.1/geo/search.json
+func Search(opts *SearchOpts) Query {
+	q := Query{
+		Request: &jape.Request{
+			Method: "1.1/geo/search.json",
+			Params: make(jape.Params),
+		},
+		search: true,
+	}
+	opts.addQueryParams(&q)
+	return q
+}
+
+// Query is a query to search for or look up places.
+type Query struct {
+	*jape.Request
+	search bool
+}
+
+// Invoke executes the query and returns the matching places.
+func (q Query) Invoke(ctx context.Context, cli *twitter.Client) (*Reply, error) {
+	data, err := cli.CallRaw(ctx, q.Request)
+	if err != nil {
+		return nil, err
+	}
+	var places []*otypes.Place
+	if q.search {
+		var rsp struct {
+			Result struct {
+				Places []*otypes.Place `json:"places"`
+			} `json:"result"`
+		}
+		err = json.Unmarshal(data, &rsp)
+		places = rsp.Result.Places
+	} else {
+		places = append(places, new(otypes.Place))
+		err = json.Unmarshal(data, places[0])
+	}
+	if err != nil {
+		return nil, &jape.Error{Message: "decoding response body", Err: err}
+	}
+	out := &Reply{Data: data}
+	for _, p := range places {
+		out.Places = append(out.Places, p.ToPlaceV2())
+	}
+	return out, nil
+}
+
+// A Reply is the response from a Query.
+type Reply struct {
+	Data   []byte
+	Places []*types.Place
+}
+
+// SearchOpts provides parameters for place search. A nil *SearchOpts
+// provides zero values for all fields.
+type SearchOpts struct {
+	// Free-form text to match against place names.
+	Query string
+
+	// If HasCoordinates is true, search near this latitude and longitude.
+	Lat, Long      float64
+	HasCoordinates bool
+
+	// If set, search near the location of this IP address.
+	IP string
+
+	// The minimal granularity of places to return: "poi", "neighborhood",
+	// "city", "admin", or "country". If empty, the server uses "neighborhood".
+	Granularity string
+
+	// A hint for the maximum number of results to return.
+	MaxResults int
+
+	// If set, return only places contained within the place with this ID.
+	ContainedWithin string
+}
+
+func (o *SearchOpts) addQueryParams(q *Query) {
+	if o == nil {
+		return
+	}
+	if o.Query != "" {
+		q.Request.Params.Set("query", o.Query)
+	}
+	if o.HasCoordinates {
+		q.Request.Params.Set("lat", strconv.FormatFloat(o.Lat, 'f', -1, 64))
+		q.Request.Params.Set("long", strconv.FormatFloat(o.Long, 'f', -1, 64))
+	}
+	if o.IP != "" {
+		q.Request.Params.Set("ip", o.IP)
+	}
+	if o.Granularity != "" {
+		q.Request.Params.Set("granularity", o.Granularity)
+	}
+	if o.MaxResults > 0 {
+		q.Request.Params.Set("max_results", strconv.Itoa(o.MaxResults))
+	}
+	if o.ContainedWithin != "" {
+		q.Request.Params.Set("contained_within", o.ContainedWithin)
+	}
+}
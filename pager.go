@@ -0,0 +1,256 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package twitter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/928799934/twitter/jape"
+)
+
+// A Pager is a query whose results are delivered in pages, whose Invoke
+// method returns replies of type R. Invoking a Pager fetches the next page of
+// results, and updates its page token. For example, tweets.Query satisfies
+// Pager[*tweets.Reply], and users.Query satisfies Pager[*users.Reply].
+type Pager[R any] interface {
+	Query[R]
+
+	// HasMorePages reports whether the query has more pages to fetch.
+	HasMorePages() bool
+
+	// PageToken returns the page token the query will send when it is next
+	// invoked, or "" if the query is fresh or has no more pages.
+	PageToken() string
+}
+
+// AllPages invokes p on cli repeatedly until it has no more pages, and calls
+// fn with each reply in order. If invoking p fails, or if fn reports an
+// error, AllPages stops and returns that error. For example:
+//
+//	q := tweets.FromUser(userID, nil)
+//	err := twitter.AllPages[*tweets.Reply](ctx, cli, q, func(rsp *tweets.Reply) error {
+//	   process(rsp.Tweets)
+//	   return nil
+//	})
+//
+// If the rate limit for the query is exhausted while pages remain, AllPages
+// waits for it to reset before fetching the next page (see PaceUntilReset).
+func AllPages[R any](ctx context.Context, cli *Client, p Pager[R], fn func(R) error) error {
+	var last *RateLimit
+	for p.HasMorePages() {
+		if err := PaceUntilReset.wait(ctx, cli, last); err != nil {
+			return err
+		}
+		rsp, err := p.Invoke(ctx, cli)
+		if err != nil {
+			return err
+		}
+		last = rateLimitOf(rsp)
+		if err := fn(rsp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Pacing determines how a paginated traversal spaces its requests, based on
+// the rate limit reported with each page.
+type Pacing int
+
+// Constants for Pacing.
+const (
+	// Fetch pages as fast as possible, without regard to rate limits.
+	NoPacing Pacing = iota
+
+	// Fetch pages as fast as possible until the rate limit is exhausted, then
+	// wait for the rate limit window to reset.
+	PaceUntilReset
+
+	// Spread the requests remaining in the rate limit window evenly over the
+	// rest of the window.
+	PaceEvenly
+)
+
+// delay returns how long to wait at time now before issuing another request
+// under the rate limit rl.
+func (p Pacing) delay(rl *RateLimit, now time.Time) time.Duration {
+	if p == NoPacing || rl == nil || rl.Reset.IsZero() {
+		return 0
+	}
+	left := rl.Reset.Sub(now)
+	if left <= 0 {
+		return 0 // the window has already reset
+	} else if rl.Remaining <= 0 {
+		return left
+	} else if p == PaceEvenly {
+		return left / time.Duration(rl.Remaining)
+	}
+	return 0
+}
+
+// wait blocks until it is time to issue another request under rl, or until
+// ctx ends.
+func (p Pacing) wait(ctx context.Context, cli *Client, rl *RateLimit) error {
+	jc := (*jape.Client)(cli)
+	d := p.delay(rl, jc.Now())
+	if d <= 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-jc.After(d):
+		return nil
+	}
+}
+
+// rateLimitOf returns the rate limit reported with rsp, if rsp is or embeds
+// a *Reply. The reply must not be nil.
+func rateLimitOf(rsp interface{}) *RateLimit {
+	if r, ok := rsp.(interface{ rateLimit() *RateLimit }); ok {
+		return r.rateLimit()
+	}
+	return nil
+}
+
+// An Iterator yields the individual items of type T reported by the pages of
+// a Pager with replies of type R, fetching pages as needed:
+//
+//	it := twitter.NewIterator[*tweets.Reply, *types.Tweet](cli, q,
+//	   func(r *tweets.Reply) []*types.Tweet { return r.Tweets })
+//	for it.Next(ctx) {
+//	   process(it.Item())
+//	}
+//	if err := it.Err(); err != nil {
+//	   log.Fatalf("Iteration failed: %v", err)
+//	}
+//
+// The query packages provide typed wrappers, for example tweets.Query.Iterate.
+//
+// By default, an iterator waits for the rate limit to reset if it is
+// exhausted while pages remain (PaceUntilReset). Use SetPacing to change this.
+type Iterator[R, T any] struct {
+	cli    *Client
+	pager  Pager[R]
+	items  func(R) []T
+	pacing Pacing
+
+	rsp  R          // the reply containing the current item
+	last *RateLimit // the rate limit reported with rsp
+	buf  []T        // the remaining items of rsp
+	cur  T
+	err  error
+}
+
+// NewIterator constructs an iterator over the items of p, using items to
+// extract the items from each reply.
+func NewIterator[R, T any](cli *Client, p Pager[R], items func(R) []T) *Iterator[R, T] {
+	return &Iterator[R, T]{cli: cli, pager: p, items: items, pacing: PaceUntilReset}
+}
+
+// SetPacing sets the pacing policy for fetching subsequent pages, and
+// returns it to permit chaining.
+func (it *Iterator[R, T]) SetPacing(p Pacing) *Iterator[R, T] {
+	it.pacing = p
+	return it
+}
+
+// Next advances the iterator to the next item, fetching another page if
+// necessary, and reports whether an item is available. Next returns false
+// when there are no more items, or if fetching a page fails (see Err).
+func (it *Iterator[R, T]) Next(ctx context.Context) bool {
+	for len(it.buf) == 0 {
+		if it.err != nil || !it.pager.HasMorePages() {
+			return false
+		}
+		if err := it.pacing.wait(ctx, it.cli, it.last); err != nil {
+			it.err = err
+			return false
+		}
+		rsp, err := it.pager.Invoke(ctx, it.cli)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.rsp, it.last, it.buf = rsp, rateLimitOf(rsp), it.items(rsp)
+	}
+	it.cur, it.buf = it.buf[0], it.buf[1:]
+	return true
+}
+
+// Item returns the current item. It is valid only after Next returns true.
+func (it *Iterator[R, T]) Item() T { return it.cur }
+
+// Reply returns the reply for the page containing the current item, for
+// example to look up its expansions.
+func (it *Iterator[R, T]) Reply() R { return it.rsp }
+
+// Err returns the error that ended iteration, or nil if iteration ended
+// because there were no more items.
+func (it *Iterator[R, T]) Err() error { return it.err }
+
+// pageStateVersion is the current version of the encoding used by
+// SavePageState. Increment this if the encoding changes incompatibly.
+const pageStateVersion = 1
+
+type pageState struct {
+	V           int               `json:"v"`
+	Method      string            `json:"method"`
+	PathParams  map[string]string `json:"path_params,omitempty"`
+	HTTPMethod  string            `json:"http_method,omitempty"`
+	Params      jape.Params       `json:"params,omitempty"`
+	Data        []byte            `json:"data,omitempty"`
+	ContentType string            `json:"content_type,omitempty"`
+}
+
+// SavePageState returns an opaque encoding of the state of a paginated query
+// request, including its parameters and current page token, so that the query
+// can be resumed later, possibly in another process, with RestorePageState.
+// For a query type that embeds a *jape.Request, such as tweets.Query:
+//
+//	state, err := twitter.SavePageState(q.Request)
+//	// ...
+//	req, err := twitter.RestorePageState(state)
+//	q := tweets.Query{Request: req}
+//
+// The state does not include the client or its credentials.
+func SavePageState(req *jape.Request) ([]byte, error) {
+	return json.Marshal(pageState{
+		V:           pageStateVersion,
+		Method:      req.Method,
+		PathParams:  req.PathParams,
+		HTTPMethod:  req.HTTPMethod,
+		Params:      req.Params,
+		Data:        req.Data,
+		ContentType: req.ContentType,
+	})
+}
+
+// RestorePageState decodes a query request from state, which must have been
+// produced by SavePageState. Invoking a query with the restored request
+// fetches the page after the last page fetched before the state was saved.
+func RestorePageState(state []byte) (*jape.Request, error) {
+	var ps pageState
+	if err := json.Unmarshal(state, &ps); err != nil {
+		return nil, fmt.Errorf("decoding page state: %w", err)
+	} else if ps.V != pageStateVersion {
+		return nil, fmt.Errorf("unsupported page state version %d", ps.V)
+	} else if ps.Method == "" {
+		return nil, errors.New("invalid page state: missing method")
+	}
+	if ps.Params == nil {
+		ps.Params = make(jape.Params)
+	}
+	return &jape.Request{
+		Method:      ps.Method,
+		PathParams:  ps.PathParams,
+		HTTPMethod:  ps.HTTPMethod,
+		Params:      ps.Params,
+		Data:        ps.Data,
+		ContentType: ps.ContentType,
+	}, nil
+}
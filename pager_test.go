@@ -0,0 +1,265 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package twitter_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/bookmarks"
+	"github.com/928799934/twitter/dms"
+	"github.com/928799934/twitter/jape"
+	"github.com/928799934/twitter/lists"
+	"github.com/928799934/twitter/olists"
+	"github.com/928799934/twitter/spaces"
+	"github.com/928799934/twitter/tweets"
+	"github.com/928799934/twitter/users"
+)
+
+// Verify that the paginated query types satisfy the Pager interface.
+var (
+	_ twitter.Pager[*tweets.Reply]          = tweets.Query{}
+	_ twitter.Pager[*tweets.CountReply]     = tweets.CountQuery{}
+	_ twitter.Pager[*users.Reply]           = users.Query{}
+	_ twitter.Pager[*lists.Reply]           = lists.Query{}
+	_ twitter.Pager[*olists.Reply]          = olists.Query{}
+	_ twitter.Pager[*bookmarks.Reply]       = bookmarks.Query{}
+	_ twitter.Pager[*spaces.Reply]          = spaces.Query{}
+	_ twitter.Pager[*dms.Reply]             = dms.Query{}
+	_ twitter.Pager[*bookmarks.FolderReply] = bookmarks.FolderQuery{}
+)
+
+// newPagesClient returns a client for a server that reports three pages of
+// tweets, plus a page with an empty page of results and a broken page token.
+func newPagesClient(t *testing.T) *twitter.Client {
+	pages := map[string]string{
+		"":   `{"data":[{"id":"1","text":"a"}],"meta":{"result_count":1,"next_token":"p2"}}`,
+		"p2": `{"data":[{"id":"2","text":"b"}],"meta":{"result_count":1,"next_token":"p3"}}`,
+		"p3": `{"data":[],"meta":{"result_count":0,"next_token":"p4"}}`,
+		"p4": `{"data":[{"id":"3","text":"c"}],"meta":{"result_count":1,"previous_token":"p2"}}`,
+		"x1": `{"data":[{"id":"4","text":"d"}],"meta":{"result_count":1,"next_token":"bogus"}}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.Query().Get("pagination_token")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(page))
+	}))
+	t.Cleanup(srv.Close)
+	return twitter.NewClient(&jape.Client{BaseURL: srv.URL})
+}
+
+func TestAllPages(t *testing.T) {
+	cli := newPagesClient(t)
+	q := tweets.FromUser("12345", nil)
+	var got, tokens []string
+	err := twitter.AllPages[*tweets.Reply](context.Background(), cli, q, func(rsp *tweets.Reply) error {
+		for _, tw := range rsp.Tweets {
+			got = append(got, tw.ID)
+		}
+		tokens = append(tokens, q.PageToken())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("AllPages failed: %v", err)
+	}
+	if want := "1 2 3"; joinStrings(got) != want {
+		t.Errorf("Tweets: got %q, want %q", got, want)
+	}
+	if want := "p2 p3 p4 "; joinStrings(tokens) != want {
+		t.Errorf("Page tokens: got %q, want %q", tokens, want)
+	}
+	if q.HasMorePages() {
+		t.Error("HasMorePages is true after the last page")
+	}
+}
+
+func TestInvokeAll(t *testing.T) {
+	cli := newPagesClient(t)
+	ctx := context.Background()
+	tests := []struct {
+		max       int
+		want      string
+		nextToken string
+	}{
+		{0, "1 2 3", ""},
+		{2, "1 2", "p3"},
+		{1, "1", "p2"},
+	}
+	for _, test := range tests {
+		q := tweets.FromUser("12345", nil)
+		rsp, err := q.InvokeAll(ctx, cli, test.max)
+		if err != nil {
+			t.Fatalf("InvokeAll(%d) failed: %v", test.max, err)
+		}
+		var got []string
+		for _, tw := range rsp.Tweets {
+			got = append(got, tw.ID)
+		}
+		if joinStrings(got) != test.want {
+			t.Errorf("InvokeAll(%d): got %q, want %q", test.max, got, test.want)
+		}
+		if rsp.Meta.ResultCount != len(got) || rsp.Meta.NextToken != test.nextToken {
+			t.Errorf("InvokeAll(%d) meta: got %+v, want %d results, next token %q",
+				test.max, rsp.Meta, len(got), test.nextToken)
+		}
+		var data []json.RawMessage
+		if err := json.Unmarshal(rsp.Data, &data); err != nil || len(data) != len(got) {
+			t.Errorf("InvokeAll(%d) data: got %s (%v), want %d items", test.max, rsp.Data, err, len(got))
+		}
+		if more := test.nextToken != ""; q.HasMorePages() != more {
+			t.Errorf("InvokeAll(%d): HasMorePages is %v, want %v", test.max, !more, more)
+		}
+	}
+}
+
+func TestPreviousPage(t *testing.T) {
+	cli := newPagesClient(t)
+	ctx := context.Background()
+	q := tweets.FromUser("12345", &tweets.ListOpts{PageToken: "p4"})
+	rsp, err := q.Invoke(ctx, cli)
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	if got := rsp.Meta.PreviousToken; got != "p2" {
+		t.Fatalf("Previous token: got %q, want p2", got)
+	}
+	q.SetPageToken(rsp.Meta.PreviousToken)
+	prev, err := q.Invoke(ctx, cli)
+	if err != nil {
+		t.Fatalf("Invoke previous failed: %v", err)
+	}
+	if len(prev.Tweets) != 1 || prev.Tweets[0].ID != "2" {
+		t.Errorf("Previous page: got %+v, want tweet 2", prev.Tweets)
+	}
+
+	q.SetPageToken("")
+	if q.PageToken() != "" || !q.HasMorePages() {
+		t.Error("SetPageToken(\"\") did not reset the query")
+	}
+}
+
+func TestIterator(t *testing.T) {
+	cli := newPagesClient(t)
+	ctx := context.Background()
+
+	it := tweets.FromUser("12345", nil).Iterate(cli)
+	var got []string
+	for it.Next(ctx) {
+		got = append(got, it.Tweet().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Errorf("Iteration failed: %v", err)
+	}
+	if want := "1 2 3"; joinStrings(got) != want {
+		t.Errorf("Tweets: got %q, want %q", got, want)
+	}
+
+	// Iteration stops at an error, after the items already fetched.
+	it = tweets.FromUser("12345", &tweets.ListOpts{PageToken: "x1"}).Iterate(cli)
+	got = nil
+	for it.Next(ctx) {
+		got = append(got, it.Tweet().ID)
+	}
+	if it.Err() == nil {
+		t.Error("Iteration: got nil error for a bad page token")
+	}
+	if want := "4"; joinStrings(got) != want {
+		t.Errorf("Tweets: got %q, want %q", got, want)
+	}
+}
+
+func TestPageState(t *testing.T) {
+	cli := newPagesClient(t)
+	ctx := context.Background()
+
+	// Fetch the first page, then save the state of the query.
+	q := tweets.FromUser("12345", nil)
+	if _, err := q.Invoke(ctx, cli); err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	state, err := twitter.SavePageState(q.Request)
+	if err != nil {
+		t.Fatalf("SavePageState failed: %v", err)
+	}
+
+	// Restore the query and fetch the remaining pages.
+	req, err := twitter.RestorePageState(state)
+	if err != nil {
+		t.Fatalf("RestorePageState failed: %v", err)
+	}
+	var got []string
+	it := tweets.Query{Request: req}.Iterate(cli)
+	for it.Next(ctx) {
+		got = append(got, it.Tweet().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Errorf("Iteration failed: %v", err)
+	}
+	if want := "2 3"; joinStrings(got) != want {
+		t.Errorf("Resumed tweets: got %q, want %q", got, want)
+	}
+
+	for _, bad := range []string{``, `{}`, `{"v":99,"method":"x"}`, `{"v":1}`} {
+		if _, err := twitter.RestorePageState([]byte(bad)); err == nil {
+			t.Errorf("RestorePageState(%q): got nil error", bad)
+		}
+	}
+}
+
+func TestPacing(t *testing.T) {
+	clock := newFakeClock()
+	reset := clock.Now().Add(time.Minute)
+
+	// Each page token maps to the remaining requests reported with the page,
+	// and the token of the next page.
+	pages := map[string][2]string{
+		"a": {"2", "b"}, "b": {"1", "c"}, "c": {"0", ""},
+		"x": {"0", "c"},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := pages[r.URL.Query().Get("pagination_token")]
+		remaining, next := page[0], page[1]
+		w.Header().Set("x-rate-limit-limit", "3")
+		w.Header().Set("x-rate-limit-remaining", remaining)
+		w.Header().Set("x-rate-limit-reset", strconv.FormatInt(reset.Unix(), 10))
+		w.Write([]byte(`{"data":[{"id":"` + remaining + `"}],"meta":{"result_count":1,"next_token":"` + next + `"}}`))
+	}))
+	defer srv.Close()
+	cli := twitter.NewClient(&jape.Client{BaseURL: srv.URL, Clock: clock})
+
+	tests := []struct {
+		start  string
+		pacing twitter.Pacing
+		want   time.Duration
+	}{
+		{"a", twitter.NoPacing, 0},
+		{"a", twitter.PaceUntilReset, 0},
+		{"a", twitter.PaceEvenly, time.Minute}, // 30s, then 30s
+		{"c", twitter.PaceEvenly, 0},           // no further pages
+		{"x", twitter.NoPacing, 0},
+		{"x", twitter.PaceUntilReset, time.Minute},
+	}
+	for _, test := range tests {
+		before := clock.Now()
+		reset = before.Add(time.Minute)
+		it := tweets.FromUser("1", &tweets.ListOpts{PageToken: test.start}).Iterate(cli)
+		it.SetPacing(test.pacing)
+		for it.Next(context.Background()) {
+		}
+		if err := it.Err(); err != nil {
+			t.Errorf("Iterate (pacing %d): %v", test.pacing, err)
+		}
+		if got := clock.Now().Sub(before); got != test.want {
+			t.Errorf("Iterate from %s (pacing %d): waited %v, want %v", test.start, test.pacing, got, test.want)
+		}
+	}
+}
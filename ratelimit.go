@@ -0,0 +1,57 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package twitter
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/928799934/twitter/jape"
+)
+
+// RateLimitStatus reports the current rate limits of the caller for the
+// endpoints in the given resource families (for example, "statuses" or
+// "users"), or for all families if none are given. The result maps each
+// endpoint path, such as "/statuses/show/:id", to its limit.
+//
+// This is useful to learn the remaining budget for many endpoints at once,
+// for example at startup, without spending requests on those endpoints. The
+// limits reported are those of the authorization context of c.
+//
+// API: 1.1/application/rate_limit_status.json
+func (c *Client) RateLimitStatus(ctx context.Context, resources ...string) (map[string]*RateLimit, error) {
+	req := &jape.Request{
+		Method: "1.1/application/rate_limit_status.json",
+		Params: make(jape.Params),
+	}
+	if len(resources) != 0 {
+		req.Params.Set("resources", strings.Join(resources, ","))
+	}
+	data, err := c.CallRaw(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	var rsp struct {
+		Resources map[string]map[string]struct {
+			Limit     int   `json:"limit"`
+			Remaining int   `json:"remaining"`
+			Reset     int64 `json:"reset"` // seconds since the epoch
+		} `json:"resources"`
+	}
+	if err := json.Unmarshal(data, &rsp); err != nil {
+		return nil, &jape.Error{Data: data, Message: "decoding rate limit status", Err: err}
+	}
+	out := make(map[string]*RateLimit)
+	for _, family := range rsp.Resources {
+		for path, rl := range family {
+			out[path] = &RateLimit{
+				Ceiling:   rl.Limit,
+				Remaining: rl.Remaining,
+				Reset:     time.Unix(rl.Reset, 0),
+			}
+		}
+	}
+	return out, nil
+}
@@ -25,24 +25,108 @@ type Reply struct {
 	// Server metadata reported with search replies.
 	Meta json.RawMessage `json:"meta,omitempty"`
 
-	// Error details reported with lookup or search replies.
+	// Error details reported with lookup or search replies.  When a lookup
+	// requests multiple IDs, the server reports the IDs that could not be
+	// found or accessed (e.g., deleted tweets, suspended users) here, while
+	// still returning data for the rest. See also ErrorsByID.
 	Errors []*types.ErrorDetail `json:"errors,omitempty"`
 
+	// For a reply delivered by a filtered stream, the rules that matched the
+	// tweet in the data field.
+	MatchingRules []*types.MatchingRule `json:"matching_rules,omitempty"`
+
+	// For a reply delivered by a stream, the complete message as received
+	// from the server. This is populated by the client.
+	Raw json.RawMessage `json:"-"`
+
+	// For a reply delivered by a stream, a system message from the server
+	// reporting a condition of the stream rather than data, such as an
+	// operational disconnect. When this is set, the reply has no data.
+	// This is populated by the client.
+	System *Error `json:"-"`
+
 	// Rate limit metadata reported by the server. If the server did not return
 	// these data, this field will be nil.
 	RateLimit *RateLimit `json:"-"`
+
+	// Size and timing annotations for the call that produced this reply.
+	// This is populated by the client, and is nil for a reply constructed or
+	// decoded by other means.
+	Info *CallInfo `json:"-"`
+}
+
+// ErrorsByID returns a map from resource IDs to the error details reported for
+// them in r. Errors that do not identify a specific resource are omitted.  It
+// returns nil if r has no such errors.
+func (r *Reply) ErrorsByID() map[string]*types.ErrorDetail {
+	var out map[string]*types.ErrorDetail
+	for _, e := range r.Errors {
+		id := e.ResourceID
+		if id == "" {
+			id = e.Value
+		}
+		if id == "" {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]*types.ErrorDetail)
+		}
+		out[id] = e
+	}
+	return out
+}
+
+// Includes records the decoded objects included in a reply as a result of
+// expansions. Each field is nil if there are no inclusions of that type.
+type Includes struct {
+	Tweets types.Tweets `json:"tweets,omitempty"`
+	Users  types.Users  `json:"users,omitempty"`
+	Media  types.Medias `json:"media,omitempty"`
+	Polls  types.Polls  `json:"polls,omitempty"`
+	Places types.Places `json:"places,omitempty"`
+}
+
+// Included decodes all the objects in the includes of r. Each call decodes
+// the includes afresh, so the caller may modify the result. To avoid decoding
+// the same includes repeatedly, call Included once and pass the result.
+func (r *Reply) Included() (*Includes, error) {
+	inc := new(Includes)
+	for _, v := range []struct {
+		key string
+		out interface{}
+	}{
+		{"tweets", &inc.Tweets},
+		{"users", &inc.Users},
+		{"media", &inc.Media},
+		{"polls", &inc.Polls},
+		{"places", &inc.Places},
+	} {
+		if err := r.decodeIncluded(v.key, v.out); err != nil {
+			return nil, err
+		}
+	}
+	return inc, nil
+}
+
+// decodeIncluded decodes the includes of r for the given key into out.
+// It leaves out unmodified if there are no inclusions for key.
+func (r *Reply) decodeIncluded(key string, out interface{}) error {
+	data, ok := r.Includes[key]
+	if !ok || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return &jape.Error{Data: data, Message: "decoding " + key, Err: err}
+	}
+	return nil
 }
 
 // IncludedMedia decodes any media objects in the includes of r.
 // It returns nil without error if there are no media inclusions.
 func (r *Reply) IncludedMedia() (types.Medias, error) {
-	media, ok := r.Includes["media"]
-	if !ok || len(media) == 0 {
-		return nil, nil
-	}
 	var out types.Medias
-	if err := json.Unmarshal(media, &out); err != nil {
-		return nil, &jape.Error{Data: media, Message: "decoding media", Err: err}
+	if err := r.decodeIncluded("media", &out); err != nil {
+		return nil, err
 	}
 	return out, nil
 }
@@ -50,13 +134,9 @@ func (r *Reply) IncludedMedia() (types.Medias, error) {
 // IncludedTweets decodes any tweet objects in the includes of r.
 // It returns nil without error if there are no tweet inclusions.
 func (r *Reply) IncludedTweets() (types.Tweets, error) {
-	tweets, ok := r.Includes["tweets"]
-	if !ok || len(tweets) == 0 {
-		return nil, nil
-	}
 	var out types.Tweets
-	if err := json.Unmarshal(tweets, &out); err != nil {
-		return nil, &jape.Error{Data: tweets, Message: "decoding tweets", Err: err}
+	if err := r.decodeIncluded("tweets", &out); err != nil {
+		return nil, err
 	}
 	return out, nil
 }
@@ -64,13 +144,9 @@ func (r *Reply) IncludedTweets() (types.Tweets, error) {
 // IncludedUsers decodes any user objects in the includes of r.
 // It returns nil without error if there are no user inclusions.
 func (r *Reply) IncludedUsers() (types.Users, error) {
-	users, ok := r.Includes["users"]
-	if !ok || len(users) == 0 {
-		return nil, nil
-	}
 	var out types.Users
-	if err := json.Unmarshal(users, &out); err != nil {
-		return nil, &jape.Error{Data: users, Message: "decoding users", Err: err}
+	if err := r.decodeIncluded("users", &out); err != nil {
+		return nil, err
 	}
 	return out, nil
 }
@@ -78,13 +154,9 @@ func (r *Reply) IncludedUsers() (types.Users, error) {
 // IncludedPolls decodes any poll objects in the includes of r.
 // It returns nil without error if there are no poll inclusions.
 func (r *Reply) IncludedPolls() (types.Polls, error) {
-	polls, ok := r.Includes["polls"]
-	if !ok || len(polls) == 0 {
-		return nil, nil
-	}
 	var out types.Polls
-	if err := json.Unmarshal(polls, &out); err != nil {
-		return nil, &jape.Error{Data: polls, Message: "decoding polls", Err: err}
+	if err := r.decodeIncluded("polls", &out); err != nil {
+		return nil, err
 	}
 	return out, nil
 }
@@ -92,17 +164,66 @@ func (r *Reply) IncludedPolls() (types.Polls, error) {
 // IncludedPlaces decodes any place objects in the includes of r.
 // It returns nil without error if there are no place inclusions.
 func (r *Reply) IncludedPlaces() (types.Places, error) {
-	places, ok := r.Includes["places"]
-	if !ok || len(places) == 0 {
+	var out types.Places
+	if err := r.decodeIncluded("places", &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AttachedMedia returns the media attached to tweet t, in the order they are
+// attached, resolved against the media included in r. Media keys that are not
+// included in r are skipped. To populate the includes, the query must request
+// the "attachments.media_keys" expansion.
+func (r *Reply) AttachedMedia(t *types.Tweet) (types.Medias, error) {
+	keys := t.MediaKeys()
+	if len(keys) == 0 {
 		return nil, nil
 	}
-	var out types.Places
-	if err := json.Unmarshal(places, &out); err != nil {
-		return nil, &jape.Error{Data: places, Message: "decoding places", Err: err}
+	media, err := r.IncludedMedia()
+	if err != nil {
+		return nil, err
+	}
+	var out types.Medias
+	for _, key := range keys {
+		if m := media.FindByKey(key); m != nil {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+// AttachedPolls returns the polls attached to tweet t, resolved against the
+// polls included in r. Poll IDs that are not included in r are skipped. To
+// populate the includes, the query must request the "attachments.poll_ids"
+// expansion.
+func (r *Reply) AttachedPolls(t *types.Tweet) (types.Polls, error) {
+	ids := t.PollIDs()
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	polls, err := r.IncludedPolls()
+	if err != nil {
+		return nil, err
+	}
+	var out types.Polls
+	for _, id := range ids {
+		if p := polls.FindByID(id); p != nil {
+			out = append(out, p)
+		}
 	}
 	return out, nil
 }
 
+// rateLimit returns the rate limit of r. Types that embed a *Reply inherit
+// this method, which allows generic code to find their rate limits.
+func (r *Reply) rateLimit() *RateLimit {
+	if r == nil {
+		return nil
+	}
+	return r.RateLimit
+}
+
 // RateLimit records metadata about API rate limits reported by the server.
 type RateLimit struct {
 	Ceiling   int       // rate limit ceiling for this endpoint
@@ -130,8 +251,38 @@ func decodeRateLimits(h http.Header) *RateLimit {
 	return out
 }
 
+// CallInfo records size and timing annotations for a single API call.  For
+// replies delivered by a stream, only Bytes and Decode are set.
+//
+// These measurements apply to one call; to aggregate them across calls, see
+// the Metrics field of jape.Client.
+type CallInfo struct {
+	Bytes      int           // size of the response body in bytes
+	Latency    time.Duration // time from request to receipt of the response body
+	Decode     time.Duration // time spent decoding the reply envelope
+	ServerTime time.Duration // server-reported processing time, 0 if unknown
+}
+
+// decodeResponseTime returns the processing time reported by the server in
+// the x-response-time header, which is given in milliseconds.
+func decodeResponseTime(h http.Header) time.Duration {
+	if v, err := strconv.ParseInt(h.Get("x-response-time"), 10, 64); err == nil && v > 0 {
+		return time.Duration(v) * time.Millisecond
+	}
+	return 0
+}
+
 // Pagination records metadata about pagination of results.
 type Pagination struct {
 	ResultCount int    `json:"result_count"`
 	NextToken   string `json:"next_token"`
+
+	// For timelines, a token for the page preceding this one, if any.
+	// See the SetPageToken method of tweets.Query.
+	PreviousToken string `json:"previous_token,omitempty"`
+
+	// For tweet searches and timelines, the IDs of the newest and oldest
+	// tweets in the page.
+	NewestID string `json:"newest_id,omitempty"`
+	OldestID string `json:"oldest_id,omitempty"`
 }
@@ -0,0 +1,77 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package twitter_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/types"
+)
+
+func TestIncluded(t *testing.T) {
+	rsp := &twitter.Reply{
+		Includes: map[string]json.RawMessage{
+			"users":  json.RawMessage(`[{"id":"u1","username":"alice"}]`),
+			"tweets": json.RawMessage(`[{"id":"t1","text":"hi"},{"id":"t2","text":"bye"}]`),
+			"places": json.RawMessage(`[{"id":"p1","full_name":"Somewhere"}]`),
+		},
+	}
+	inc, err := rsp.Included()
+	if err != nil {
+		t.Fatalf("Included failed: %v", err)
+	}
+	if len(inc.Users) != 1 || len(inc.Tweets) != 2 || len(inc.Places) != 1 {
+		t.Errorf("Included: got %d users, %d tweets, %d places; want 1, 2, 1",
+			len(inc.Users), len(inc.Tweets), len(inc.Places))
+	}
+	if inc.Media != nil || inc.Polls != nil {
+		t.Errorf("Included: got media %v, polls %v; want nil", inc.Media, inc.Polls)
+	}
+
+	// Each call decodes a separate value, so callers do not share state.
+	inc.Users[0].Username = "changed"
+	if users, _ := rsp.IncludedUsers(); users.FindByUsername("alice") == nil {
+		t.Error("IncludedUsers: modifying an earlier result changed the reply")
+	}
+
+	bad := &twitter.Reply{Includes: map[string]json.RawMessage{"polls": json.RawMessage(`{}`)}}
+	if _, err := bad.IncludedPolls(); err == nil {
+		t.Error("IncludedPolls: got nil error for invalid data")
+	}
+}
+
+func TestAttached(t *testing.T) {
+	rsp := &twitter.Reply{
+		Includes: map[string]json.RawMessage{
+			"media": json.RawMessage(`[{"media_key":"3_1","type":"photo"},{"media_key":"7_2","type":"video"}]`),
+			"polls": json.RawMessage(`[{"id":"p1","voting_status":"open"}]`),
+		},
+	}
+	var tweet types.Tweet
+	const input = `{"id":"1","text":"look","attachments":{"media_keys":["7_2","9_9","3_1"],"poll_ids":["p1"]}}`
+	if err := json.Unmarshal([]byte(input), &tweet); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	media, err := rsp.AttachedMedia(&tweet)
+	if err != nil {
+		t.Fatalf("AttachedMedia failed: %v", err)
+	}
+	if len(media) != 2 || media[0].Key != "7_2" || media[1].Key != "3_1" {
+		t.Errorf("AttachedMedia: got %+v, want keys 7_2, 3_1", media)
+	}
+	polls, err := rsp.AttachedPolls(&tweet)
+	if err != nil {
+		t.Fatalf("AttachedPolls failed: %v", err)
+	}
+	if len(polls) != 1 || polls[0].ID != "p1" {
+		t.Errorf("AttachedPolls: got %+v, want p1", polls)
+	}
+
+	plain := &types.Tweet{ID: "2", Text: "nothing"}
+	if media, err := rsp.AttachedMedia(plain); media != nil || err != nil {
+		t.Errorf("AttachedMedia: got %v, %v; want nil, nil", media, err)
+	}
+}
@@ -42,11 +42,18 @@
 //
 // The response will include the updated rules, along with server metadata
 // indicating the effective time of application and summary statistics.
+//
+// To replace the rules on the server with a desired set, use Sync, which
+// computes and applies the necessary additions and deletions:
+//
+//	current, err := rules.Sync(ctx, cli, adds)
 package rules
 
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/928799934/twitter"
@@ -115,7 +122,9 @@ func (q Query) Invoke(ctx context.Context, cli *twitter.Client) (*Reply, error)
 	} else if err := json.Unmarshal(rsp.Data, &out.Rules); err != nil {
 		return nil, &jape.Error{Data: rsp.Data, Message: "decoding rules data", Err: err}
 	}
-	if err := json.Unmarshal(rsp.Meta, &out.Meta); err != nil {
+	if len(rsp.Meta) == 0 {
+		// no metadata, e.g., in dry-run mode
+	} else if err := json.Unmarshal(rsp.Meta, &out.Meta); err != nil {
 		return nil, &jape.Error{Data: rsp.Meta, Message: "decoding rules metadata", Err: err}
 	}
 	return out, nil
@@ -185,3 +194,93 @@ func (ds Deletes) encode() ([]byte, error) {
 		D: del{I: []string(ds)},
 	})
 }
+
+// SearchQuery returns a search query that matches any tweet matched by at
+// least one of the given rules, suitable for a recent search. Note that the
+// search API limits the length of a query, which a large rule set may exceed.
+func SearchQuery(rs []Rule) string {
+	if len(rs) == 1 {
+		return rs[0].Value
+	}
+	parts := make([]string, len(rs))
+	for i, r := range rs {
+		parts[i] = "(" + r.Value + ")"
+	}
+	return strings.Join(parts, " OR ")
+}
+
+// Sync updates the streaming search rules on the server to match want. Rules
+// in want that do not appear on the server are added, and then rules on the
+// server that do not appear in want are deleted. Rules are compared by query
+// and tag. Sync returns the resulting rules, with their IDs.
+//
+// Additions are applied before deletions, so that a failure does not leave the
+// stream with fewer rules than before. If the additions fail, no rules are
+// deleted. If the deletions fail after the additions succeed, Sync returns the
+// rules now on the server along with an error of type *SyncError.
+//
+// The server rejects a rule whose query duplicates an existing rule, so to
+// change only the tag of a rule, delete the old rule before calling Sync.
+//
+// API: GET and POST 2/tweets/search/stream/rules
+func Sync(ctx context.Context, cli *twitter.Client, want Adds) ([]Rule, error) {
+	cur, err := Get().Invoke(ctx, cli)
+	if err != nil {
+		return nil, err
+	}
+	type key struct{ query, tag string }
+	wanted := make(map[key]bool)
+	for _, a := range want {
+		wanted[key{a.Query, a.Tag}] = true
+	}
+
+	var out, stale []Rule
+	var dels Deletes
+	for _, r := range cur.Rules {
+		k := key{r.Value, r.Tag}
+		if wanted[k] {
+			out = append(out, r)
+			delete(wanted, k) // already present
+		} else {
+			stale = append(stale, r)
+			dels = append(dels, r.ID)
+		}
+	}
+	var adds Adds
+	for _, a := range want {
+		if k := (key{a.Query, a.Tag}); wanted[k] {
+			adds = append(adds, a)
+			delete(wanted, k) // in case of duplicates
+		}
+	}
+
+	if len(adds) != 0 {
+		rsp, err := Update(adds).Invoke(ctx, cli)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rsp.Rules...)
+	}
+	if len(dels) != 0 {
+		if _, err := Update(dels).Invoke(ctx, cli); err != nil {
+			return append(out, stale...), &SyncError{Added: len(adds), Stale: dels, Err: err}
+		}
+	}
+	return out, nil
+}
+
+// A SyncError reports that Sync applied only part of an update: the new rules
+// were added, but the stale rules could not be deleted.
+type SyncError struct {
+	Added int     // the number of rules added
+	Stale Deletes // the IDs of the rules that were not deleted
+	Err   error   // the error from the deletion
+}
+
+// Error satisfies the error interface.
+func (e *SyncError) Error() string {
+	return fmt.Sprintf("added %d rules, but deleting %d stale rules failed: %v", e.Added, len(e.Stale), e.Err)
+}
+
+// Unwrap returns the underlying error from the deletion.
+func (e *SyncError) Unwrap() error { return e.Err }
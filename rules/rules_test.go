@@ -0,0 +1,46 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package rules_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/928799934/twitter/internal/otest"
+	"github.com/928799934/twitter/rules"
+)
+
+func TestSyncDeleteFailure(t *testing.T) {
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			io.WriteString(w, `{"data":[{"id":"1","value":"dogs"}],"meta":{"sent":"2022-03-14T12:00:00.000Z"}}`)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), `"delete"`) {
+			http.Error(w, `{"title":"Service Unavailable"}`, http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, `{"data":[{"id":"2","value":"cats"}],"meta":{"sent":"2022-03-14T12:00:00.000Z"}}`)
+	}))
+
+	got, err := rules.Sync(context.Background(), cli, rules.Adds{{Query: "cats"}})
+	var serr *rules.SyncError
+	if !errors.As(err, &serr) {
+		t.Fatalf("Sync: got error %v, want *rules.SyncError", err)
+	}
+	if serr.Added != 1 || len(serr.Stale) != 1 || serr.Stale[0] != "1" {
+		t.Errorf("SyncError: got %+v, want 1 added and stale [1]", serr)
+	}
+	var ids []string
+	for _, r := range got {
+		ids = append(ids, r.ID)
+	}
+	if want := "2 1"; strings.Join(ids, " ") != want {
+		t.Errorf("Sync rules: got %q, want %q", ids, want)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package twitter
+
+import (
+	"io"
+	"sync"
+)
+
+// A LineSink is a stream callback that writes each message received from a
+// stream as a single line of JSON (the "JSON Lines" format) to a writer, for
+// example to archive a stream to disk:
+//
+//	sink := &twitter.LineSink{W: f}
+//	err := cli.Stream(ctx, tweets.SearchStream(nil, opts).Request, sink.Write)
+//
+// The messages are written exactly as received from the server, so that they
+// can later be decoded as Reply values. A LineSink is safe for concurrent use.
+type LineSink struct {
+	// The writer to which messages are written. This field must be set,
+	// unless Rotate supplies a writer for the first message.
+	W io.Writer
+
+	// If set, this function is called before each message is written, with
+	// the number of lines and bytes written to the current writer. If it
+	// returns a non-nil writer, that writer replaces W, and the counts are
+	// reset. This can be used to rotate output files by size or by time.
+	// Rotate is responsible for closing the previous writer, if necessary.
+	// If Rotate reports an error, the error is returned to the stream.
+	Rotate func(lines, bytes int64) (io.Writer, error)
+
+	mu           sync.Mutex
+	lines, bytes int64
+}
+
+// Write writes the raw message of rsp as a line to the sink. It satisfies
+// the Callback type. Replies without a raw message, such as those not
+// delivered by a stream, are ignored.
+func (s *LineSink) Write(rsp *Reply) error {
+	if len(rsp.Raw) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Rotate != nil {
+		w, err := s.Rotate(s.lines, s.bytes)
+		if err != nil {
+			return err
+		} else if w != nil {
+			s.W, s.lines, s.bytes = w, 0, 0
+		}
+	}
+	buf := make([]byte, len(rsp.Raw)+1)
+	copy(buf, rsp.Raw)
+	buf[len(rsp.Raw)] = '\n'
+	nw, err := s.W.Write(buf)
+	s.bytes += int64(nw)
+	if err != nil {
+		return err
+	}
+	s.lines++
+	return nil
+}
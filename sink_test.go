@@ -0,0 +1,55 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package twitter_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/jape"
+)
+
+func TestLineSink(t *testing.T) {
+	msgs := []string{
+		`{"data":{"id":"1","text":"one"}}`,
+		`{"data":{"id":"2","text":"two"},"matching_rules":[{"id":"5"}]}`,
+		`{"data":{"id":"3","text":"three"}}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, msg := range msgs {
+			io.WriteString(w, msg+"\r\n\r\n")
+		}
+	}))
+	defer srv.Close()
+
+	// Rotate to a new buffer after every two lines.
+	var files []*bytes.Buffer
+	sink := &twitter.LineSink{
+		Rotate: func(lines, _ int64) (io.Writer, error) {
+			if len(files) == 0 || lines == 2 {
+				files = append(files, new(bytes.Buffer))
+				return files[len(files)-1], nil
+			}
+			return nil, nil
+		},
+	}
+	cli := twitter.NewClient(&jape.Client{BaseURL: srv.URL})
+	if err := cli.Stream(context.Background(), &jape.Request{Method: "stream"}, sink.Write); err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+
+	if len(files) != 2 {
+		t.Fatalf("Got %d files, want 2", len(files))
+	}
+	if got, want := files[0].String(), msgs[0]+"\n"+msgs[1]+"\n"; got != want {
+		t.Errorf("File 1: got %q, want %q", got, want)
+	}
+	if got, want := files[1].String(), msgs[2]+"\n"; got != want {
+		t.Errorf("File 2: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,308 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+// Package spaces supports queries for Spaces, Twitter's live audio
+// conversations.
+//
+// To look up one or more Spaces by ID, use spaces.Lookup. Additional IDs can
+// be given in the options:
+//
+//	single := spaces.Lookup("1DXxyRYNejbKM", nil)
+//	multi := spaces.Lookup("1DXxyRYNejbKM", &spaces.LookupOpts{
+//	   More: []string{"1nAJELYEEPvGL"},
+//	})
+//
+// By default only the default fields are returned (see types.Space).  To
+// request additional fields or expansions, include them in the options:
+//
+//	q := spaces.Lookup(id, &spaces.LookupOpts{
+//	   Optional: []types.Fields{
+//	      types.SpaceFields{HostIDs: true, ParticipantCount: true},
+//	      types.Expansions{HostIDs: true},
+//	   },
+//	})
+//
+// To search for Spaces by title, use spaces.Search. To find the Spaces
+// created by particular users, use spaces.ByCreator.
+package spaces
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/jape"
+	"github.com/928799934/twitter/tweets"
+	"github.com/928799934/twitter/types"
+	"github.com/928799934/twitter/users"
+)
+
+// Lookup constructs a lookup query for one or more Space IDs.  To look up
+// multiple IDs, add subsequent values to the opts.More field.
+//
+// The API accepts at most twitter.MaxLookupIDs IDs per request. If more IDs
+// are given, the query issues multiple requests and merges their results
+// into a single reply (see twitter.Client.CallChunked).
+//
+// API: 2/spaces/:id or 2/spaces
+func Lookup(id string, opts *LookupOpts) Query {
+	if opts == nil || len(opts.More) == 0 {
+		req := &jape.Request{
+			Method: "2/spaces/" + id,
+			Params: make(jape.Params),
+		}
+		opts.addRequestParams(req)
+		return Query{Request: req}
+	}
+	req := &jape.Request{
+		Method: "2/spaces",
+		Params: make(jape.Params),
+	}
+	req.Params.Add("ids", id)
+	req.Params.Add("ids", opts.More...)
+	opts.addRequestParams(req)
+	return Query{Request: req, chunkParam: "ids", concurrency: opts.Concurrency}
+}
+
+// ByCreator constructs a query for the live and scheduled Spaces created by
+// one or more users. To look up multiple user IDs, add subsequent values to
+// the opts.More field. The reply metadata reports the result count.
+//
+// API: 2/spaces/by/creator_ids
+func ByCreator(userID string, opts *LookupOpts) Query {
+	req := &jape.Request{
+		Method: "2/spaces/by/creator_ids",
+		Params: make(jape.Params),
+	}
+	req.Params.Add("user_ids", userID)
+	q := Query{Request: req, chunkParam: "user_ids"}
+	if opts != nil {
+		req.Params.Add("user_ids", opts.More...)
+		q.concurrency = opts.Concurrency
+	}
+	opts.addRequestParams(req)
+	return q
+}
+
+// Buyers constructs a query to list the users who purchased a ticket to a
+// ticketed Space. Note that the query reply contains user data, not Spaces.
+// This query requires user-context authorization as the creator of the Space.
+//
+// API: 2/spaces/:id/buyers
+func Buyers(spaceID string, opts *ListOpts) users.Query {
+	req := &jape.Request{
+		Method: "2/spaces/" + spaceID + "/buyers",
+		Params: make(jape.Params),
+	}
+	opts.addRequestParams(req)
+	return users.Query{Request: req}
+}
+
+// Tweets constructs a query for the tweets shared within a Space. Note that
+// the query reply contains tweets, not Spaces.
+//
+// API: 2/spaces/:id/tweets
+func Tweets(spaceID string, opts *ListOpts) tweets.Query {
+	req := &jape.Request{
+		Method: "2/spaces/" + spaceID + "/tweets",
+		Params: make(jape.Params),
+	}
+	opts.addRequestParams(req)
+	return tweets.Query{Request: req}
+}
+
+// Search constructs a query for Spaces whose title matches the given search
+// terms. The results are paginated.
+//
+//	q := spaces.Search("music", &spaces.SearchOpts{State: "live"})
+//
+// API: 2/spaces/search
+func Search(query string, opts *SearchOpts) Query {
+	req := &jape.Request{
+		Method: "2/spaces/search",
+		Params: make(jape.Params),
+	}
+	req.Params.Set("query", query)
+	opts.addRequestParams(req)
+	return Query{Request: req}
+}
+
+// A Query performs a lookup or search query for Spaces.
+type Query struct {
+	*jape.Request
+	chunkParam  string // for lookups, the parameter listing IDs
+	concurrency int    // for chunked lookups
+}
+
+// Invoke executes the query on the given context and client.
+func (q Query) Invoke(ctx context.Context, cli *twitter.Client) (*Reply, error) {
+	var rsp *twitter.Reply
+	var err error
+	if q.chunkParam != "" {
+		rsp, err = cli.CallChunked(ctx, q.Request, q.chunkParam, q.concurrency)
+	} else {
+		rsp, err = cli.Call(ctx, q.Request)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var spaces types.Spaces
+	if len(rsp.Data) == 0 {
+		// no results
+	} else if rsp.Data[0] == '{' {
+		// single-value return
+		spaces = append(spaces, new(types.Space))
+		err = json.Unmarshal(rsp.Data, spaces[0])
+	} else {
+		// multiple-value return
+		err = json.Unmarshal(rsp.Data, &spaces)
+	}
+	if err != nil {
+		return nil, &jape.Error{Data: rsp.Data, Message: "decoding spaces data", Err: err}
+	}
+	out := &Reply{Reply: rsp, Spaces: spaces}
+	q.Request.Params.Set(twitter.NextTokenParam, "")
+	if len(rsp.Meta) != 0 {
+		if err := json.Unmarshal(rsp.Meta, &out.Meta); err != nil {
+			return nil, &jape.Error{Data: rsp.Meta, Message: "decoding response metadata", Err: err}
+		}
+		// Update the query page token. Do this even if next_token is empty; the
+		// HasMorePages method uses the presence of the parameter to distinguish
+		// a fresh query from end-of-pages.
+		q.Request.Params.Set(twitter.NextTokenParam, out.Meta.NextToken)
+	}
+	return out, nil
+}
+
+// HasMorePages reports whether the query has more pages to fetch. This is true
+// for a freshly-constructed query, and for an invoked query where the server
+// has not reported a next-page token.
+func (q Query) HasMorePages() bool {
+	v, ok := q.Request.Params[twitter.NextTokenParam]
+	return !ok || v[0] != ""
+}
+
+// ResetPageToken clears (resets) the query's current page token. Subsequently
+// invoking the query will then fetch the first page of results.
+func (q Query) ResetPageToken() { q.Request.Params.Reset(twitter.NextTokenParam) }
+
+// PageToken returns the page token the query will send when it is next
+// invoked, or "" if the query is fresh or has no more pages.
+func (q Query) PageToken() string { return q.Request.Params.Get(twitter.NextTokenParam) }
+
+// Iterate returns an iterator over the Spaces reported by q, which fetches
+// pages of results from cli as needed.
+func (q Query) Iterate(cli *twitter.Client) Iterator {
+	return Iterator{twitter.NewIterator[*Reply, *types.Space](cli, q, func(r *Reply) []*types.Space {
+		return r.Spaces
+	})}
+}
+
+// An Iterator yields the Spaces reported by a paginated query.
+type Iterator struct {
+	*twitter.Iterator[*Reply, *types.Space]
+}
+
+// Space returns the current Space. It is valid only after Next returns true.
+func (it Iterator) Space() *types.Space { return it.Item() }
+
+// A Reply is the response from a Query.
+type Reply struct {
+	*twitter.Reply
+	Spaces types.Spaces
+	Meta   *twitter.Pagination
+}
+
+// LookupOpts provides parameters for Space lookup. A nil *LookupOpts provides
+// empty values for all fields.
+type LookupOpts struct {
+	// Additional Space IDs to query.
+	More []string
+
+	// Optional response fields and expansions.
+	Optional []types.Fields
+
+	// If more than twitter.MaxLookupIDs IDs are given, issue up to this many
+	// requests concurrently. If zero, requests are issued one at a time.
+	Concurrency int
+}
+
+func (o *LookupOpts) addRequestParams(req *jape.Request) {
+	if o == nil {
+		return // nothing to do
+	}
+	for _, fs := range o.Optional {
+		if vs := fs.Values(); len(vs) != 0 {
+			req.Params.Add(fs.Label(), vs...)
+		}
+	}
+}
+
+// SearchOpts provides parameters for Space search. A nil *SearchOpts provides
+// empty or zero values for all fields.
+type SearchOpts struct {
+	// A pagination token provided by the server.
+	PageToken string
+
+	// The state of Spaces to match: "live", "scheduled", or "all".
+	// If empty, the server uses "all".
+	State string
+
+	// The maximum number of results to return; 0 means let the server choose.
+	// The service will accept values up to 100.
+	MaxResults int
+
+	// Optional response fields and expansions.
+	Optional []types.Fields
+}
+
+func (o *SearchOpts) addRequestParams(req *jape.Request) {
+	if o == nil {
+		return // nothing to do
+	}
+	if o.PageToken != "" {
+		req.Params.Set(twitter.NextTokenParam, o.PageToken)
+	}
+	if o.State != "" {
+		req.Params.Set("state", o.State)
+	}
+	if o.MaxResults > 0 {
+		req.Params.Set("max_results", strconv.Itoa(o.MaxResults))
+	}
+	for _, fs := range o.Optional {
+		if vs := fs.Values(); len(vs) != 0 {
+			req.Params.Add(fs.Label(), vs...)
+		}
+	}
+}
+
+// ListOpts provide parameters for listing the users or tweets associated with
+// a Space. A nil *ListOpts provides empty values for all fields.
+type ListOpts struct {
+	// A pagination token provided by the server.
+	PageToken string
+
+	// The maximum number of results to return; 0 means let the server choose.
+	// The service will accept values up to 100.
+	MaxResults int
+
+	// Optional response fields and expansions.
+	Optional []types.Fields
+}
+
+func (o *ListOpts) addRequestParams(req *jape.Request) {
+	if o == nil {
+		return // nothing to do
+	}
+	if o.PageToken != "" {
+		req.Params.Set(twitter.NextTokenParam, o.PageToken)
+	}
+	if o.MaxResults > 0 {
+		req.Params.Set("max_results", strconv.Itoa(o.MaxResults))
+	}
+	for _, fs := range o.Optional {
+		if vs := fs.Values(); len(vs) != 0 {
+			req.Params.Add(fs.Label(), vs...)
+		}
+	}
+}
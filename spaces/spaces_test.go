@@ -0,0 +1,219 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package spaces_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/928799934/twitter/internal/otest"
+	"github.com/928799934/twitter/spaces"
+	"github.com/928799934/twitter/types"
+)
+
+func TestLookup(t *testing.T) {
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if got := q.Get("space.fields"); got != "host_ids,participant_count" {
+			t.Errorf("Parameter space.fields: got %q", got)
+		}
+		switch r.URL.Path {
+		case "/2/spaces/s1":
+			io.WriteString(w, `{"data":{"id":"s1","state":"live","host_ids":["12"],"participant_count":5},`+
+				`"includes":{"users":[{"id":"12","username":"jack"}]}}`)
+		case "/2/spaces":
+			if got := q.Get("ids"); got != "s1,s2" {
+				t.Errorf("Parameter ids: got %q, want s1,s2", got)
+			}
+			io.WriteString(w, `{"data":[{"id":"s1","state":"live"},{"id":"s2","state":"scheduled"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	ctx := context.Background()
+	fields := []types.Fields{
+		types.SpaceFields{HostIDs: true, ParticipantCount: true},
+		types.Expansions{HostIDs: true},
+	}
+
+	rsp, err := spaces.Lookup("s1", &spaces.LookupOpts{Optional: fields}).Invoke(ctx, cli)
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	if len(rsp.Spaces) != 1 || rsp.Spaces[0].State != "live" || rsp.Spaces[0].ParticipantCount != 5 {
+		t.Errorf("Lookup: got %+v, want live space s1", rsp.Spaces)
+	}
+	hosts, err := rsp.IncludedUsers()
+	if err != nil || hosts.FindByID("12") == nil {
+		t.Errorf("Included hosts: got %+v, %v", hosts, err)
+	}
+
+	rsp, err = spaces.Lookup("s1", &spaces.LookupOpts{
+		More:     []string{"s2"},
+		Optional: fields,
+	}).Invoke(ctx, cli)
+	if err != nil {
+		t.Fatalf("Lookup multiple failed: %v", err)
+	}
+	if len(rsp.Spaces) != 2 || rsp.Spaces.FindByID("s2") == nil {
+		t.Errorf("Lookup multiple: got %+v, want s1 and s2", rsp.Spaces)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/2/spaces/search" || q.Get("query") != "music" || q.Get("state") != "live" {
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+		switch q.Get("pagination_token") {
+		case "":
+			io.WriteString(w, `{"data":[{"id":"s1","state":"live"}],"meta":{"result_count":1,"next_token":"p2"}}`)
+		case "p2":
+			io.WriteString(w, `{"data":[{"id":"s2","state":"live"}],"meta":{"result_count":1}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	var got []string
+	it := spaces.Search("music", &spaces.SearchOpts{State: "live"}).Iterate(cli)
+	for it.Next(context.Background()) {
+		got = append(got, it.Space().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(got) != 2 || got[0] != "s1" || got[1] != "s2" {
+		t.Errorf("Search: got %q, want [s1 s2]", got)
+	}
+}
+
+func TestByCreator(t *testing.T) {
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2/spaces/by/creator_ids" {
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("user_ids"); got != "u1,u2" {
+			t.Errorf("User IDs: got %q, want u1,u2", got)
+		}
+		io.WriteString(w, `{"data":[{"id":"s1","state":"live","creator_id":"u1"}],"meta":{"result_count":1}}`)
+	}))
+
+	rsp, err := spaces.ByCreator("u1", &spaces.LookupOpts{
+		More:     []string{"u2"},
+		Optional: []types.Fields{types.SpaceFields{CreatorID: true}},
+	}).Invoke(context.Background(), cli)
+	if err != nil {
+		t.Fatalf("ByCreator failed: %v", err)
+	}
+	if len(rsp.Spaces) != 1 || rsp.Spaces[0].CreatorID != "u1" {
+		t.Errorf("ByCreator: got %+v, want one space by u1", rsp.Spaces)
+	}
+	if rsp.Meta == nil || rsp.Meta.ResultCount != 1 {
+		t.Errorf("ByCreator meta: got %+v, want result count 1", rsp.Meta)
+	}
+}
+
+func TestBuyers(t *testing.T) {
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2/spaces/s1/buyers" {
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("user.fields"); got != "verified" {
+			t.Errorf("User fields: got %q, want verified", got)
+		}
+		io.WriteString(w, `{"data":[{"id":"u1","username":"alice","name":"Alice"}]}`)
+	}))
+
+	rsp, err := spaces.Buyers("s1", &spaces.ListOpts{
+		Optional: []types.Fields{types.UserFields{Verified: true}},
+	}).Invoke(context.Background(), cli)
+	if err != nil {
+		t.Fatalf("Buyers failed: %v", err)
+	}
+	if len(rsp.Users) != 1 || rsp.Users[0].Username != "alice" {
+		t.Errorf("Buyers: got %+v, want one user alice", rsp.Users)
+	}
+}
+
+func TestTweets(t *testing.T) {
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2/spaces/s1/tweets" {
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if got := q.Get("tweet.fields"); got != "author_id" {
+			t.Errorf("Tweet fields: got %q, want author_id", got)
+		}
+		if got := q.Get("expansions"); got != "author_id" {
+			t.Errorf("Expansions: got %q, want author_id", got)
+		}
+		io.WriteString(w, `{"data":[{"id":"t1","text":"hello","author_id":"u1"}],`+
+			`"includes":{"users":[{"id":"u1","username":"alice","name":"Alice"}]}}`)
+	}))
+
+	rsp, err := spaces.Tweets("s1", &spaces.ListOpts{
+		Optional: []types.Fields{
+			types.TweetFields{AuthorID: true},
+			types.Expansions{AuthorID: true},
+		},
+	}).Invoke(context.Background(), cli)
+	if err != nil {
+		t.Fatalf("Tweets failed: %v", err)
+	}
+	if len(rsp.Tweets) != 1 || rsp.Tweets[0].AuthorID != "u1" {
+		t.Errorf("Tweets: got %+v, want one tweet by u1", rsp.Tweets)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	// Each poll reports the next set of spaces; the last repeats forever.
+	polls := []string{
+		`{"data":[{"id":"s1","state":"scheduled"},{"id":"s2","state":"live"}]}`,
+		`{"data":[{"id":"s1","state":"scheduled"},{"id":"s2","state":"live"}]}`,
+		`{"data":[{"id":"s1","state":"live"}]}`,
+	}
+	var n int
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2/spaces/by/creator_ids" {
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+		io.WriteString(w, polls[n])
+		if n < len(polls)-1 {
+			n++
+		}
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := make(chan *spaces.Event)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- spaces.Watch(ctx, cli, []string{"u1"}, ch, &spaces.WatchOpts{
+			Interval:  time.Millisecond,
+			ByCreator: true,
+		})
+	}()
+
+	var got []string
+	for evt := range ch {
+		got = append(got, evt.Space.ID+":"+evt.Previous+">"+evt.Space.State)
+		if len(got) == 4 {
+			cancel()
+		}
+	}
+	if err := <-errc; err != context.Canceled {
+		t.Errorf("Watch: got error %v, want %v", err, context.Canceled)
+	}
+	// The order of events within a poll follows the reply, except that ended
+	// spaces are reported after the others.
+	want := "s1:>scheduled s2:>live s1:scheduled>live s2:live>ended"
+	if s := strings.Join(got, " "); s != want {
+		t.Errorf("Watch events:\ngot  %q\nwant %q", s, want)
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package spaces
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/jape"
+	"github.com/928799934/twitter/types"
+)
+
+// DefaultWatchInterval is the default interval between lookups for Watch.
+const DefaultWatchInterval = 30 * time.Second
+
+// StateEnded is the state reported for a Space that ended.
+const StateEnded = "ended"
+
+// An Event reports a change in the state of a watched Space.
+type Event struct {
+	// The Space as most recently reported by the server.
+	Space *types.Space
+
+	// The state of the Space before this change, or "" if this is the first
+	// time the Space was observed.
+	Previous string
+}
+
+// Watch polls the given Spaces repeatedly, and sends an Event to ch each time
+// one of them is first observed or changes state (for example, from
+// "scheduled" to "live", or from "live" to "ended"). It runs until ctx ends or
+// a lookup fails, and closes ch before returning.
+//
+//	ch := make(chan *spaces.Event)
+//	go func() { errc <- spaces.Watch(ctx, cli, ids, ch, nil) }()
+//	for evt := range ch {
+//	   notify(evt.Space, evt.Previous)
+//	}
+//
+// By default ids are Space IDs. If opts.ByCreator is true, ids are user IDs
+// and Watch reports the Spaces those users create. The server reports only
+// live and scheduled Spaces by creator, so when a Space previously reported
+// for a creator is no longer present, Watch reports it with state "ended".
+//
+// Between lookups Watch waits for the interval given in opts. If the rate
+// limit for the lookup is exhausted, it waits for the limit to reset instead.
+// If ctx ends, Watch reports the error from the context.
+//
+// API: 2/spaces or 2/spaces/by/creator_ids
+func Watch(ctx context.Context, cli *twitter.Client, ids []string, ch chan<- *Event, opts *WatchOpts) error {
+	defer close(ch)
+	if len(ids) == 0 {
+		return errors.New("no IDs to watch")
+	}
+	jc := (*jape.Client)(cli)
+	last := make(map[string]*types.Space)
+	for {
+		q := opts.query(ids)
+		q.NoCache = true // each lookup must see the current state
+		rsp, err := q.Invoke(ctx, cli)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		} else if err != nil {
+			return err
+		}
+
+		seen := make(map[string]bool)
+		for _, sp := range rsp.Spaces {
+			seen[sp.ID] = true
+			old := last[sp.ID]
+			last[sp.ID] = sp
+			if old != nil && old.State == sp.State {
+				continue
+			}
+			evt := &Event{Space: sp}
+			if old != nil {
+				evt.Previous = old.State
+			}
+			if err := send(ctx, ch, evt); err != nil {
+				return err
+			}
+		}
+		if opts != nil && opts.ByCreator {
+			for id, old := range last {
+				if seen[id] {
+					continue
+				}
+				delete(last, id)
+				if old.State == StateEnded {
+					continue
+				}
+				sp := *old
+				sp.State = StateEnded
+				if err := send(ctx, ch, &Event{Space: &sp, Previous: old.State}); err != nil {
+					return err
+				}
+			}
+		}
+
+		wait := opts.interval()
+		if rl := rsp.RateLimit; rl != nil && rl.Remaining <= 0 {
+			if d := rl.Reset.Sub(jc.Now()); d > wait {
+				wait = d
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-jc.After(wait):
+		}
+	}
+}
+
+func send(ctx context.Context, ch chan<- *Event, evt *Event) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case ch <- evt:
+		return nil
+	}
+}
+
+// WatchOpts provides parameters for Watch. A nil *WatchOpts provides empty
+// values for all fields.
+type WatchOpts struct {
+	// The interval between lookups. If zero, use DefaultWatchInterval.
+	Interval time.Duration
+
+	// If true, the IDs given to Watch are creator user IDs rather than Space
+	// IDs (see ByCreator).
+	ByCreator bool
+
+	// Optional response fields and expansions.
+	Optional []types.Fields
+}
+
+func (o *WatchOpts) interval() time.Duration {
+	if o == nil || o.Interval <= 0 {
+		return DefaultWatchInterval
+	}
+	return o.Interval
+}
+
+func (o *WatchOpts) query(ids []string) Query {
+	lo := &LookupOpts{More: ids[1:]}
+	if o == nil {
+		return Lookup(ids[0], lo)
+	}
+	lo.Optional = o.Optional
+	if o.ByCreator {
+		return ByCreator(ids[0], lo)
+	}
+	return Lookup(ids[0], lo)
+}
@@ -0,0 +1,87 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+// Package trends supports queries for trending topics.
+//
+// To look up the topics currently trending at a location, use trends.ByWOEID
+// with the Yahoo! "where on earth" ID (WOEID) of the location:
+//
+//	rsp, err := trends.ByWOEID(trends.Worldwide, nil).Invoke(ctx, cli)
+//	...
+//	for _, t := range rsp.Trends {
+//	   fmt.Println(t.Name, t.TweetCount)
+//	}
+package trends
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/jape"
+)
+
+// Worldwide is the WOEID for worldwide trends.
+const Worldwide = 1
+
+// A Trend is a single trending topic.
+type Trend struct {
+	Name       string `json:"trend_name"`            // e.g., "#GoAvsGo"
+	TweetCount int    `json:"tweet_count,omitempty"` // if known
+}
+
+// ByWOEID constructs a query for the topics trending at the location with the
+// given WOEID, most popular first. Use Worldwide for global trends.
+//
+// API: 2/trends/by/woeid/:woeid
+func ByWOEID(woeid int, opts *Opts) Query {
+	req := &jape.Request{
+		Method: "2/trends/by/woeid/" + strconv.Itoa(woeid),
+		Params: make(jape.Params),
+	}
+	opts.addRequestParams(req)
+	return Query{Request: req}
+}
+
+// Opts provides parameters for trend queries. A nil *Opts provides empty or
+// zero values for all fields.
+type Opts struct {
+	// The maximum number of trends to return; 0 means let the server choose.
+	// The service will accept values up to 50.
+	MaxTrends int
+}
+
+func (o *Opts) addRequestParams(req *jape.Request) {
+	if o == nil {
+		return // nothing to do
+	}
+	if o.MaxTrends > 0 {
+		req.Params.Set("max_trends", strconv.Itoa(o.MaxTrends))
+	}
+}
+
+// A Query performs a query for trending topics.
+type Query struct {
+	*jape.Request
+}
+
+// Invoke executes the query on the given context and client.
+func (q Query) Invoke(ctx context.Context, cli *twitter.Client) (*Reply, error) {
+	rsp, err := cli.Call(ctx, q.Request)
+	if err != nil {
+		return nil, err
+	}
+	out := &Reply{Reply: rsp}
+	if len(rsp.Data) == 0 {
+		// no results
+	} else if err := json.Unmarshal(rsp.Data, &out.Trends); err != nil {
+		return nil, &jape.Error{Data: rsp.Data, Message: "decoding trend data", Err: err}
+	}
+	return out, nil
+}
+
+// A Reply is the response from a Query.
+type Reply struct {
+	*twitter.Reply
+	Trends []*Trend
+}
@@ -0,0 +1,43 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package trends_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/jape"
+	"github.com/928799934/twitter/trends"
+)
+
+func TestByWOEID(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2/trends/by/woeid/1" {
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("max_trends"); got != "2" {
+			t.Errorf("Parameter max_trends: got %q, want 2", got)
+		}
+		io.WriteString(w, `{"data":[{"trend_name":"#cats","tweet_count":1200},{"trend_name":"dogs"}]}`)
+	}))
+	defer srv.Close()
+	cli := twitter.NewClient(&jape.Client{BaseURL: srv.URL})
+
+	rsp, err := trends.ByWOEID(trends.Worldwide, &trends.Opts{MaxTrends: 2}).Invoke(context.Background(), cli)
+	if err != nil {
+		t.Fatalf("ByWOEID failed: %v", err)
+	}
+	if len(rsp.Trends) != 2 {
+		t.Fatalf("ByWOEID: got %d trends, want 2", len(rsp.Trends))
+	}
+	if tr := rsp.Trends[0]; tr.Name != "#cats" || tr.TweetCount != 1200 {
+		t.Errorf("Trend 0: got %+v", tr)
+	}
+	if tr := rsp.Trends[1]; tr.Name != "dogs" || tr.TweetCount != 0 {
+		t.Errorf("Trend 1: got %+v", tr)
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package tweets
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/jape"
+	"github.com/928799934/twitter/rules"
+	"github.com/928799934/twitter/types"
+)
+
+// DefaultPollInterval is the default interval between search queries when
+// Await falls back to polling.
+const DefaultPollInterval = 15 * time.Second
+
+// Await blocks until the filtered stream delivers a tweet matching the stream
+// rules, the timeout in opts expires, or ctx ends.  On success, the reply
+// contains exactly one tweet.
+//
+// If opts.Rules is set, Await first replaces the stream rules with those rules
+// (see rules.Sync). Otherwise, Await matches the rules already installed on
+// the stream.
+//
+//	rsp, err := tweets.Await(ctx, cli, &tweets.AwaitOpts{
+//	   Rules:   rules.Adds{{Query: "from:jack"}},
+//	   Timeout: time.Hour,
+//	})
+//
+// If the filtered stream is not accessible to the caller (the server reports
+// 403 Forbidden), and opts sets Query or Rules, Await falls back to polling
+// recent search instead. In that case the reply contains the earliest tweet
+// from the first batch of new matches.
+//
+// If the timeout expires or ctx ends before a match is found, Await reports
+// the error from the context. If the server closes the stream before a match
+// is found, Await reports an error.
+func Await(ctx context.Context, cli *twitter.Client, opts *AwaitOpts) (*Reply, error) {
+	if t := opts.timeout(); t > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t)
+		defer cancel()
+	}
+
+	var out *Reply
+	err := opts.stream(ctx, cli, func(rsp *Reply) error {
+		if rsp.System != nil || len(rsp.Tweets) == 0 {
+			return nil // a system message or keep-alive; keep waiting
+		}
+		out = rsp
+		return jape.ErrStopStreaming
+	})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	} else if err == nil && out == nil {
+		return nil, &jape.Error{Message: "stream ended before a matching tweet"}
+	} else if err == nil {
+		return out, nil
+	}
+
+	var jerr *jape.Error
+	if opts.searchQuery() == "" || !errors.As(err, &jerr) || jerr.Status != http.StatusForbidden {
+		return nil, err
+	}
+	return opts.poll(ctx, cli)
+}
+
+// stream runs a filtered stream delivering to f, first installing the rules
+// in o if there are any.
+func (o *AwaitOpts) stream(ctx context.Context, cli *twitter.Client, f Callback) error {
+	sopts := &StreamOpts{MaxResults: 1, Optional: o.optional()}
+	if o != nil && len(o.Rules) != 0 {
+		return StreamWithRules(ctx, cli, o.Rules, f, sopts)
+	}
+	return SearchStream(f, sopts).Invoke(ctx, cli)
+}
+
+// AwaitOpts provides parameters for Await. A nil *AwaitOpts provides empty
+// values for all fields.
+type AwaitOpts struct {
+	// If positive, give up waiting for a match after this long.
+	Timeout time.Duration
+
+	// If set, replace the filtered stream rules with these rules before
+	// waiting (see rules.Sync). This deletes any other rules on the stream.
+	// If empty, Await uses the rules already installed.
+	Rules rules.Adds
+
+	// If set, poll recent search with this query if the filtered stream is
+	// not accessible. For query syntax see SearchRecent. If empty, the query
+	// is derived from Rules (see rules.SearchQuery).
+	Query string
+
+	// The interval between search queries when polling.
+	// If zero, use DefaultPollInterval.
+	PollInterval time.Duration
+
+	// Optional response fields and expansions.
+	Optional []types.Fields
+}
+
+func (o *AwaitOpts) timeout() time.Duration {
+	if o == nil {
+		return 0
+	}
+	return o.Timeout
+}
+
+func (o *AwaitOpts) optional() []types.Fields {
+	if o == nil {
+		return nil
+	}
+	return o.Optional
+}
+
+// searchQuery returns the recent search query to poll if the filtered stream
+// is not accessible, or "" if there is none.
+func (o *AwaitOpts) searchQuery() string {
+	if o == nil {
+		return ""
+	} else if o.Query != "" {
+		return o.Query
+	} else if len(o.Rules) == 0 {
+		return ""
+	}
+	rs := make([]rules.Rule, len(o.Rules))
+	for i, a := range o.Rules {
+		rs[i] = rules.Rule{Value: a.Query, Tag: a.Tag}
+	}
+	return rules.SearchQuery(rs)
+}
+
+func (o *AwaitOpts) pollInterval() time.Duration {
+	if o.PollInterval <= 0 {
+		return DefaultPollInterval
+	}
+	return o.PollInterval
+}
+
+// poll runs recent search for o.searchQuery() until at least one match is found, or
+// until ctx ends. Only tweets posted after poll begins are considered.
+func (o *AwaitOpts) poll(ctx context.Context, cli *twitter.Client) (*Reply, error) {
+	start := (*jape.Client)(cli).Now().UTC()
+	for {
+		q := SearchRecent(o.searchQuery(), &SearchOpts{
+			StartTime: start,
+			Optional:  o.Optional,
+		})
+		q.NoCache = true // each poll must see new results
+		rsp, err := q.Invoke(ctx, cli)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		} else if err != nil {
+			return nil, err
+		}
+		if n := len(rsp.Tweets); n != 0 {
+			// Search results are ordered newest first.
+			rsp.Tweets = rsp.Tweets[n-1:]
+			return rsp, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-(*jape.Client)(cli).After(o.pollInterval()):
+		}
+	}
+}
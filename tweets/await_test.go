@@ -0,0 +1,176 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package tweets_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/928799934/twitter/internal/otest"
+	"github.com/928799934/twitter/rules"
+	"github.com/928799934/twitter/tweets"
+)
+
+func TestAwaitStream(t *testing.T) {
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2/tweets/search/stream" {
+			t.Errorf("Unexpected request path %q", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, `{"data":{"id":"1","text":"first"}}`+"\r\n")
+		io.WriteString(w, `{"data":{"id":"2","text":"second"}}`+"\r\n")
+	}))
+
+	rsp, err := tweets.Await(context.Background(), cli, nil)
+	if err != nil {
+		t.Fatalf("Await failed: %v", err)
+	}
+	if len(rsp.Tweets) != 1 || rsp.Tweets[0].ID != "1" {
+		t.Errorf("Await: got %+v, want tweet 1", rsp.Tweets)
+	}
+}
+
+func TestAwaitSystemMessage(t *testing.T) {
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"errors":[{"title":"operational-disconnect",`+
+			`"type":"https://api.twitter.com/2/problems/operational-disconnect"}]}`+"\r\n")
+		io.WriteString(w, `{"data":{"id":"3","text":"after"}}`+"\r\n")
+	}))
+
+	rsp, err := tweets.Await(context.Background(), cli, nil)
+	if err != nil {
+		t.Fatalf("Await failed: %v", err)
+	}
+	if len(rsp.Tweets) != 1 || rsp.Tweets[0].ID != "3" {
+		t.Errorf("Await: got %+v, want tweet 3", rsp.Tweets)
+	}
+}
+
+func TestAwaitStreamEnded(t *testing.T) {
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "\r\n") // a keep-alive, then the stream closes
+	}))
+
+	rsp, err := tweets.Await(context.Background(), cli, nil)
+	if err == nil {
+		t.Fatalf("Await: got %+v, want error", rsp)
+	} else if rsp != nil {
+		t.Errorf("Await: got reply %+v with error %v", rsp, err)
+	}
+}
+
+func TestAwaitRules(t *testing.T) {
+	var updates []string
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2/tweets/search/stream/rules":
+			if r.Method == "GET" {
+				io.WriteString(w, `{"data":[{"id":"1","value":"dogs"}],"meta":{"sent":"2022-03-14T12:00:00.000Z"}}`)
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			updates = append(updates, string(body))
+			io.WriteString(w, `{"data":[{"id":"2","value":"cats"}],"meta":{"sent":"2022-03-14T12:00:00.000Z"}}`)
+		case "/2/tweets/search/stream":
+			if len(updates) != 2 {
+				t.Errorf("Stream opened after %d rule updates, want 2", len(updates))
+			}
+			io.WriteString(w, `{"data":{"id":"4","text":"a cat"},"matching_rules":[{"id":"2"}]}`+"\r\n")
+		default:
+			t.Errorf("Unexpected request path %q", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+
+	rsp, err := tweets.Await(context.Background(), cli, &tweets.AwaitOpts{
+		Rules: rules.Adds{{Query: "cats"}},
+	})
+	if err != nil {
+		t.Fatalf("Await failed: %v", err)
+	}
+	if len(rsp.Tweets) != 1 || rsp.Tweets[0].ID != "4" {
+		t.Errorf("Await: got %+v, want tweet 4", rsp.Tweets)
+	}
+}
+
+func TestAwaitRulesPoll(t *testing.T) {
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2/tweets/search/stream/rules":
+			http.Error(w, `{"title":"Client Forbidden"}`, http.StatusForbidden)
+		case "/2/tweets/search/recent":
+			if got, want := r.URL.Query().Get("query"), "(cats) OR (dogs)"; got != want {
+				t.Errorf("Search query: got %q, want %q", got, want)
+			}
+			io.WriteString(w, `{"data":[{"id":"8","text":"a dog"}]}`)
+		default:
+			t.Errorf("Unexpected request path %q", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+
+	rsp, err := tweets.Await(context.Background(), cli, &tweets.AwaitOpts{
+		Rules: rules.Adds{{Query: "cats"}, {Query: "dogs"}},
+	})
+	if err != nil {
+		t.Fatalf("Await failed: %v", err)
+	}
+	if len(rsp.Tweets) != 1 || rsp.Tweets[0].ID != "8" {
+		t.Errorf("Await: got %+v, want tweet 8", rsp.Tweets)
+	}
+}
+
+func TestAwaitPoll(t *testing.T) {
+	var polls int
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2/tweets/search/stream":
+			http.Error(w, `{"title":"Client Forbidden"}`, http.StatusForbidden)
+		case "/2/tweets/search/recent":
+			if got := r.URL.Query().Get("query"); got != "cats" {
+				t.Errorf("Search query: got %q, want cats", got)
+			}
+			polls++
+			if polls < 2 {
+				io.WriteString(w, `{"meta":{"result_count":0}}`)
+			} else {
+				io.WriteString(w, `{"data":[{"id":"6","text":"new"},{"id":"5","text":"old"}]}`)
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	rsp, err := tweets.Await(context.Background(), cli, &tweets.AwaitOpts{
+		Query:        "cats",
+		PollInterval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Await failed: %v", err)
+	}
+	if len(rsp.Tweets) != 1 || rsp.Tweets[0].ID != "5" {
+		t.Errorf("Await: got %+v, want tweet 5", rsp.Tweets)
+	}
+	if polls != 2 {
+		t.Errorf("Got %d polls, want 2", polls)
+	}
+}
+
+func TestAwaitTimeout(t *testing.T) {
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+
+	_, err := tweets.Await(context.Background(), cli, &tweets.AwaitOpts{
+		Timeout: 50 * time.Millisecond,
+	})
+	if err != context.DeadlineExceeded {
+		t.Errorf("Await: got error %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package tweets
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/jape"
+	"github.com/928799934/twitter/types"
+)
+
+// CountRecent constructs a query for the number of recent tweets matching
+// the specified query filter, grouped into time buckets. For query syntax,
+// see SearchRecent.
+//
+//	rsp, err := tweets.CountRecent("cat has:images", &tweets.CountOpts{
+//	   Granularity: "hour",
+//	}).Invoke(ctx, cli)
+//
+// API: 2/tweets/counts/recent
+func CountRecent(query string, opts *CountOpts) CountQuery {
+	req := &jape.Request{
+		Method: "2/tweets/counts/recent",
+		Params: make(jape.Params),
+	}
+	req.Params.Set("query", query)
+	opts.addRequestParams(req)
+	return CountQuery{Request: req}
+}
+
+// CountAll constructs a query for the number of tweets in the full archive
+// matching the specified query filter, grouped into time buckets. The results
+// are paginated; invoking the query again fetches the next page of buckets.
+// This endpoint requires Academic Research access.
+//
+// API: 2/tweets/counts/all
+func CountAll(query string, opts *CountOpts) CountQuery {
+	req := &jape.Request{
+		Method: "2/tweets/counts/all",
+		Params: make(jape.Params),
+	}
+	req.Params.Set("query", query)
+	opts.addRequestParams(req)
+	return CountQuery{Request: req}
+}
+
+// CountOpts provides parameters for tweet counts. A nil *CountOpts provides
+// empty or zero values for all fields.
+type CountOpts struct {
+	// A pagination token provided by the server (CountAll only).
+	PageToken string
+
+	// The size of the time buckets: "minute", "hour", or "day".
+	// If empty, the server uses "hour".
+	Granularity string
+
+	// The oldest UTC time from which results will be provided.
+	StartTime time.Time
+
+	// The latest (most recent) UTC time to which results will be provided.
+	EndTime time.Time
+
+	// If set, count tweets with IDs greater than this (exclusive).
+	SinceID string
+
+	// If set, count tweets with IDs smaller than this (exclusive).
+	UntilID string
+}
+
+func (o *CountOpts) addRequestParams(req *jape.Request) {
+	if o == nil {
+		return // nothing to do
+	}
+	if o.PageToken != "" {
+		req.Params.Set("next_token", o.PageToken)
+	}
+	if o.Granularity != "" {
+		req.Params.Set("granularity", o.Granularity)
+	}
+	if !o.StartTime.IsZero() {
+		req.Params.Set("start_time", o.StartTime.Format(types.DateFormat))
+	}
+	if !o.EndTime.IsZero() {
+		req.Params.Set("end_time", o.EndTime.Format(types.DateFormat))
+	}
+	if o.SinceID != "" {
+		req.Params.Set("since_id", o.SinceID)
+	}
+	if o.UntilID != "" {
+		req.Params.Set("until_id", o.UntilID)
+	}
+}
+
+// A CountQuery performs a tweet count query.
+type CountQuery struct {
+	*jape.Request
+}
+
+// Invoke executes the query on the given context and client. If the reply
+// contains a pagination token, q is updated in-place so that invoking the
+// query again will fetch the next page.
+func (q CountQuery) Invoke(ctx context.Context, cli *twitter.Client) (*CountReply, error) {
+	rsp, err := cli.Call(ctx, q.Request)
+	if err != nil {
+		return nil, err
+	}
+	out := &CountReply{Reply: rsp}
+	if len(rsp.Data) == 0 {
+		// no results
+	} else if err := json.Unmarshal(rsp.Data, &out.Counts); err != nil {
+		return nil, &jape.Error{Data: rsp.Data, Message: "decoding count data", Err: err}
+	}
+	q.Request.Params.Set("next_token", "")
+	if len(rsp.Meta) != 0 {
+		if err := json.Unmarshal(rsp.Meta, &out.Meta); err != nil {
+			return nil, &jape.Error{Data: rsp.Meta, Message: "decoding count metadata", Err: err}
+		}
+		q.Request.Params.Set("next_token", out.Meta.NextToken)
+	}
+	return out, nil
+}
+
+// HasMorePages reports whether the query has more pages to fetch. This is true
+// for a freshly-constructed query, and for an invoked query where the server
+// has reported a next-page token.
+func (q CountQuery) HasMorePages() bool {
+	v, ok := q.Request.Params["next_token"]
+	return !ok || v[0] != ""
+}
+
+// ResetPageToken clears (resets) the query's current page token. Subsequently
+// invoking the query will then fetch the first page of results.
+func (q CountQuery) ResetPageToken() { q.Request.Params.Reset("next_token") }
+
+// PageToken returns the page token the query will send when it is next
+// invoked, or "" if the query is fresh or has no more pages.
+func (q CountQuery) PageToken() string { return q.Request.Params.Get("next_token") }
+
+// A CountReply is the response from a CountQuery.
+type CountReply struct {
+	*twitter.Reply
+	Counts []*Count
+	Meta   *CountMeta
+}
+
+// A Count records the number of matching tweets in a time bucket.
+type Count struct {
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	TweetCount int       `json:"tweet_count"`
+}
+
+// CountMeta records metadata reported with tweet counts.
+type CountMeta struct {
+	TotalTweetCount int    `json:"total_tweet_count"`
+	NextToken       string `json:"next_token,omitempty"`
+}
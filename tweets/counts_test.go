@@ -0,0 +1,85 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package tweets_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/928799934/twitter/internal/otest"
+	"github.com/928799934/twitter/tweets"
+)
+
+func TestCountRecent(t *testing.T) {
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2/tweets/counts/recent" {
+			t.Errorf("Unexpected request path %q", r.URL.Path)
+		}
+		q := r.URL.Query()
+		for key, want := range map[string]string{
+			"query":       "cats",
+			"granularity": "day",
+			"since_id":    "100",
+		} {
+			if got := q.Get(key); got != want {
+				t.Errorf("Parameter %q: got %q, want %q", key, got, want)
+			}
+		}
+		io.WriteString(w, `{"data":[`+
+			`{"start":"2022-03-01T00:00:00.000Z","end":"2022-03-02T00:00:00.000Z","tweet_count":5},`+
+			`{"start":"2022-03-02T00:00:00.000Z","end":"2022-03-03T00:00:00.000Z","tweet_count":7}],`+
+			`"meta":{"total_tweet_count":12}}`)
+	}))
+
+	rsp, err := tweets.CountRecent("cats", &tweets.CountOpts{
+		Granularity: "day",
+		SinceID:     "100",
+	}).Invoke(context.Background(), cli)
+	if err != nil {
+		t.Fatalf("CountRecent failed: %v", err)
+	}
+	if rsp.Meta == nil || rsp.Meta.TotalTweetCount != 12 {
+		t.Errorf("Total count: got %+v, want 12", rsp.Meta)
+	}
+	if len(rsp.Counts) != 2 {
+		t.Fatalf("Counts: got %d buckets, want 2", len(rsp.Counts))
+	}
+	c := rsp.Counts[1]
+	if want := time.Date(2022, 3, 2, 0, 0, 0, 0, time.UTC); !c.Start.Equal(want) || c.TweetCount != 7 {
+		t.Errorf("Bucket 2: got %+v, want start %v, count 7", c, want)
+	}
+}
+
+func TestCountAll(t *testing.T) {
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2/tweets/counts/all" {
+			t.Errorf("Unexpected request path %q", r.URL.Path)
+		}
+		switch r.URL.Query().Get("next_token") {
+		case "":
+			io.WriteString(w, `{"data":[{"tweet_count":3}],"meta":{"total_tweet_count":3,"next_token":"p2"}}`)
+		case "p2":
+			io.WriteString(w, `{"data":[{"tweet_count":4}],"meta":{"total_tweet_count":4}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	var total int
+	q := tweets.CountAll("cats", nil)
+	for q.HasMorePages() {
+		rsp, err := q.Invoke(context.Background(), cli)
+		if err != nil {
+			t.Fatalf("CountAll failed: %v", err)
+		}
+		for _, c := range rsp.Counts {
+			total += c.TweetCount
+		}
+	}
+	if total != 7 {
+		t.Errorf("CountAll: got total %d, want 7", total)
+	}
+}
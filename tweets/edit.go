@@ -3,24 +3,45 @@
 package tweets
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
+	"github.com/928799934/twitter"
 	"github.com/928799934/twitter/jape"
 	"github.com/928799934/twitter/types"
 )
 
 // Create constructs a query to create a new tweet from the given settings.
+// On success, the reply contains the new tweet, with its ID and text.
+//
+//	rsp, err := tweets.Create(tweets.CreateOpts{
+//	   Text:      "Hello, world",
+//	   InReplyTo: tweetID,
+//	}).Invoke(ctx, cli)
+//	...
+//	newID := rsp.Tweets[0].ID
 //
 // API: POST 2/tweets
 func Create(opts CreateOpts) Query {
 	req := &jape.Request{
 		Method:     "2/tweets",
 		HTTPMethod: "POST",
+		Params:     make(jape.Params),
+	}
+	tweet := &postTweet{
+		Text:       opts.Text,
+		QuotedID:   opts.QuoteOf,
+		LimitReply: opts.ReplySettings,
 	}
-	tweet := &postTweet{Text: opts.Text, QuotedID: opts.QuoteOf}
 	if opts.InReplyTo != "" {
-		tweet.Reply = &replyOpts{InReplyTo: opts.InReplyTo}
+		tweet.Reply = &replyOpts{
+			InReplyTo: opts.InReplyTo,
+			Exclude:   opts.ExcludeReplyUsers,
+		}
 	}
 	if len(opts.PollOptions) != 0 {
 		tweet.Poll = &pollOpts{
@@ -28,6 +49,15 @@ func Create(opts CreateOpts) Query {
 			Duration: types.Minutes(opts.PollDuration),
 		}
 	}
+	if len(opts.MediaIDs) != 0 {
+		tweet.Media = &mediaOpts{
+			IDs:    opts.MediaIDs,
+			Tagged: opts.TaggedUsers,
+		}
+	}
+	if opts.PlaceID != "" {
+		tweet.Geo = &geoOpts{PlaceID: opts.PlaceID}
+	}
 
 	data, err := json.Marshal(tweet)
 	req.Data = data
@@ -42,6 +72,61 @@ type CreateOpts struct {
 	InReplyTo    string        // the ID of a tweet to reply to
 	PollOptions  []string      // options to create a poll (if non-empty)
 	PollDuration time.Duration // poll duration (required with poll options)
+
+	// Who may reply to the tweet: "mentionedUsers" or "following".
+	// If empty, anyone may reply.
+	ReplySettings string
+
+	// With InReplyTo, the IDs of users to omit from the mentions that
+	// precede the reply text.
+	ExcludeReplyUsers []string
+
+	// The IDs of uploaded media to attach to the tweet, and the IDs of users
+	// to tag in the media. Polls and media are mutually exclusive.
+	MediaIDs    []string
+	TaggedUsers []string
+
+	// The ID of a place to attach to the tweet.
+	PlaceID string
+}
+
+// Quote constructs a query to post a tweet with the given text that quotes
+// the target tweet. The target may be a tweet ID or the URL of a tweet, for
+// example "https://twitter.com/jack/status/20".
+//
+// API: POST 2/tweets
+func Quote(target, text string) Query {
+	id, err := parseTweetID(target)
+	q := Create(CreateOpts{Text: text, QuoteOf: id})
+	if err != nil {
+		q.encodeErr = err
+	}
+	return q
+}
+
+// parseTweetID returns the tweet ID denoted by s, which is either a tweet ID
+// or the URL of a tweet.
+func parseTweetID(s string) (string, error) {
+	id := s
+	if u, err := url.Parse(s); err == nil && u.Host != "" {
+		parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+		id = ""
+		for i := 0; i+1 < len(parts); i++ {
+			if parts[i] == "status" || parts[i] == "statuses" {
+				id = parts[i+1]
+				break
+			}
+		}
+	}
+	if id == "" {
+		return "", fmt.Errorf("invalid tweet ID or URL %q", s)
+	}
+	for _, c := range id {
+		if c < '0' || c > '9' {
+			return "", fmt.Errorf("invalid tweet ID or URL %q", s)
+		}
+	}
+	return id, nil
 }
 
 type postTweet struct {
@@ -50,8 +135,10 @@ type postTweet struct {
 	LimitReply string     `json:"reply_settings,omitempty"` // mentionedUsers, following
 	Poll       *pollOpts  `json:"poll,omitempty"`
 	Reply      *replyOpts `json:"reply,omitempty"`
+	Media      *mediaOpts `json:"media,omitempty"`
+	Geo        *geoOpts   `json:"geo,omitempty"`
 
-	// TODO: DM links, super followers, geo, media
+	// TODO: DM links, super followers
 }
 
 type pollOpts struct {
@@ -63,3 +150,54 @@ type replyOpts struct {
 	InReplyTo string   `json:"in_reply_to_tweet_id,omitempty"`
 	Exclude   []string `json:"exclude_reply_user_ids,omitempty"`
 }
+
+type mediaOpts struct {
+	IDs    []string `json:"media_ids"`
+	Tagged []string `json:"tagged_user_ids,omitempty"`
+}
+
+type geoOpts struct {
+	PlaceID string `json:"place_id"`
+}
+
+// EditHistory fetches all the versions of the given tweet ID, oldest first.
+// The last version is the most recent. For a tweet that was not edited, the
+// result contains only that tweet. Versions the server does not report, for
+// example because they are no longer available, are omitted. Any optional
+// fields in opts are applied to each version; opts.More is ignored.
+//
+// API: 2/tweets, with the edit_history_tweet_ids expansion
+func EditHistory(ctx context.Context, cli *twitter.Client, id string, opts *LookupOpts) (types.Tweets, error) {
+	var lopts LookupOpts
+	if opts != nil {
+		lopts = *opts
+	}
+	lopts.More = nil
+	lopts.Optional = append([]types.Fields{
+		types.TweetFields{EditHistory: true},
+		types.Expansions{EditHistoryTweetIDs: true},
+	}, lopts.Optional...)
+	rsp, err := Lookup(id, &lopts).Invoke(ctx, cli)
+	if err != nil {
+		return nil, err
+	} else if len(rsp.Tweets) == 0 {
+		return nil, fmt.Errorf("tweet %q not found", id)
+	}
+	tweet := rsp.Tweets[0]
+	if len(tweet.EditHistory) <= 1 {
+		return types.Tweets{tweet}, nil
+	}
+	inc, err := rsp.IncludedTweets()
+	if err != nil {
+		return nil, err
+	}
+	var out types.Tweets
+	for _, vid := range tweet.EditHistory {
+		if vid == tweet.ID {
+			out = append(out, tweet)
+		} else if v := inc.FindByID(vid); v != nil {
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,113 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package tweets_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/928799934/twitter/internal/otest"
+	"github.com/928799934/twitter/tweets"
+)
+
+func TestCreate(t *testing.T) {
+	var got json.RawMessage
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/2/tweets" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		got, _ = io.ReadAll(r.Body)
+		io.WriteString(w, `{"data":{"id":"12345","text":"hello"}}`)
+	}))
+
+	rsp, err := tweets.Create(tweets.CreateOpts{
+		Text:              "hello",
+		InReplyTo:         "100",
+		ExcludeReplyUsers: []string{"7"},
+		ReplySettings:     "following",
+		MediaIDs:          []string{"m1", "m2"},
+		TaggedUsers:       []string{"8"},
+		PlaceID:           "p1",
+	}).Invoke(context.Background(), cli)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if len(rsp.Tweets) != 1 || rsp.Tweets[0].ID != "12345" {
+		t.Errorf("Create: got %+v, want tweet 12345", rsp.Tweets)
+	}
+
+	const want = `{"text":"hello","reply_settings":"following",` +
+		`"reply":{"in_reply_to_tweet_id":"100","exclude_reply_user_ids":["7"]},` +
+		`"media":{"media_ids":["m1","m2"],"tagged_user_ids":["8"]},"geo":{"place_id":"p1"}}`
+	if string(got) != want {
+		t.Errorf("Request body:\ngot  %s\nwant %s", got, want)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	var got string
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = string(body)
+		io.WriteString(w, `{"data":{"id":"2","text":"look"}}`)
+	}))
+	ctx := context.Background()
+
+	for _, target := range []string{
+		"20",
+		"https://twitter.com/jack/status/20",
+		"https://mobile.twitter.com/jack/status/20/photo/1?s=20",
+	} {
+		if _, err := tweets.Quote(target, "look").Invoke(ctx, cli); err != nil {
+			t.Errorf("Quote %q failed: %v", target, err)
+			continue
+		}
+		if want := `{"text":"look","quote_tweet_id":"20"}`; got != want {
+			t.Errorf("Quote %q: got body %s, want %s", target, got, want)
+		}
+	}
+
+	for _, target := range []string{"", "nonesuch", "https://twitter.com/jack"} {
+		if _, err := tweets.Quote(target, "look").Invoke(ctx, cli); err == nil {
+			t.Errorf("Quote %q: got nil error, want error", target)
+		}
+	}
+}
+
+func TestEditHistory(t *testing.T) {
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if got := q.Get("expansions"); got != "edit_history_tweet_ids" {
+			t.Errorf("Parameter expansions: got %q", got)
+		}
+		switch q.Get("ids") {
+		case "3":
+			io.WriteString(w, `{"data":[{"id":"3","text":"v3","edit_history_tweet_ids":["1","2","3"]}],`+
+				`"includes":{"tweets":[{"id":"2","text":"v2"},{"id":"1","text":"v1"}]}}`)
+		case "9":
+			io.WriteString(w, `{"data":[{"id":"9","text":"once","edit_history_tweet_ids":["9"]}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	ctx := context.Background()
+
+	for id, want := range map[string]string{"3": "v1 v2 v3", "9": "once"} {
+		vs, err := tweets.EditHistory(ctx, cli, id, nil)
+		if err != nil {
+			t.Errorf("EditHistory(%s) failed: %v", id, err)
+			continue
+		}
+		var got []string
+		for _, v := range vs {
+			got = append(got, v.Text)
+		}
+		if strings.Join(got, " ") != want {
+			t.Errorf("EditHistory(%s): got %q, want %q", id, got, want)
+		}
+	}
+}
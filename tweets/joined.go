@@ -0,0 +1,122 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package tweets
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/928799934/twitter/types"
+)
+
+// A Joined is a denormalized view of a tweet, in which the objects referenced
+// by its expansions are embedded inline rather than by ID. When encoded as
+// JSON, the fields of the tweet itself appear at the top level of the object,
+// alongside the embedded objects.
+type Joined struct {
+	*types.Tweet
+
+	Author *types.User  `json:"author,omitempty"`
+	Media  types.Medias `json:"media,omitempty"`
+	Polls  types.Polls  `json:"polls,omitempty"`
+	Place  *types.Place `json:"place,omitempty"`
+	Refs   []*JoinedRef `json:"referenced_tweets,omitempty"` // replaces the tweet field
+}
+
+// MarshalJSON implements the json.Marshaler interface. This is required
+// because the embedded *types.Tweet has its own MarshalJSON method, which
+// would otherwise be promoted and omit the embedded objects.
+func (j *Joined) MarshalJSON() ([]byte, error) {
+	obj := make(map[string]json.RawMessage)
+	if j.Tweet != nil {
+		data, err := json.Marshal(j.Tweet)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return nil, err
+		}
+		delete(obj, "referenced_tweets") // replaced by j.Refs
+	}
+	data, err := json.Marshal(struct {
+		Author *types.User  `json:"author,omitempty"`
+		Media  types.Medias `json:"media,omitempty"`
+		Polls  types.Polls  `json:"polls,omitempty"`
+		Place  *types.Place `json:"place,omitempty"`
+		Refs   []*JoinedRef `json:"referenced_tweets,omitempty"`
+	}{Author: j.Author, Media: j.Media, Polls: j.Polls, Place: j.Place, Refs: j.Refs})
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	return json.Marshal(obj)
+}
+
+// A JoinedRef is a referenced tweet embedded in a Joined value.
+type JoinedRef struct {
+	Type   string       `json:"type"` // e.g., "quoted"
+	ID     string       `json:"id"`
+	Tweet  *types.Tweet `json:"tweet,omitempty"`  // nil if not included
+	Author *types.User  `json:"author,omitempty"` // nil if not included
+}
+
+// Joined returns a denormalized view of each tweet in r, in which the authors,
+// media, polls, places, and referenced tweets included in the reply are
+// embedded in the tweets that refer to them. Objects that were not requested
+// as expansions, or that the server did not include, are omitted.
+func (r *Reply) Joined() ([]*Joined, error) {
+	inc, err := r.Included()
+	if err != nil {
+		return nil, err
+	}
+	users, media, polls, places, refs := inc.Users, inc.Media, inc.Polls, inc.Places, inc.Tweets
+
+	out := make([]*Joined, len(r.Tweets))
+	for i, t := range r.Tweets {
+		j := &Joined{Tweet: t}
+		if t.AuthorID != "" {
+			j.Author = users.FindByID(t.AuthorID)
+		}
+		for _, key := range t.MediaKeys() {
+			if m := media.FindByKey(key); m != nil {
+				j.Media = append(j.Media, m)
+			}
+		}
+		for _, id := range t.PollIDs() {
+			if p := polls.FindByID(id); p != nil {
+				j.Polls = append(j.Polls, p)
+			}
+		}
+		if t.Location != nil && t.Location.PlaceID != "" {
+			j.Place = places.FindByID(t.Location.PlaceID)
+		}
+		for _, ref := range t.Referenced {
+			jr := &JoinedRef{Type: ref.Type, ID: ref.ID, Tweet: refs.FindByID(ref.ID)}
+			if jr.Tweet != nil && jr.Tweet.AuthorID != "" {
+				jr.Author = users.FindByID(jr.Tweet.AuthorID)
+			}
+			j.Refs = append(j.Refs, jr)
+		}
+		out[i] = j
+	}
+	return out, nil
+}
+
+// EncodeJoined writes the denormalized view of each tweet in r to w as JSON,
+// one object per line (see Joined). This format is suitable for bulk loading
+// into most document indexing systems.
+func (r *Reply) EncodeJoined(w io.Writer) error {
+	js, err := r.Joined()
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	for _, j := range js {
+		if err := enc.Encode(j); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package tweets_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/tweets"
+	"github.com/928799934/twitter/types"
+)
+
+func TestEncodeJoined(t *testing.T) {
+	rsp := &tweets.Reply{
+		Reply: &twitter.Reply{
+			Includes: map[string]json.RawMessage{
+				"users":  json.RawMessage(`[{"id":"u1","name":"Alice","username":"alice"},{"id":"u2","name":"Bob","username":"bob"}]`),
+				"media":  json.RawMessage(`[{"media_key":"m1","type":"photo"}]`),
+				"tweets": json.RawMessage(`[{"id":"t0","text":"original","author_id":"u2"}]`),
+			},
+		},
+		Tweets: types.Tweets{{
+			ID:          "t1",
+			Text:        "quoting",
+			AuthorID:    "u1",
+			Referenced:  []*types.Ref{{Type: "quoted", ID: "t0"}, {Type: "replied_to", ID: "t9"}},
+			Attachments: types.Attachments{"media_keys": {"m1", "m2"}},
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := rsp.EncodeJoined(&buf); err != nil {
+		t.Fatalf("EncodeJoined failed: %v", err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Decoding output: %v\n%s", err, buf.String())
+	}
+	t.Logf("Output: %s", buf.String())
+
+	if got["id"] != "t1" || got["text"] != "quoting" {
+		t.Errorf("Tweet fields not inlined: %v", got)
+	}
+	if a, _ := got["author"].(map[string]interface{}); a["username"] != "alice" {
+		t.Errorf("Author: got %v, want alice", got["author"])
+	}
+	if ms, _ := got["media"].([]interface{}); len(ms) != 1 {
+		t.Errorf("Media: got %v, want 1 item", got["media"])
+	}
+	refs, _ := got["referenced_tweets"].([]interface{})
+	if len(refs) != 2 {
+		t.Fatalf("Referenced: got %v, want 2 items", got["referenced_tweets"])
+	}
+	q, _ := refs[0].(map[string]interface{})
+	if tw, _ := q["tweet"].(map[string]interface{}); tw["text"] != "original" {
+		t.Errorf("Quoted tweet: got %v, want original", q["tweet"])
+	}
+	if a, _ := q["author"].(map[string]interface{}); a["username"] != "bob" {
+		t.Errorf("Quoted author: got %v, want bob", q["author"])
+	}
+	if r, _ := refs[1].(map[string]interface{}); r["tweet"] != nil {
+		t.Errorf("Missing reference: got %v, want no tweet", r["tweet"])
+	}
+}
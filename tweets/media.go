@@ -0,0 +1,109 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package tweets
+
+import (
+	"strings"
+
+	"github.com/928799934/twitter/types"
+)
+
+// A PhotoSize names one of the sizes in which the server can render a photo.
+type PhotoSize string
+
+// Photo sizes supported by the media server.
+const (
+	PhotoThumb    PhotoSize = "thumb"  // 150x150, cropped
+	PhotoSmall    PhotoSize = "small"  // up to 680 pixels on the long edge
+	PhotoMedium   PhotoSize = "medium" // up to 1200 pixels on the long edge
+	PhotoLarge    PhotoSize = "large"  // up to 2048 pixels on the long edge
+	PhotoOriginal PhotoSize = "orig"   // the original upload
+)
+
+// A MediaItem describes a media attachment of a tweet in a form ready for
+// display or download.
+type MediaItem struct {
+	Key  string // the media key
+	Type string // e.g., "photo", "video", "animated_gif"
+
+	// For photos, the URL of the image at the requested size.
+	// For videos and animated GIFs, the URL of the MP4 variant with the
+	// highest bit rate (see types.Media.BestVariant).
+	// This is empty if the server did not report a usable URL.
+	URL string
+
+	// For videos and animated GIFs, the content type and bit rate of the
+	// variant selected for URL.
+	ContentType string
+	BitRate     int
+
+	PreviewURL string             // a preview image, if available
+	Width      int                // pixels
+	Height     int                // pixels
+	Duration   types.Milliseconds // for videos
+
+	Media *types.Media // the original media record
+}
+
+// MediaOpts provides parameters for media resolution. A nil *MediaOpts
+// provides default values for all fields.
+type MediaOpts struct {
+	// The size at which photos should be rendered.
+	// If unset, the default is PhotoLarge.
+	PhotoSize PhotoSize
+}
+
+func (o *MediaOpts) photoSize() PhotoSize {
+	if o == nil || o.PhotoSize == "" {
+		return PhotoLarge
+	}
+	return o.PhotoSize
+}
+
+// MediaItems returns descriptors for the media attached to tweet t, in the
+// order they are attached, resolved against the media included in r. Media
+// keys that are not included in r are skipped.
+//
+// To populate the includes, the query must request the "attachments.media_keys"
+// expansion, along with the "url" and "variants" media fields.
+func (r *Reply) MediaItems(t *types.Tweet, opts *MediaOpts) ([]*MediaItem, error) {
+	media, err := r.AttachedMedia(t)
+	if err != nil {
+		return nil, err
+	}
+	var out []*MediaItem
+	for _, m := range media {
+		item := &MediaItem{
+			Key:        m.Key,
+			Type:       m.Type,
+			PreviewURL: m.PreviewImageURL,
+			Width:      m.Width,
+			Height:     m.Height,
+			Duration:   m.Duration,
+			Media:      m,
+		}
+		if m.Type == "photo" {
+			item.URL = photoURL(m.URL, opts.photoSize())
+		} else if v := m.BestVariant("video/mp4"); v != nil {
+			item.URL = v.URL
+			item.ContentType = v.ContentType
+			item.BitRate = v.BitRate
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+// photoURL returns the URL for the photo at base rendered at the given size.
+// The media server reports photo URLs of the form ".../media/<id>.<format>",
+// and serves each size as ".../media/<id>?format=<format>&name=<size>".
+func photoURL(base string, size PhotoSize) string {
+	if base == "" || strings.Contains(base, "?") {
+		return base
+	}
+	slash := strings.LastIndex(base, "/")
+	if dot := strings.LastIndex(base, "."); dot > slash {
+		return base[:dot] + "?format=" + base[dot+1:] + "&name=" + string(size)
+	}
+	return base + "?name=" + string(size)
+}
@@ -0,0 +1,58 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package tweets_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/tweets"
+	"github.com/928799934/twitter/types"
+)
+
+func TestMediaItems(t *testing.T) {
+	rsp := &tweets.Reply{
+		Reply: &twitter.Reply{
+			Includes: map[string]json.RawMessage{
+				"media": json.RawMessage(`[
+{"media_key":"3_1","type":"photo","url":"https://pbs.twimg.com/media/abc.jpg","width":800,"height":600},
+{"media_key":"7_2","type":"video","preview_image_url":"https://pbs.twimg.com/preview.jpg","duration_ms":5000,
+ "variants":[
+   {"content_type":"application/x-mpegURL","url":"https://video.twimg.com/pl.m3u8"},
+   {"bit_rate":256000,"content_type":"video/mp4","url":"https://video.twimg.com/low.mp4"},
+   {"bit_rate":2176000,"content_type":"video/mp4","url":"https://video.twimg.com/high.mp4"},
+   {"bit_rate":832000,"content_type":"video/mp4","url":"https://video.twimg.com/mid.mp4"}
+ ]}
+]`),
+			},
+		},
+	}
+	tweet := &types.Tweet{
+		ID:          "1",
+		Attachments: types.Attachments{"media_keys": {"7_2", "9_9", "3_1"}},
+	}
+
+	items, err := rsp.MediaItems(tweet, &tweets.MediaOpts{PhotoSize: tweets.PhotoSmall})
+	if err != nil {
+		t.Fatalf("MediaItems failed: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("MediaItems: got %d items, want 2", len(items))
+	}
+
+	if v := items[0]; v.Key != "7_2" || v.URL != "https://video.twimg.com/high.mp4" || v.BitRate != 2176000 {
+		t.Errorf("Video: got key %q url %q rate %d", v.Key, v.URL, v.BitRate)
+	} else if v.PreviewURL == "" || time.Duration(v.Duration) != 5*time.Second {
+		t.Errorf("Video: got preview %q duration %v", v.PreviewURL, v.Duration)
+	}
+	if p := items[1]; p.Key != "3_1" || p.URL != "https://pbs.twimg.com/media/abc?format=jpg&name=small" {
+		t.Errorf("Photo: got key %q url %q", p.Key, p.URL)
+	}
+
+	none, err := rsp.MediaItems(&types.Tweet{ID: "2"}, nil)
+	if err != nil || len(none) != 0 {
+		t.Errorf("MediaItems (none): got %v, %v; want empty, nil", none, err)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package tweets
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/jape"
+	"github.com/928799934/twitter/types"
+)
+
+// Poll runs a recent search for query repeatedly, and delivers the tweets
+// posted since the previous search to f, until f stops the poll or ctx ends.
+// This is an alternative to a filtered stream for callers without stream
+// access.
+//
+// Each search passes the ID of the newest tweet seen so far as since_id, so
+// only new tweets are delivered. Within a search, tweets are delivered oldest
+// first, one page of results per reply. Searches that find nothing new are
+// not reported to f.
+//
+//	err := tweets.Poll(ctx, cli, "cat has:images", func(rsp *tweets.Reply) error {
+//	   process(rsp.Tweets)
+//	   return nil
+//	}, &tweets.PollOpts{Interval: time.Minute})
+//
+// If f returns an error, the poll ends. If the error is not
+// jape.ErrStopStreaming, that error is reported to the caller. Otherwise Poll
+// runs until ctx ends, and reports the error from the context.
+//
+// API: 2/tweets/search/recent
+func Poll(ctx context.Context, cli *twitter.Client, query string, f Callback, opts *PollOpts) error {
+	sinceID := opts.sinceID()
+	var start time.Time
+	if sinceID == "" {
+		start = (*jape.Client)(cli).Now().UTC()
+	}
+	for {
+		newest, err := pollOnce(ctx, cli, query, f, &SearchOpts{
+			StartTime:  start,
+			SinceID:    sinceID,
+			MaxResults: 100,
+			Optional:   opts.optional(),
+		})
+		if errors.Is(err, jape.ErrStopStreaming) {
+			return nil
+		} else if ctx.Err() != nil {
+			return ctx.Err()
+		} else if err != nil {
+			return err
+		}
+		if newest != "" {
+			sinceID, start = newest, time.Time{}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-(*jape.Client)(cli).After(opts.interval()):
+		}
+	}
+}
+
+// PollOpts provides parameters for Poll. A nil *PollOpts provides empty
+// values for all fields.
+type PollOpts struct {
+	// The interval between searches. If zero, use DefaultPollInterval.
+	Interval time.Duration
+
+	// If set, deliver only tweets newer than this ID. Otherwise, only tweets
+	// posted after Poll begins are delivered.
+	SinceID string
+
+	// Optional response fields and expansions.
+	Optional []types.Fields
+}
+
+func (o *PollOpts) interval() time.Duration {
+	if o == nil || o.Interval <= 0 {
+		return DefaultPollInterval
+	}
+	return o.Interval
+}
+
+func (o *PollOpts) sinceID() string {
+	if o == nil {
+		return ""
+	}
+	return o.SinceID
+}
+
+func (o *PollOpts) optional() []types.Fields {
+	if o == nil {
+		return nil
+	}
+	return o.Optional
+}
+
+// pollOnce fetches all the pages of a search for query with the given options,
+// and delivers them to f oldest first. It returns the ID of the newest tweet
+// found, or "" if there were none.
+func pollOnce(ctx context.Context, cli *twitter.Client, query string, f Callback, opts *SearchOpts) (string, error) {
+	q := SearchRecent(query, opts)
+	q.NoCache = true // each poll must see new results
+	var pages []*Reply
+	var newest string
+	for q.HasMorePages() {
+		rsp, err := q.Invoke(ctx, cli)
+		if err != nil {
+			return "", err
+		}
+		if len(rsp.Tweets) == 0 {
+			continue
+		}
+		pages = append(pages, rsp)
+		id := rsp.Tweets[0].ID // results are ordered newest first
+		if rsp.Meta != nil && rsp.Meta.NewestID != "" {
+			id = rsp.Meta.NewestID
+		}
+		if idLess(newest, id) {
+			newest = id
+		}
+	}
+
+	// Search results are reported newest first; deliver them oldest first.
+	for i := len(pages) - 1; i >= 0; i-- {
+		tws := pages[i].Tweets
+		for j, k := 0, len(tws)-1; j < k; j, k = j+1, k-1 {
+			tws[j], tws[k] = tws[k], tws[j]
+		}
+		if err := f(pages[i]); err != nil {
+			return newest, err
+		}
+	}
+	return newest, nil
+}
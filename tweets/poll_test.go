@@ -0,0 +1,59 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package tweets_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/928799934/twitter/internal/otest"
+	"github.com/928799934/twitter/jape"
+	"github.com/928799934/twitter/tweets"
+)
+
+func TestPoll(t *testing.T) {
+	var since []string // since_id for each poll
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("next_token") == "p2" {
+			io.WriteString(w, `{"data":[{"id":"2"}],"meta":{"newest_id":"2"}}`)
+			return
+		}
+		since = append(since, q.Get("since_id"))
+		if hasStart := q.Get("start_time") != ""; hasStart != (q.Get("since_id") == "") {
+			t.Errorf("Poll %d: got start_time %q with since_id %q", len(since), q.Get("start_time"), q.Get("since_id"))
+		}
+		switch len(since) {
+		case 1:
+			io.WriteString(w, `{"meta":{"result_count":0}}`)
+		case 2:
+			io.WriteString(w, `{"data":[{"id":"4"},{"id":"3"}],"meta":{"newest_id":"4","next_token":"p2"}}`)
+		default:
+			io.WriteString(w, `{"data":[{"id":"5"}],"meta":{"newest_id":"5"}}`)
+		}
+	}))
+
+	var got []string
+	err := tweets.Poll(context.Background(), cli, "cats", func(rsp *tweets.Reply) error {
+		for _, tw := range rsp.Tweets {
+			got = append(got, tw.ID)
+		}
+		if len(got) == 4 {
+			return jape.ErrStopStreaming
+		}
+		return nil
+	}, &tweets.PollOpts{Interval: time.Millisecond})
+	if err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+	if want := "2 3 4 5"; strings.Join(got, " ") != want {
+		t.Errorf("Poll: got %q, want %q", got, want)
+	}
+	if want := "  4"; strings.Join(since, " ") != want {
+		t.Errorf("Poll since_id: got %q, want %q", since, want)
+	}
+}
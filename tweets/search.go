@@ -3,6 +3,7 @@
 package tweets
 
 import (
+	"fmt"
 	"strconv"
 	"time"
 
@@ -18,15 +19,35 @@ import (
 //
 // API: 2/tweets/search/recent
 func SearchRecent(query string, opts *SearchOpts) Query {
+	return newSearch("2/tweets/search/recent", query, opts)
+}
+
+// SearchAll conducts a search query on the full archive of tweets matching
+// the specified query filter. The query syntax and options are the same as
+// for SearchRecent, but MaxResults may be up to 500. This endpoint requires
+// Academic Research access.
+//
+// API: 2/tweets/search/all
+func SearchAll(query string, opts *SearchOpts) Query {
+	return newSearch("2/tweets/search/all", query, opts)
+}
+
+func newSearch(method, query string, opts *SearchOpts) Query {
 	req := &jape.Request{
-		Method: "2/tweets/search/recent",
+		Method: method,
 		Params: make(jape.Params),
 	}
 	req.Params.Set("query", query)
 	opts.addRequestParams(req)
-	return Query{Request: req}
+	return Query{Request: req, encodeErr: opts.check()}
 }
 
+// Sort orders for search results (see SearchOpts).
+const (
+	SortRecency   = "recency"   // newest first (the default)
+	SortRelevancy = "relevancy" // most relevant first
+)
+
 // SearchOpts provides parameters for tweet search. A nil *SearchOpts provides
 // empty or zero values for all fields.
 type SearchOpts struct {
@@ -40,7 +61,7 @@ type SearchOpts struct {
 	EndTime time.Time
 
 	// The maximum number of results to return; 0 means let the server choose.
-	// Non-zero values < 10 or > 100 are invalid.
+	// Non-zero values < 10 or > 100 (500 for SearchAll) are invalid.
 	MaxResults int
 
 	// If set, return results with IDs greater than this (exclusive).
@@ -49,10 +70,26 @@ type SearchOpts struct {
 	// If set, return results with IDs smaller than this (exclusive).
 	UntilID string
 
+	// The order of results: SortRecency or SortRelevancy. If empty, the server
+	// uses SortRecency.
+	SortOrder string
+
 	// Optional response fields and expansions
 	Optional []types.Fields
 }
 
+// check reports an error if o has invalid settings.
+func (o *SearchOpts) check() error {
+	if o == nil {
+		return nil
+	}
+	switch o.SortOrder {
+	case "", SortRecency, SortRelevancy:
+		return nil
+	}
+	return fmt.Errorf("invalid sort order %q", o.SortOrder)
+}
+
 func (o *SearchOpts) addRequestParams(req *jape.Request) {
 	if o == nil {
 		return // nothing to do
@@ -75,6 +112,9 @@ func (o *SearchOpts) addRequestParams(req *jape.Request) {
 	if o.UntilID != "" {
 		req.Params.Set("until_id", o.UntilID)
 	}
+	if o.SortOrder != "" {
+		req.Params.Set("sort_order", o.SortOrder)
+	}
 	for _, fs := range o.Optional {
 		if vs := fs.Values(); len(vs) != 0 {
 			req.Params.Add(fs.Label(), vs...)
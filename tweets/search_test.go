@@ -0,0 +1,66 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package tweets_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/928799934/twitter/internal/otest"
+	"github.com/928799934/twitter/tweets"
+)
+
+func TestSearchAll(t *testing.T) {
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2/tweets/search/all" {
+			t.Errorf("Unexpected request path %q", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if got := q.Get("max_results"); got != "500" {
+			t.Errorf("Parameter max_results: got %q, want 500", got)
+		}
+		switch q.Get("next_token") {
+		case "":
+			io.WriteString(w, `{"data":[{"id":"2"}],"meta":{"result_count":1,"next_token":"p2"}}`)
+		case "p2":
+			io.WriteString(w, `{"data":[{"id":"1"}],"meta":{"result_count":1}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	rsp, err := tweets.SearchAll("cats", &tweets.SearchOpts{
+		MaxResults: 500,
+		StartTime:  time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC),
+	}).InvokeAll(context.Background(), cli, 0)
+	if err != nil {
+		t.Fatalf("SearchAll failed: %v", err)
+	}
+	if len(rsp.Tweets) != 2 || rsp.Tweets[0].ID != "2" || rsp.Tweets[1].ID != "1" {
+		t.Errorf("SearchAll: got %+v, want tweets 2, 1", rsp.Tweets)
+	}
+}
+
+func TestSearchSortOrder(t *testing.T) {
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("sort_order"); got != "relevancy" {
+			t.Errorf("Parameter sort_order: got %q, want relevancy", got)
+		}
+		io.WriteString(w, `{"data":[{"id":"1"}],"meta":{"result_count":1}}`)
+	}))
+	ctx := context.Background()
+
+	if _, err := tweets.SearchRecent("cats", &tweets.SearchOpts{
+		SortOrder: tweets.SortRelevancy,
+	}).Invoke(ctx, cli); err != nil {
+		t.Errorf("SearchRecent failed: %v", err)
+	}
+	if _, err := tweets.SearchRecent("cats", &tweets.SearchOpts{
+		SortOrder: "popularity",
+	}).Invoke(ctx, cli); err == nil {
+		t.Error("SearchRecent with an invalid sort order: got nil error, want error")
+	}
+}
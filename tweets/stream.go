@@ -5,13 +5,24 @@ package tweets
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
 
 	"github.com/928799934/twitter"
 	"github.com/928799934/twitter/jape"
+	"github.com/928799934/twitter/rules"
 	"github.com/928799934/twitter/types"
 )
 
 // SampleStream constructs a streaming sample query that delivers results to f.
+// The sample stream delivers a random sample of about 1% of all public tweets
+// in real time.
+//
+//	err := tweets.SampleStream(func(rsp *tweets.Reply) error {
+//	   process(rsp.Tweets)
+//	   return nil
+//	}, &tweets.StreamOpts{MaxResults: 100}).Invoke(ctx, cli)
 //
 // API: 2/tweets/sample/stream
 func SampleStream(f Callback, opts *StreamOpts) Stream {
@@ -20,7 +31,22 @@ func SampleStream(f Callback, opts *StreamOpts) Stream {
 		Params: make(jape.Params),
 	}
 	opts.addRequestParams(req)
-	return Stream{Request: req, callback: f, maxResults: opts.maxResults()}
+	return newStream(req, f, opts)
+}
+
+// Sample10Stream constructs a streaming sample query for the 10% sample
+// stream that delivers results to f. This stream is partitioned, so opts must
+// set Partition, or the stream must be run with RunPartitions. This endpoint
+// requires Enterprise access.
+//
+// API: 2/tweets/sample10/stream
+func Sample10Stream(f Callback, opts *StreamOpts) Stream {
+	req := &jape.Request{
+		Method: "2/tweets/sample10/stream",
+		Params: make(jape.Params),
+	}
+	opts.addRequestParams(req)
+	return newStream(req, f, opts)
 }
 
 // SearchStream constructs a streaming search query that delivers results to f.
@@ -32,7 +58,32 @@ func SearchStream(f Callback, opts *StreamOpts) Stream {
 		Params: make(jape.Params),
 	}
 	opts.addRequestParams(req)
-	return Stream{Request: req, callback: f, maxResults: opts.maxResults()}
+	return newStream(req, f, opts)
+}
+
+func newStream(req *jape.Request, f Callback, opts *StreamOpts) Stream {
+	s := Stream{Request: req, callback: f, maxResults: opts.maxResults()}
+	if opts != nil {
+		s.gapFill = opts.GapFill
+		s.optional = opts.Optional
+	}
+	return s
+}
+
+// StreamWithRules updates the streaming search rules on the server to match
+// ruleSet (see rules.Sync), and then runs a streaming search query that
+// delivers results to f until the stream ends.
+//
+//	err := tweets.StreamWithRules(ctx, cli, rules.Adds{
+//	   {Query: "cat has:images", Tag: "cats"},
+//	}, handle, nil)
+//
+// API: 2/tweets/search/stream
+func StreamWithRules(ctx context.Context, cli *twitter.Client, ruleSet rules.Adds, f Callback, opts *StreamOpts) error {
+	if _, err := rules.Sync(ctx, cli, ruleSet); err != nil {
+		return err
+	}
+	return SearchStream(f, opts).Invoke(ctx, cli)
 }
 
 // A Stream performs a streaming search or sampling query.
@@ -40,6 +91,8 @@ type Stream struct {
 	*jape.Request
 	callback   Callback
 	maxResults int
+	gapFill    string
+	optional   []types.Fields
 }
 
 // StreamOpts provides parameters for tweet streaming. A nil *StreamOpts
@@ -50,6 +103,17 @@ type StreamOpts struct {
 
 	// Optional response fields and expansions.
 	Optional []types.Fields
+
+	// If non-empty, a managed stream (see Stream.Managed) runs a recent search
+	// with this query before each reconnect, to recover tweets posted since
+	// the latest tweet it delivered. Recovered tweets are delivered to the
+	// callback, oldest first, in replies with Backfilled set, and count
+	// toward MaxResults. For a filtered stream, see rules.SearchQuery.
+	GapFill string
+
+	// If positive, connect to this partition of a partitioned stream.
+	// Partitions are numbered from 1. See also Stream.RunPartitions.
+	Partition int
 }
 
 func (o *StreamOpts) addRequestParams(req *jape.Request) {
@@ -61,6 +125,9 @@ func (o *StreamOpts) addRequestParams(req *jape.Request) {
 			req.Params.Add(fs.Label(), vs...)
 		}
 	}
+	if o.Partition > 0 {
+		req.Params.Set("partition", strconv.Itoa(o.Partition))
+	}
 }
 
 func (o *StreamOpts) maxResults() int {
@@ -73,25 +140,204 @@ func (o *StreamOpts) maxResults() int {
 // A Callback receives streaming replies from a sample or streaming search
 // query. If the callback returns an error, the stream is terminated. If the
 // error is not jape.ErrStopStreaming, that error is reported to the caller.
+//
+// A system message from the server is delivered as a reply with no tweets,
+// whose System field is set (see twitter.Reply).
 type Callback func(*Reply) error
 
 // Invoke executes the streaming query on the given context and client.
 func (s Stream) Invoke(ctx context.Context, cli *twitter.Client) error {
-	var nr int
-	return cli.Stream(ctx, s.Request, func(rsp *twitter.Reply) error {
-		nr++
+	return cli.Stream(ctx, s.Request, s.handler(new(streamState)))
+}
+
+// Managed returns a managed stream for s, which automatically reconnects
+// after disconnects according to cfg (see twitter.ManagedStream). The
+// MaxResults option applies to the total number of results delivered across
+// all connections.
+//
+//	err := tweets.SearchStream(handle, opts).Managed(&twitter.StreamConfig{
+//	   BackfillMinutes: 2,
+//	}).Run(ctx, cli)
+func (s Stream) Managed(cfg *twitter.StreamConfig) *twitter.ManagedStream {
+	st := new(streamState)
+	if s.gapFill != "" {
+		var cp twitter.StreamConfig
+		if cfg != nil {
+			cp = *cfg
+		}
+		before := cp.BeforeReconnect
+		cp.BeforeReconnect = func(ctx context.Context, cli *twitter.Client) error {
+			if before != nil {
+				if err := before(ctx, cli); err != nil {
+					return err
+				}
+			}
+			return s.fillGap(ctx, cli, st)
+		}
+		cfg = &cp
+	}
+	return twitter.NewManagedStream(s.Request, s.handler(st), cfg)
+}
+
+// RunPartitions runs a managed stream (see Managed) for each of the partitions
+// 1 to n of s concurrently, and merges their results into a single logical
+// stream. The callback is not invoked concurrently, and MaxResults applies to
+// the merged stream. The Partition and GapFill options are ignored.
+//
+// Each partition is configured by cfg. Note that cfg.OnEvent may be called
+// concurrently by the partitions.
+//
+// If the callback stops the stream, or any partition ends with an error, the
+// remaining partitions are stopped. RunPartitions returns nil if the callback
+// stopped the stream; otherwise it returns the first error that ended a
+// partition.
+func (s Stream) RunPartitions(ctx context.Context, cli *twitter.Client, n int, cfg *twitter.StreamConfig) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	var stopped bool
+	h := s.handler(new(streamState))
+	merged := func(rsp *twitter.Reply) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if stopped {
+			return jape.ErrStopStreaming
+		}
+		err := h(rsp)
+		if errors.Is(err, jape.ErrStopStreaming) {
+			stopped = true
+			cancel()
+		}
+		return err
+	}
+
+	errc := make(chan error, n)
+	for i := 1; i <= n; i++ {
+		req := *s.Request
+		req.Params = make(jape.Params, len(s.Request.Params)+1)
+		for name, vals := range s.Request.Params {
+			req.Params[name] = vals
+		}
+		req.Params.Set("partition", strconv.Itoa(i))
+		ms := twitter.NewManagedStream(&req, merged, cfg)
+		go func() { errc <- ms.Run(ctx, cli) }()
+	}
+	var first error
+	for i := 0; i < n; i++ {
+		err := <-errc
+		if err == nil || first != nil {
+			continue
+		}
+		mu.Lock()
+		done := stopped
+		mu.Unlock()
+		if !done {
+			first = err
+			cancel()
+		}
+	}
+	return first
+}
+
+// streamState records the delivery state of a stream across connections.
+type streamState struct {
+	nr     int             // the number of results delivered
+	lastID string          // the ID of the latest tweet delivered
+	filled map[string]bool // IDs delivered by the latest gap-fill
+}
+
+// deliver reports tweet to the state, and reports whether it is new.
+func (st *streamState) deliver(tweet *types.Tweet) bool {
+	if st.filled[tweet.ID] {
+		return false
+	}
+	if idLess(st.lastID, tweet.ID) {
+		st.lastID = tweet.ID
+	}
+	return true
+}
+
+// handler returns a callback that decodes stream replies and delivers them to
+// the callback of s.
+func (s Stream) handler(st *streamState) twitter.Callback {
+	return func(rsp *twitter.Reply) error {
+		if rsp.System != nil {
+			return s.callback(&Reply{Reply: rsp}) // no tweet data
+		}
 		var tweet types.Tweet
 		if err := json.Unmarshal(rsp.Data, &tweet); err != nil {
 			return &jape.Error{Data: rsp.Data, Message: "decoding tweet data", Err: err}
 		}
-		if err := s.callback(&Reply{
+		if !st.deliver(&tweet) {
+			return nil // already delivered by a gap-fill
+		}
+		return s.send(st, &Reply{
 			Reply:  rsp,
 			Tweets: types.Tweets{&tweet},
-		}); err != nil {
+		})
+	}
+}
+
+// send delivers rsp to the callback of s, and reports jape.ErrStopStreaming
+// once the result limit is reached.
+func (s Stream) send(st *streamState, rsp *Reply) error {
+	st.nr++
+	if err := s.callback(rsp); err != nil {
+		return err
+	} else if s.maxResults > 0 && st.nr >= s.maxResults {
+		return jape.ErrStopStreaming
+	}
+	return nil
+}
+
+// fillGap searches for tweets matching the gap-fill query of s posted after
+// the latest tweet delivered, and delivers them to the callback.
+func (s Stream) fillGap(ctx context.Context, cli *twitter.Client, st *streamState) error {
+	if st.lastID == "" {
+		return nil // nothing delivered yet, so there is no known gap
+	}
+	q := SearchRecent(s.gapFill, &SearchOpts{
+		SinceID:    st.lastID,
+		MaxResults: 100,
+		Optional:   s.optional,
+	})
+	var pages []*Reply
+	for q.HasMorePages() {
+		rsp, err := q.Invoke(ctx, cli)
+		if err != nil {
 			return err
-		} else if s.maxResults > 0 && nr == s.maxResults {
-			return jape.ErrStopStreaming
 		}
-		return nil
-	})
+		pages = append(pages, rsp)
+	}
+
+	// Search results are reported newest first; deliver them oldest first.
+	st.filled = make(map[string]bool)
+	for i := len(pages) - 1; i >= 0; i-- {
+		for j := len(pages[i].Tweets) - 1; j >= 0; j-- {
+			tweet := pages[i].Tweets[j]
+			if !st.deliver(tweet) {
+				continue
+			}
+			st.filled[tweet.ID] = true
+			err := s.send(st, &Reply{
+				Reply:      pages[i].Reply,
+				Tweets:     types.Tweets{tweet},
+				Backfilled: true,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// idLess reports whether tweet ID a precedes b. Tweet IDs are decimal
+// integers assigned in roughly chronological order.
+func idLess(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
 }
@@ -0,0 +1,204 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package tweets_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/928799934/twitter/internal/otest"
+	"github.com/928799934/twitter/rules"
+	"github.com/928799934/twitter/tweets"
+	"github.com/928799934/twitter/types"
+)
+
+func TestSampleStream(t *testing.T) {
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2/tweets/sample/stream" {
+			t.Errorf("Unexpected request path %q", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		q := r.URL.Query()
+		if got := q.Get("tweet.fields"); got != "author_id" {
+			t.Errorf("tweet.fields: got %q, want author_id", got)
+		}
+		if got := q.Get("expansions"); got != "author_id" {
+			t.Errorf("expansions: got %q, want author_id", got)
+		}
+		for _, id := range []string{"1", "2", "3"} {
+			io.WriteString(w, `{"data":{"id":"`+id+`","text":"t`+id+`","author_id":"u`+id+`"},`+
+				`"includes":{"users":[{"id":"u`+id+`","username":"user`+id+`"}]}}`+"\r\n")
+		}
+	}))
+
+	var tweetIDs, userNames []string
+	err := tweets.SampleStream(func(rsp *tweets.Reply) error {
+		for _, tw := range rsp.Tweets {
+			tweetIDs = append(tweetIDs, tw.ID+"/"+tw.AuthorID)
+		}
+		users, err := rsp.IncludedUsers()
+		if err != nil {
+			return err
+		}
+		for _, u := range users {
+			userNames = append(userNames, u.Username)
+		}
+		return nil
+	}, &tweets.StreamOpts{
+		MaxResults: 2,
+		Optional: []types.Fields{
+			types.TweetFields{AuthorID: true},
+			types.Expansions{AuthorID: true},
+		},
+	}).Invoke(context.Background(), cli)
+	if err != nil {
+		t.Fatalf("SampleStream failed: %v", err)
+	}
+	if len(tweetIDs) != 2 || tweetIDs[0] != "1/u1" || tweetIDs[1] != "2/u2" {
+		t.Errorf("Tweets: got %q, want [1/u1 2/u2]", tweetIDs)
+	}
+	if len(userNames) != 2 || userNames[0] != "user1" || userNames[1] != "user2" {
+		t.Errorf("Users: got %q, want [user1 user2]", userNames)
+	}
+}
+
+func TestGapFill(t *testing.T) {
+	var conns int
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2/tweets/search/stream":
+			conns++
+			if conns == 1 {
+				io.WriteString(w, `{"data":{"id":"100","text":"before"}}`+"\r\n")
+			} else {
+				// The first message duplicates a tweet recovered by the gap-fill.
+				io.WriteString(w, `{"data":{"id":"103","text":"during"}}`+"\r\n")
+				io.WriteString(w, `{"data":{"id":"104","text":"after"}}`+"\r\n")
+			}
+		case "/2/tweets/search/recent":
+			q := r.URL.Query()
+			if got := q.Get("query"); got != "cats" {
+				t.Errorf("Gap-fill query: got %q, want cats", got)
+			}
+			if got := q.Get("since_id"); got != "100" {
+				t.Errorf("Gap-fill since_id: got %q, want 100", got)
+			}
+			io.WriteString(w, `{"data":[{"id":"103","text":"during"},{"id":"102","text":"gap"}],`+
+				`"meta":{"result_count":2}}`)
+		default:
+			t.Errorf("Unexpected request path %q", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+
+	var got []string
+	err := tweets.SearchStream(func(rsp *tweets.Reply) error {
+		for _, tw := range rsp.Tweets {
+			if rsp.Backfilled {
+				got = append(got, tw.ID+"*")
+			} else {
+				got = append(got, tw.ID)
+			}
+		}
+		return nil
+	}, &tweets.StreamOpts{MaxResults: 4, GapFill: "cats"}).Managed(nil).Run(context.Background(), cli)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if want := "100 102* 103* 104"; strings.Join(got, " ") != want {
+		t.Errorf("Tweets: got %q, want %q", got, want)
+	}
+}
+
+func TestRunPartitions(t *testing.T) {
+	var mu sync.Mutex
+	seen := make(map[string]int)
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2/tweets/sample10/stream" {
+			t.Errorf("Unexpected request path %q", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		p := r.URL.Query().Get("partition")
+		mu.Lock()
+		seen[p]++
+		first := seen[p] == 1
+		mu.Unlock()
+		if first {
+			io.WriteString(w, `{"data":{"id":"`+p+`","text":"partition `+p+`"}}`+"\r\n")
+			return
+		}
+		<-r.Context().Done() // idle after reconnecting
+	}))
+
+	var got []string
+	err := tweets.Sample10Stream(func(rsp *tweets.Reply) error {
+		for _, tw := range rsp.Tweets {
+			got = append(got, tw.ID)
+		}
+		return nil
+	}, &tweets.StreamOpts{MaxResults: 3}).RunPartitions(context.Background(), cli, 3, nil)
+	if err != nil {
+		t.Fatalf("RunPartitions failed: %v", err)
+	}
+	sort.Strings(got)
+	if want := "1 2 3"; strings.Join(got, " ") != want {
+		t.Errorf("Tweets: got %q, want %q", got, want)
+	}
+}
+
+func TestStreamWithRules(t *testing.T) {
+	var updates []string
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2/tweets/search/stream/rules":
+			if r.Method == "GET" {
+				io.WriteString(w, `{"data":[{"id":"1","value":"dogs"},{"id":"2","value":"cats","tag":"c"}],`+
+					`"meta":{"sent":"2022-03-14T12:00:00.000Z"}}`)
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			updates = append(updates, string(body))
+			if strings.Contains(string(body), `"add"`) {
+				io.WriteString(w, `{"data":[{"id":"3","value":"birds"}],"meta":{"sent":"2022-03-14T12:00:00.000Z"}}`)
+			} else {
+				io.WriteString(w, `{"meta":{"sent":"2022-03-14T12:00:00.000Z"}}`)
+			}
+		case "/2/tweets/search/stream":
+			if len(updates) != 2 {
+				t.Error("Stream opened before the rules were updated")
+			}
+			io.WriteString(w, `{"data":{"id":"10","text":"a bird"},"matching_rules":[{"id":"3"}]}`+"\r\n")
+		default:
+			t.Errorf("Unexpected request path %q", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+
+	var got []string
+	err := tweets.StreamWithRules(context.Background(), cli, rules.Adds{
+		{Query: "cats", Tag: "c"},
+		{Query: "birds"},
+	}, func(rsp *tweets.Reply) error {
+		for _, tw := range rsp.Tweets {
+			got = append(got, tw.ID)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatalf("StreamWithRules failed: %v", err)
+	}
+	want := []string{`{"add":[{"value":"birds"}]}`, `{"delete":{"ids":["1"]}}`}
+	if strings.Join(updates, " ") != strings.Join(want, " ") {
+		t.Errorf("Rule updates: got %q, want %q", updates, want)
+	}
+	if len(got) != 1 || got[0] != "10" {
+		t.Errorf("Tweets: got %q, want [10]", got)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package tweets
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/types"
+)
+
+// A Thread is the conversation containing a tweet, arranged as a tree of
+// replies rooted at the tweet that began the conversation.
+type Thread struct {
+	// The tweet that began the conversation.
+	Root *ThreadNode
+
+	// Replies whose parent tweet is not part of the thread as fetched, for
+	// example because the parent was deleted, or is too old for the search.
+	Orphans []*ThreadNode
+
+	// All the tweets in the thread, indexed by ID.
+	Tweets map[string]*ThreadNode
+}
+
+// A ThreadNode is a single tweet in a Thread.
+type ThreadNode struct {
+	*types.Tweet
+
+	Parent  *ThreadNode   // the tweet this replies to, or nil
+	Replies []*ThreadNode // replies to this tweet, oldest first
+}
+
+// ThreadOpts provides parameters for FetchThread. A nil *ThreadOpts provides
+// empty values for all fields.
+type ThreadOpts struct {
+	// If true, search the full archive for replies (see SearchAll).
+	// Otherwise, only replies from the past week are found.
+	FullArchive bool
+
+	// Optional response fields and expansions. The conversation and
+	// referenced tweet fields are always requested.
+	Optional []types.Fields
+}
+
+func (o *ThreadOpts) optional() []types.Fields {
+	if o == nil {
+		return nil
+	}
+	return o.Optional
+}
+
+// threadFields are the tweet fields FetchThread needs to assemble a thread.
+var threadFields = types.TweetFields{ConversationID: true, Referenced: true}
+
+// FetchThread fetches the conversation containing the given tweet ID, and
+// assembles its tweets into a thread using the replied-to tweet of each.
+// It looks up the tweet to find its conversation, then pages through a
+// search for the tweets of that conversation.
+//
+//	thread, err := tweets.FetchThread(ctx, cli, tweetID, nil)
+//	...
+//	for _, reply := range thread.Root.Replies {
+//	   process(reply.Tweet)
+//	}
+//
+// API: 2/tweets, 2/tweets/search/recent or 2/tweets/search/all
+func FetchThread(ctx context.Context, cli *twitter.Client, tweetID string, opts *ThreadOpts) (*Thread, error) {
+	optional := append([]types.Fields{threadFields}, opts.optional()...)
+	rsp, err := Lookup(tweetID, &LookupOpts{Optional: optional}).Invoke(ctx, cli)
+	if err != nil {
+		return nil, err
+	} else if len(rsp.Tweets) == 0 {
+		return nil, fmt.Errorf("tweet %q not found", tweetID)
+	}
+	tweet := rsp.Tweets[0]
+	convID := tweet.ConversationID
+	if convID == "" {
+		convID = tweet.ID
+	}
+
+	all := types.Tweets{tweet}
+	if convID != tweet.ID {
+		rsp, err := Lookup(convID, &LookupOpts{Optional: optional}).Invoke(ctx, cli)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, rsp.Tweets...) // empty if the root is not available
+	}
+
+	search := SearchRecent
+	if opts != nil && opts.FullArchive {
+		search = SearchAll
+	}
+	q := search("conversation_id:"+convID, &SearchOpts{
+		MaxResults: 100,
+		Optional:   optional,
+	})
+	for q.HasMorePages() {
+		rsp, err := q.Invoke(ctx, cli)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, rsp.Tweets...)
+	}
+	return newThread(convID, all), nil
+}
+
+// newThread assembles the given tweets of conversation convID into a thread.
+func newThread(convID string, tweets types.Tweets) *Thread {
+	t := &Thread{Tweets: make(map[string]*ThreadNode)}
+	var ids []string
+	for _, tw := range tweets {
+		if _, ok := t.Tweets[tw.ID]; !ok {
+			t.Tweets[tw.ID] = &ThreadNode{Tweet: tw}
+			ids = append(ids, tw.ID)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return idLess(ids[i], ids[j]) })
+
+	t.Root = t.Tweets[convID]
+	for _, id := range ids {
+		node := t.Tweets[id]
+		if node == t.Root {
+			continue
+		}
+		if parent := t.Tweets[repliedTo(node.Tweet)]; parent != nil {
+			node.Parent = parent
+			parent.Replies = append(parent.Replies, node)
+		} else {
+			t.Orphans = append(t.Orphans, node)
+		}
+	}
+	return t
+}
+
+// repliedTo returns the ID of the tweet that tw replies to, or "".
+func repliedTo(tw *types.Tweet) string {
+	for _, ref := range tw.Referenced {
+		if ref.Type == "replied_to" {
+			return ref.ID
+		}
+	}
+	return ""
+}
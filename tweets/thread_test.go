@@ -0,0 +1,82 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package tweets_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/928799934/twitter/internal/otest"
+	"github.com/928799934/twitter/tweets"
+)
+
+func TestFetchThread(t *testing.T) {
+	reply := func(id, parent string) string {
+		return `{"id":"` + id + `","conversation_id":"1","referenced_tweets":[{"type":"replied_to","id":"` + parent + `"}]}`
+	}
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if fs := q.Get("tweet.fields"); !strings.Contains(fs, "conversation_id") || !strings.Contains(fs, "referenced_tweets") {
+			t.Errorf("Missing tweet fields: %q", fs)
+		}
+		switch r.URL.Path {
+		case "/2/tweets":
+			switch q.Get("ids") {
+			case "3":
+				io.WriteString(w, `{"data":[`+reply("3", "2")+`]}`)
+			case "1":
+				io.WriteString(w, `{"data":[{"id":"1","conversation_id":"1"}]}`)
+			default:
+				io.WriteString(w, `{"errors":[{"title":"Not Found Error","resource_id":"`+q.Get("ids")+`"}]}`)
+			}
+		case "/2/tweets/search/recent":
+			if got := q.Get("query"); got != "conversation_id:1" {
+				t.Errorf("Search query: got %q, want conversation_id:1", got)
+			}
+			if q.Get("next_token") == "" {
+				io.WriteString(w, `{"data":[`+reply("5", "9")+`,`+reply("4", "2")+`],"meta":{"next_token":"p2"}}`)
+			} else {
+				io.WriteString(w, `{"data":[`+reply("3", "2")+`,`+reply("2", "1")+`]}`)
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	thread, err := tweets.FetchThread(context.Background(), cli, "3", nil)
+	if err != nil {
+		t.Fatalf("FetchThread failed: %v", err)
+	}
+	if thread.Root == nil || thread.Root.ID != "1" {
+		t.Fatalf("Root: got %+v, want tweet 1", thread.Root)
+	}
+	if n := len(thread.Tweets); n != 5 {
+		t.Errorf("Thread has %d tweets, want 5", n)
+	}
+
+	// Render the tree as nested parentheses to check its shape.
+	var render func(*tweets.ThreadNode) string
+	render = func(n *tweets.ThreadNode) string {
+		s := n.ID
+		for _, r := range n.Replies {
+			if r.Parent != n {
+				t.Errorf("Tweet %s: parent is %v, want %s", r.ID, r.Parent, n.ID)
+			}
+			s += "(" + render(r) + ")"
+		}
+		return s
+	}
+	if got, want := render(thread.Root), "1(2(3)(4))"; got != want {
+		t.Errorf("Thread: got %s, want %s", got, want)
+	}
+	if len(thread.Orphans) != 1 || thread.Orphans[0].ID != "5" {
+		t.Errorf("Orphans: got %+v, want tweet 5", thread.Orphans)
+	}
+
+	if _, err := tweets.FetchThread(context.Background(), cli, "404", nil); err == nil {
+		t.Error("FetchThread for a missing tweet: got nil error, want error")
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package tweets_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/928799934/twitter/internal/otest"
+	"github.com/928799934/twitter/tweets"
+)
+
+func TestFromUser(t *testing.T) {
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2/users/12345/tweets" {
+			t.Errorf("Unexpected request path %q", r.URL.Path)
+		}
+		q := r.URL.Query()
+		for key, want := range map[string]string{
+			"exclude":          "replies,retweets",
+			"start_time":       "2022-03-01T00:00:00Z",
+			"since_id":         "100",
+			"max_results":      "5",
+			"pagination_token": "p1",
+		} {
+			if got := q.Get(key); got != want {
+				t.Errorf("Parameter %q: got %q, want %q", key, got, want)
+			}
+		}
+		io.WriteString(w, `{"data":[{"id":"102"},{"id":"101"}],`+
+			`"meta":{"result_count":2,"newest_id":"102","oldest_id":"101","next_token":"p2"}}`)
+	}))
+
+	q := tweets.FromUser("12345", &tweets.ListOpts{
+		PageToken:  "p1",
+		MaxResults: 5,
+		Exclude:    []string{"replies", "retweets"},
+		StartTime:  time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC),
+		SinceID:    "100",
+	})
+	rsp, err := q.Invoke(context.Background(), cli)
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	if len(rsp.Tweets) != 2 || rsp.Meta.NewestID != "102" || rsp.Meta.OldestID != "101" {
+		t.Errorf("FromUser: got %+v, meta %+v", rsp.Tweets, rsp.Meta)
+	}
+	if got := q.PageToken(); got != "p2" {
+		t.Errorf("Page token: got %q, want p2", got)
+	}
+}
+
+func TestMentioningUser(t *testing.T) {
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2/users/12345/mentions" {
+			t.Errorf("Unexpected request path %q", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("until_id"); got != "200" {
+			t.Errorf("Parameter until_id: got %q, want 200", got)
+		}
+		io.WriteString(w, `{"data":[{"id":"150","text":"@you hi"}],"meta":{"result_count":1}}`)
+	}))
+
+	q := tweets.MentioningUser("12345", &tweets.ListOpts{UntilID: "200"})
+	var got []string
+	for it := q.Iterate(cli); it.Next(context.Background()); {
+		got = append(got, it.Tweet().ID)
+	}
+	if len(got) != 1 || got[0] != "150" {
+		t.Errorf("MentioningUser: got %q, want [150]", got)
+	}
+}
+
+func TestQuotes(t *testing.T) {
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2/tweets/20/quote_tweets" {
+			t.Errorf("Unexpected request path %q", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("exclude"); got != "replies" {
+			t.Errorf("Parameter exclude: got %q, want replies", got)
+		}
+		io.WriteString(w, `{"data":[{"id":"30","text":"so true","author_id":"5"}],`+
+			`"includes":{"users":[{"id":"5","username":"quoter"}]},"meta":{"result_count":1}}`)
+	}))
+
+	rsp, err := tweets.Quotes("20", &tweets.ListOpts{
+		Exclude: []string{"replies"},
+	}).Invoke(context.Background(), cli)
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	if len(rsp.Tweets) != 1 || rsp.Tweets[0].ID != "30" {
+		t.Errorf("Quotes: got %+v, want tweet 30", rsp.Tweets)
+	}
+	users, err := rsp.IncludedUsers()
+	if err != nil || len(users) != 1 || users[0].Username != "quoter" {
+		t.Errorf("Included users: got %+v, %v", users, err)
+	}
+}
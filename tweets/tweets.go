@@ -86,12 +86,21 @@
 //	      types.MediaFields{PublicMetrics: true},
 //	   },
 //	}
+//
+// To wait for a single tweet matching the current stream rules, use
+// tweets.Await.
+//
+// # Posting
+//
+// To post a tweet, use tweets.Create or tweets.Quote. To delete a tweet, use
+// edit.DeleteTweet.
 package tweets
 
 import (
 	"context"
 	"encoding/json"
 	"strconv"
+	"time"
 
 	"github.com/928799934/twitter"
 	"github.com/928799934/twitter/jape"
@@ -101,6 +110,10 @@ import (
 // Lookup constructs a lookup query for one or more tweet IDs.  To look up
 // multiple IDs, add subsequent values the opts.More field.
 //
+// The API accepts at most twitter.MaxLookupIDs IDs per request. If more IDs
+// are given, the query issues multiple requests and merges their results
+// into a single reply (see twitter.Client.CallChunked).
+//
 // API: 2/tweets
 func Lookup(id string, opts *LookupOpts) Query {
 	req := &jape.Request{
@@ -109,7 +122,11 @@ func Lookup(id string, opts *LookupOpts) Query {
 	}
 	req.Params.Add("ids", id)
 	opts.addRequestParams(req)
-	return Query{Request: req}
+	q := Query{Request: req}
+	if opts != nil {
+		q.concurrency = opts.Concurrency
+	}
+	return q
 }
 
 // LikedBy constructs a query for the tweets liked by a given user.
@@ -124,7 +141,10 @@ func LikedBy(userID string, opts *ListOpts) Query {
 	return Query{Request: req}
 }
 
-// Quotes consstructs a query for the quotes of a given tweet ID.
+// Quotes constructs a query for the tweets quoting a given tweet ID.  Use the
+// Exclude option to omit replies or retweets from the results:
+//
+//	q := tweets.Quotes(id, &tweets.ListOpts{Exclude: []string{"replies"}})
 //
 // API: 2/tweets/:id/quote_tweets
 func Quotes(id string, opts *ListOpts) Query {
@@ -136,7 +156,9 @@ func Quotes(id string, opts *ListOpts) Query {
 	return Query{Request: req}
 }
 
-// MentioningUser constructs a query for tweets that mention the given user ID.
+// MentioningUser constructs a query for the timeline of tweets that mention
+// the given user ID, most recent first. Use the options to restrict the
+// timeline by time or ID range.
 //
 // API: 2/users/:id/mentions
 func MentioningUser(userID string, opts *ListOpts) Query {
@@ -148,7 +170,13 @@ func MentioningUser(userID string, opts *ListOpts) Query {
 	return Query{Request: req}
 }
 
-// FromUser constructs a query for tweets posted by the given user ID.
+// FromUser constructs a query for the timeline of tweets posted by the given
+// user ID, most recent first. Use the options to restrict the timeline by time
+// or ID range, or to exclude replies or retweets:
+//
+//	q := tweets.FromUser(userID, &tweets.ListOpts{
+//	   Exclude: []string{"replies", "retweets"},
+//	})
 //
 // API: 2/users/:id/tweets
 func FromUser(userID string, opts *ListOpts) Query {
@@ -175,13 +203,14 @@ func BookmarkedBy(userID string, opts *ListOpts) Query {
 // A Query performs a lookup or search query.
 type Query struct {
 	*jape.Request
-	encodeErr error
+	encodeErr   error
+	concurrency int // for chunked lookups
 }
 
 func (q Query) nextTokenParam() string {
-	// N.B. For some reason the "search recent" API uses a different pagination
-	// token parameter the rest of the API.
-	if q.Request.Method == "2/tweets/search/recent" {
+	// N.B. For some reason the search APIs use a different pagination token
+	// parameter the rest of the API.
+	if m := q.Request.Method; m == "2/tweets/search/recent" || m == "2/tweets/search/all" {
 		return "next_token"
 	}
 	return twitter.NextTokenParam
@@ -194,7 +223,13 @@ func (q Query) Invoke(ctx context.Context, cli *twitter.Client) (*Reply, error)
 	if q.encodeErr != nil {
 		return nil, q.encodeErr // deferred encoding error
 	}
-	rsp, err := cli.Call(ctx, q.Request)
+	var rsp *twitter.Reply
+	var err error
+	if q.Request.Method == "2/tweets" {
+		rsp, err = cli.CallChunked(ctx, q.Request, "ids", q.concurrency)
+	} else {
+		rsp, err = cli.Call(ctx, q.Request)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -225,6 +260,59 @@ func (q Query) Invoke(ctx context.Context, cli *twitter.Client) (*Reply, error)
 	return out, nil
 }
 
+// InvokeAll fetches the pages of the query until there are no more, or until
+// at least maxItems tweets have been reported, and returns a single reply
+// merging the results (see twitter.MergeReplies). If maxItems ≤ 0, all pages
+// are fetched. The merged reply contains at most maxItems tweets.
+//
+// The metadata of the merged reply summarize the pages fetched: NextToken is
+// the token of the page after the last fetched, and is empty if there are no
+// more pages. As with Invoke, q is updated in-place, so invoking the query
+// again will fetch the next page.
+func (q Query) InvokeAll(ctx context.Context, cli *twitter.Client, maxItems int) (*Reply, error) {
+	start := time.Now() // measured on the wall clock, not the client Clock
+	var rsps []*twitter.Reply
+	var tweets types.Tweets
+	meta := new(twitter.Pagination)
+	for q.HasMorePages() && (maxItems <= 0 || len(tweets) < maxItems) {
+		rsp, err := q.Invoke(ctx, cli)
+		if err != nil {
+			return nil, err
+		}
+		rsps = append(rsps, rsp.Reply)
+		tweets = append(tweets, rsp.Tweets...)
+		if m := rsp.Meta; m != nil {
+			if len(rsps) == 1 {
+				meta.PreviousToken = m.PreviousToken
+			}
+			meta.NextToken = m.NextToken
+			if idLess(meta.NewestID, m.NewestID) {
+				meta.NewestID = m.NewestID
+			}
+			if m.OldestID != "" && (meta.OldestID == "" || idLess(m.OldestID, meta.OldestID)) {
+				meta.OldestID = m.OldestID
+			}
+		}
+	}
+	merged, err := twitter.MergeReplies(rsps)
+	if err != nil {
+		return nil, err
+	}
+	merged.Info.Latency = time.Since(start)
+	if maxItems > 0 && len(tweets) > maxItems {
+		tweets = tweets[:maxItems]
+		var data []json.RawMessage
+		if err := json.Unmarshal(merged.Data, &data); err != nil {
+			return nil, &jape.Error{Data: merged.Data, Message: "decoding tweet data", Err: err}
+		}
+		if merged.Data, err = json.Marshal(data[:maxItems]); err != nil {
+			return nil, err
+		}
+	}
+	meta.ResultCount = len(tweets)
+	return &Reply{Reply: merged, Tweets: tweets, Meta: meta}, nil
+}
+
 // HasMorePages reports whether the query has more pages to fetch. This is true
 // for a freshly-constructed query, and for an invoked query where the server
 // has not reported a next-page token.
@@ -243,11 +331,73 @@ func (q Query) HasMorePages() bool {
 // invoking the query will then fetch the first page of results.
 func (q Query) ResetPageToken() { q.Request.Params.Reset(q.nextTokenParam()) }
 
+// PageToken returns the page token the query will send when it is next
+// invoked, or "" if the query is fresh or has no more pages.
+func (q Query) PageToken() string { return q.Request.Params.Get(q.nextTokenParam()) }
+
+// SetPageToken sets the page token the query will send when it is next
+// invoked. To page backward through a timeline, pass the PreviousToken from
+// the metadata of a reply:
+//
+//	q.SetPageToken(rsp.Meta.PreviousToken)
+//	prev, err := q.Invoke(ctx, cli)
+//
+// An empty token resets the query, as ResetPageToken does.
+func (q Query) SetPageToken(token string) {
+	if token == "" {
+		q.ResetPageToken()
+	} else {
+		q.Request.Params.Set(q.nextTokenParam(), token)
+	}
+}
+
+// Iterate returns an iterator over the tweets reported by q, which fetches
+// pages of results from cli as needed.
+//
+//	it := tweets.FromUser(userID, nil).Iterate(cli)
+//	for it.Next(ctx) {
+//	   process(it.Tweet())
+//	}
+func (q Query) Iterate(cli *twitter.Client) Iterator {
+	return Iterator{twitter.NewIterator[*Reply, *types.Tweet](cli, q, func(r *Reply) []*types.Tweet {
+		return r.Tweets
+	})}
+}
+
+// An Iterator yields the tweets reported by a paginated query.
+type Iterator struct {
+	*twitter.Iterator[*Reply, *types.Tweet]
+}
+
+// Tweet returns the current tweet. It is valid only after Next returns true.
+func (it Iterator) Tweet() *types.Tweet { return it.Item() }
+
 // A Reply is the response from a Query.
 type Reply struct {
 	*twitter.Reply
 	Tweets types.Tweets
 	Meta   *twitter.Pagination
+
+	// For a stream reply, this is true if the tweets were recovered by a
+	// gap-fill search after a reconnect, rather than delivered by the stream.
+	// See StreamOpts.
+	Backfilled bool
+}
+
+// WithContext returns the tweets of ts that have a context annotation with
+// the given domain and entity IDs, in their original order. An empty ID
+// matches any domain or entity (see types.Tweet.HasContext). The tweets must
+// have been fetched with the context_annotations field.
+//
+//	sports := tweets.WithContext(rsp.Tweets, "11", "") // domain 11 is "Sport"
+func WithContext(ts types.Tweets, domainID, entityID string) types.Tweets {
+	var out types.Tweets
+	for _, t := range ts {
+		if t.HasContext(domainID, entityID) {
+			out = append(out, t)
+		}
+	}
+	return out
 }
 
 // LookupOpts provides parameters for tweet lookup. A nil *LookupOpts provides
@@ -256,6 +406,10 @@ type LookupOpts struct {
 	More      []string       // additional tweet IDs to query
 	PageToken string         // a pagination token
 	Optional  []types.Fields // optional response fields, expansions
+
+	// If more than twitter.MaxLookupIDs IDs are given, issue up to this many
+	// requests concurrently. If zero, requests are issued one at a time.
+	Concurrency int
 }
 
 func (o *LookupOpts) addRequestParams(req *jape.Request) {
@@ -283,6 +437,26 @@ type ListOpts struct {
 	// The service will accept values up to 100.
 	MaxResults int
 
+	// Types of tweets to exclude from the results: "replies", "retweets".
+	// This is supported only by FromUser and Quotes.
+	Exclude []string
+
+	// The oldest UTC time from which results will be provided.
+	// This is supported only by FromUser and MentioningUser.
+	StartTime time.Time
+
+	// The latest (most recent) UTC time to which results will be provided.
+	// This is supported only by FromUser and MentioningUser.
+	EndTime time.Time
+
+	// If set, return results with IDs greater than this (exclusive).
+	// This is supported only by FromUser and MentioningUser.
+	SinceID string
+
+	// If set, return results with IDs smaller than this (exclusive).
+	// This is supported only by FromUser and MentioningUser.
+	UntilID string
+
 	// Optional response fields and expansions.
 	Optional []types.Fields
 }
@@ -297,6 +471,21 @@ func (o *ListOpts) addRequestParams(req *jape.Request) {
 	if o.MaxResults > 0 {
 		req.Params.Set("max_results", strconv.Itoa(o.MaxResults))
 	}
+	if len(o.Exclude) != 0 {
+		req.Params.Add("exclude", o.Exclude...)
+	}
+	if !o.StartTime.IsZero() {
+		req.Params.Set("start_time", o.StartTime.Format(types.DateFormat))
+	}
+	if !o.EndTime.IsZero() {
+		req.Params.Set("end_time", o.EndTime.Format(types.DateFormat))
+	}
+	if o.SinceID != "" {
+		req.Params.Set("since_id", o.SinceID)
+	}
+	if o.UntilID != "" {
+		req.Params.Set("until_id", o.UntilID)
+	}
 	for _, fs := range o.Optional {
 		if vs := fs.Values(); len(vs) != 0 {
 			req.Params.Add(fs.Label(), vs...)
@@ -34,13 +34,18 @@
 //
 // Queries to create, edit, delete, and show the contents of lists are defined
 // in package "lists".
+//
+// Queries to list, add, and remove bookmarks are defined in package
+// "bookmarks".
 package twitter
 
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/928799934/twitter/jape"
+	"github.com/928799934/twitter/types"
 )
 
 const (
@@ -51,11 +56,32 @@ const (
 	// NextTokenParam is the name of the query parameter used to send a page
 	// token to the service.
 	NextTokenParam = "pagination_token"
+
+	// Version is the current version of this library.
+	Version = "0.1.0"
+
+	// UserAgent is the default User-Agent string reported by the client.
+	// This is the default user agent if one is not given in the client.
+	UserAgent = "928799934-twitter/" + Version + " (+https://github.com/928799934/twitter)"
 )
 
+// AppUserAgent returns a User-Agent string that identifies the given
+// application component, followed by the default library UserAgent.
+// The app string should have the form "name/version", for example:
+//
+//	cli := twitter.NewClient(&jape.Client{
+//	   UserAgent: twitter.AppUserAgent("mybot/1.2"),
+//	})
+func AppUserAgent(app string) string {
+	if app == "" {
+		return UserAgent
+	}
+	return app + " " + UserAgent
+}
+
 // NewClient returns a new client for the Twitter API.
 // If cli == nil, default client options are used targeting the production API
-// at BaseURL.
+// at BaseURL. If cli does not set a user agent, UserAgent is used.
 func NewClient(cli *jape.Client) *Client {
 	if cli == nil {
 		cli = new(jape.Client)
@@ -63,6 +89,9 @@ func NewClient(cli *jape.Client) *Client {
 	if cli.BaseURL == "" {
 		cli.BaseURL = BaseURL
 	}
+	if cli.UserAgent == "" {
+		cli.UserAgent = UserAgent
+	}
 	return (*Client)(cli)
 }
 
@@ -77,33 +106,107 @@ type Callback func(*Reply) error
 // Call issues the specified API request and returns the decoded reply.
 // Errors from Call have concrete type *jape.Error.
 func (c *Client) Call(ctx context.Context, req *jape.Request) (*Reply, error) {
-	header, body, err := (*jape.Client)(c).Call(ctx, req)
+	jc := (*jape.Client)(c)
+	start := time.Now() // measured on the wall clock, not jc.Clock
+	header, body, err := jc.Call(ctx, req)
 	if err != nil {
-		return nil, err
+		return nil, checkError(err, jc.Now())
 	}
+	info := &CallInfo{Bytes: len(body), Latency: time.Since(start)}
 	var reply Reply
 	if err := json.Unmarshal(body, &reply); err != nil {
 		return nil, &jape.Error{Data: body, Message: "decoding response body", Err: err}
 	}
+	info.Decode = time.Since(start) - info.Latency
+	info.ServerTime = decodeResponseTime(header)
 	reply.RateLimit = decodeRateLimits(header)
+	reply.Info = info
 	return &reply, nil
 }
 
+// CallInto issues the specified API request and decodes the data field of
+// the reply into v, which must be a pointer. This is useful for callers that
+// want a custom shape for the results, or a type not covered by the types
+// package. The reply is also returned, for access to includes and metadata.
+// If the reply has no data, v is not modified.
+// Errors from CallInto have concrete type *jape.Error.
+func (c *Client) CallInto(ctx context.Context, req *jape.Request, v interface{}) (*Reply, error) {
+	rsp, err := c.Call(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(rsp.Data) != 0 {
+		if err := json.Unmarshal(rsp.Data, v); err != nil {
+			return nil, &jape.Error{Data: rsp.Data, Message: "decoding response data", Err: err}
+		}
+	}
+	return rsp, nil
+}
+
 // CallRaw issues the specified API request and returns the raw response body
 // without decoding. Errors from CallRaw have concrete type *jape.Error
 func (c *Client) CallRaw(ctx context.Context, req *jape.Request) ([]byte, error) {
-	_, body, err := (*jape.Client)(c).Call(ctx, req)
-	return body, err
+	jc := (*jape.Client)(c)
+	_, body, err := jc.Call(ctx, req)
+	if err != nil {
+		return nil, checkError(err, jc.Now())
+	}
+	return body, nil
 }
 
 // Stream issues the specified API request and streams results to the given
 // callback. Errors from Stream have concrete type *jape.Error.
+//
+// A system message sent by the server on the stream is delivered to the
+// callback as a reply whose System field is set. If the server cleanly closes
+// the stream after a system message, such as an operational disconnect, the
+// error reported by Stream wraps the *Error for that message, so that the
+// caller can distinguish this from a network failure. If the stream instead
+// fails in transport, Stream reports that error:
+//
+//	if errors.Is(err, twitter.ProblemOperationalDisconnect) {
+//	   // the server ended the stream
+//	}
 func (c *Client) Stream(ctx context.Context, req *jape.Request, f Callback) error {
-	return (*jape.Client)(c).Stream(ctx, req, func(body []byte) error {
+	jc := (*jape.Client)(c)
+	var last *Error    // the most recent system message, if no data followed
+	var lastMsg []byte // the body of last
+	var cbErr bool
+	err := jc.Stream(ctx, req, func(body []byte) error {
+		start := time.Now()
 		var reply Reply
 		if err := json.Unmarshal(body, &reply); err != nil {
+			cbErr = true
 			return &jape.Error{Data: body, Message: "decoding stream response", Err: err}
 		}
-		return f(&reply)
+		reply.Raw = body
+		reply.Info = &CallInfo{Bytes: len(body), Decode: time.Since(start)}
+		if len(reply.Data) == 0 && len(reply.Errors) != 0 {
+			reply.System = systemMessage(reply.Errors)
+			last, lastMsg = reply.System, body
+		} else {
+			last, lastMsg = nil, nil
+		}
+		if err := f(&reply); err != nil {
+			cbErr = true
+			return err
+		}
+		return nil
 	})
+	if err == nil && last != nil && !cbErr && ctx.Err() == nil {
+		return &jape.Error{Data: lastMsg, Message: "stream closed by server", Err: last}
+	}
+	return checkError(err, jc.Now())
+}
+
+// systemMessage returns an *Error describing a stream system message with
+// the given error details.
+func systemMessage(errs []*types.ErrorDetail) *Error {
+	d := errs[0]
+	return &Error{
+		Type:   Problem(d.TypeURL),
+		Title:  d.Title,
+		Detail: d.Detail,
+		Errors: errs,
+	}
 }
@@ -0,0 +1,32 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package types
+
+import "time"
+
+// A DMEvent is the decoded form of a Direct Message event.  The fields marked
+// "default" will always be populated by the API; other fields are filled in
+// based on the parameters in the request.
+type DMEvent struct {
+	ID        string `json:"id" twitter:"default"`
+	EventType string `json:"event_type" twitter:"default"` // "MessageCreate", "ParticipantsJoin", or "ParticipantsLeave"
+	Text      string `json:"text" twitter:"default"`       // only for MessageCreate
+
+	ConversationID string     `json:"dm_conversation_id,omitempty"`
+	CreatedAt      *time.Time `json:"created_at,omitempty"`
+	ParticipantIDs []string   `json:"participant_ids,omitempty"` // only for ParticipantsJoin and ParticipantsLeave
+	Referenced     []*Ref     `json:"referenced_tweets,omitempty"`
+	SenderID       string     `json:"sender_id,omitempty"`
+
+	Attachments `json:"attachments,omitempty"`
+
+	// Fields not recognized by this type; see DecodeOpts.
+	Extra Extra `json:"-"`
+}
+
+// Constants for the values of DMEvent.EventType.
+const (
+	DMMessageCreate     = "MessageCreate"
+	DMParticipantsJoin  = "ParticipantsJoin"
+	DMParticipantsLeave = "ParticipantsLeave"
+)
@@ -0,0 +1,33 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package types_test
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/928799934/twitter/types"
+)
+
+func TestDMEventFields(t *testing.T) {
+	tests := []struct {
+		fields      types.Fields
+		label, want string
+	}{
+		{types.DMEventFields{SenderID: true, ParticipantIDs: true, ConversationID: true},
+			"dm_event.fields", "dm_conversation_id,participant_ids,sender_id"},
+		{types.Expansions{SenderID: true, ParticipantIDs: true, MediaKeys: true},
+			"expansions", "attachments.media_keys,participant_ids,sender_id"},
+	}
+	for _, test := range tests {
+		if got := test.fields.Label(); got != test.label {
+			t.Errorf("Label: got %q, want %q", got, test.label)
+		}
+		vs := test.fields.Values()
+		sort.Strings(vs) // order is not significant to the API
+		if got := strings.Join(vs, ","); got != test.want {
+			t.Errorf("Values(%s): got %q, want %q", test.label, got, test.want)
+		}
+	}
+}
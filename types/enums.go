@@ -47,6 +47,31 @@ type Expansions struct {
 
 	// Return a user object representing a list's owner.
 	OwnerID bool `json:"owner_id"`
+
+	// Return Tweet objects for the earlier versions of an edited Tweet.
+	EditHistoryTweetIDs bool `json:"edit_history_tweet_ids"`
+
+	// Return user objects for the hosts of a Space.
+	HostIDs bool `json:"host_ids"`
+
+	// Return a user object representing the creator of a Space.
+	CreatorID bool `json:"creator_id"`
+
+	// Return user objects for the users invited to speak in a Space.
+	InvitedUserIDs bool `json:"invited_user_ids"`
+
+	// Return user objects for the speakers of a Space.
+	SpeakerIDs bool `json:"speaker_ids"`
+
+	// Return topic objects for the topics of a Space.
+	TopicIDs bool `json:"topic_ids"`
+
+	// Return a user object representing the sender of a Direct Message.
+	SenderID bool `json:"sender_id"`
+
+	// Return user objects for the users who joined or left a Direct Message
+	// conversation.
+	ParticipantIDs bool `json:"participant_ids"`
 }
 
 // Constants for the names of various metrics reported in a Metrics map.  The
@@ -3,18 +3,30 @@
 package types
 
 // ErrorDetail describes an error condition reported in an otherwise successful
-// reply from the API, such as missing expansion data.
+// reply from the API, such as missing expansion data. Error details are also
+// reported in the envelope of a failed request.
 //
 // See https://developer.twitter.com/en/support/twitter-api/error-troubleshooting
 type ErrorDetail struct {
 	// omitted: required_enrollment, registration_url
 
-	ClientID     string `json:"client_id,omitempty"` // e.g., "1011011"
-	Title        string `json:"title"`               // e.g., "Not Found Error"
-	Detail       string `json:"detail"`              // for human consumption
-	Parameter    string `json:"parameter"`           // e.g., "pinned_tweet_id"
-	Value        string `json:"value"`               // e.g., "12345"
-	Reason       string `json:"reason,omitempty"`    // e.g., "client-not-enrolled"
-	ResourceType string `json:"resource_type"`       // e.g., "tweet"
-	TypeURL      string `json:"type"`                // link to problem definition
+	ClientID     string `json:"client_id,omitempty"`   // e.g., "1011011"
+	Title        string `json:"title"`                 // e.g., "Not Found Error"
+	Detail       string `json:"detail"`                // for human consumption
+	Parameter    string `json:"parameter"`             // e.g., "pinned_tweet_id"
+	Value        string `json:"value"`                 // e.g., "12345"
+	Reason       string `json:"reason,omitempty"`      // e.g., "client-not-enrolled"
+	ResourceType string `json:"resource_type"`         // e.g., "tweet"
+	ResourceID   string `json:"resource_id,omitempty"` // e.g., "12345"
+	TypeURL      string `json:"type"`                  // link to problem definition
+
+	// For invalid requests, a description of the problem and the offending
+	// parameter values.
+	Message    string              `json:"message,omitempty"`
+	Parameters map[string][]string `json:"parameters,omitempty"`
+
+	// For stream system messages, the reason the server disconnected or
+	// refused the stream.
+	DisconnectType  string `json:"disconnect_type,omitempty"`  // e.g., "UpstreamOperationalDisconnect"
+	ConnectionIssue string `json:"connection_issue,omitempty"` // e.g., "TooManyConnections"
 }
@@ -0,0 +1,179 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// DecodeOpts provides parameters for Decode. A nil *DecodeOpts provides empty
+// values for all fields.
+type DecodeOpts struct {
+	// If true, the object types defined in this package (such as Tweet, User,
+	// List, Media, Poll, and Place) retain any JSON fields that are not
+	// recognized by the Go type, in the Extra field of the object.
+	//
+	// This is off by default, since retaining unknown fields costs additional
+	// time and memory during decoding.
+	PreserveUnknownFields bool
+}
+
+// Decode decodes the JSON value in data into v, as json.Unmarshal does,
+// using the given options. Regardless of the options, any fields in Extra are
+// included when an object is encoded, so that an object decoded with
+// PreserveUnknownFields can be re-encoded without loss.
+//
+// The query packages decode replies with the default options. To retain
+// unknown fields from a reply, decode its data again:
+//
+//	var tweets types.Tweets
+//	err := types.Decode(rsp.Data, &tweets, &types.DecodeOpts{PreserveUnknownFields: true})
+func Decode(data []byte, v interface{}, opts *DecodeOpts) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+	if opts == nil || !opts.PreserveUnknownFields {
+		return nil
+	}
+	return fillExtra(reflect.ValueOf(v), data)
+}
+
+// An extraHolder is an object type that can store its unrecognized fields.
+// The implementations are generated by mkenum.
+type extraHolder interface {
+	preserveExtra(data []byte) error
+}
+
+// fillExtra traverses v in parallel with the JSON value in data from which it
+// was decoded, and stores the unrecognized fields of each extraHolder found.
+func fillExtra(v reflect.Value, data json.RawMessage) error {
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return fillExtra(v.Elem(), data)
+
+	case reflect.Slice, reflect.Array:
+		if data[0] != '[' {
+			return nil
+		}
+		var elts []json.RawMessage
+		if err := json.Unmarshal(data, &elts); err != nil {
+			return err
+		}
+		for i := 0; i < v.Len() && i < len(elts); i++ {
+			if err := fillExtra(v.Index(i), elts[i]); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Struct:
+		if data[0] != '{' {
+			return nil
+		}
+		if v.CanAddr() {
+			if h, ok := v.Addr().Interface().(extraHolder); ok {
+				if err := h.preserveExtra(data); err != nil {
+					return err
+				}
+			}
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return err
+		}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			} else if f.Anonymous && name == "" {
+				// The fields of an untagged embedded struct are promoted.
+				if err := fillExtra(v.Field(i), data); err != nil {
+					return err
+				}
+				continue
+			} else if name == "" {
+				name = f.Name
+			}
+			if err := fillExtra(v.Field(i), fields[name]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Extra records the JSON fields of an object that are not recognized by its
+// Go type, mapping each field name to its undecoded value.
+type Extra map[string]json.RawMessage
+
+// decodeExtra returns the fields of the JSON object in data whose names are
+// not in known. It returns nil if there are no such fields.
+func decodeExtra(data []byte, known map[string]bool) (Extra, error) {
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	var extra Extra
+	for name, value := range all {
+		if known[name] {
+			continue
+		}
+		if extra == nil {
+			extra = make(Extra)
+		}
+		extra[name] = value
+	}
+	return extra, nil
+}
+
+// mergeExtra returns a copy of the JSON object in obj with the fields of
+// extra added. Fields already present in obj take precedence.
+func mergeExtra(obj []byte, extra Extra) ([]byte, error) {
+	var have map[string]json.RawMessage
+	if err := json.Unmarshal(obj, &have); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(extra))
+	for name := range extra {
+		if _, ok := have[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return obj, nil
+	}
+	sort.Strings(names)
+
+	// Splice the extra fields onto the end of the encoded object, so that the
+	// order of the known fields is preserved.
+	var buf bytes.Buffer
+	trim := bytes.TrimRight(obj, " \t\r\n")
+	buf.Write(trim[:len(trim)-1]) // drop the closing "}"
+	sep := len(have) != 0
+	for _, name := range names {
+		if sep {
+			buf.WriteByte(',')
+		}
+		sep = true
+		key, _ := json.Marshal(name)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(extra[name])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
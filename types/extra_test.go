@@ -0,0 +1,82 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package types_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/928799934/twitter/types"
+)
+
+func TestPreserveUnknownFields(t *testing.T) {
+	const input = `{"id":"1","text":"hi","scopes":{"followers":false},"public_metrics":{"like_count":3},"note":null}`
+
+	t.Run("Disabled", func(t *testing.T) {
+		var tw types.Tweet
+		if err := json.Unmarshal([]byte(input), &tw); err != nil {
+			t.Fatalf("Unmarshal: %v", err)
+		}
+		if tw.ID != "1" || tw.PublicMetrics["like_count"] != 3 {
+			t.Errorf("Unmarshal: got %+v", tw)
+		}
+		if tw.Extra != nil {
+			t.Errorf("Extra: got %v, want nil", tw.Extra)
+		}
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		var tw types.Tweet
+		if err := types.Decode([]byte(input), &tw, &types.DecodeOpts{PreserveUnknownFields: true}); err != nil {
+			t.Fatalf("Decode: %v", err)
+		}
+		if len(tw.Extra) != 2 || string(tw.Extra["scopes"]) != `{"followers":false}` {
+			t.Errorf("Extra: got %v, want scopes and note", tw.Extra)
+		}
+
+		// Re-encoding should retain the unknown fields.
+		out, err := json.Marshal(&tw)
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		var got, want map[string]interface{}
+		if err := json.Unmarshal(out, &got); err != nil {
+			t.Fatalf("Decoding output: %v", err)
+		}
+		json.Unmarshal([]byte(input), &want)
+		for key := range want {
+			if _, ok := got[key]; !ok {
+				t.Errorf("Output is missing field %q: %s", key, out)
+			}
+		}
+	})
+}
+
+func TestDecodeNested(t *testing.T) {
+	const input = `{"tweets":[{"id":"1","text":"a","x":1},{"id":"2","text":"b"}],
+  "users":[{"id":"5","username":"u","y":true}]}`
+	var v struct {
+		Tweets types.Tweets `json:"tweets"`
+		Users  types.Users  `json:"users"`
+	}
+	if err := types.Decode([]byte(input), &v, &types.DecodeOpts{PreserveUnknownFields: true}); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if got := string(v.Tweets[0].Extra["x"]); got != "1" {
+		t.Errorf("Tweet 1 extra: got %q, want 1", got)
+	}
+	if v.Tweets[1].Extra != nil {
+		t.Errorf("Tweet 2 extra: got %v, want nil", v.Tweets[1].Extra)
+	}
+	if got := string(v.Users[0].Extra["y"]); got != "true" {
+		t.Errorf("User extra: got %q, want true", got)
+	}
+
+	// Without the option, the same input retains nothing.
+	if err := types.Decode([]byte(input), &v, nil); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if v.Tweets[0].Extra != nil || v.Users[0].Extra != nil {
+		t.Errorf("Extra: got %v, %v; want nil", v.Tweets[0].Extra, v.Users[0].Extra)
+	}
+}
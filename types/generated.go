@@ -2,6 +2,8 @@ package types
 
 // Code generated by mkenum. DO NOT EDIT.
 
+import "encoding/json"
+
 // TweetFields defines optional Tweet field parameters.
 type TweetFields struct {
 	Attachments        bool // attachments
@@ -9,6 +11,8 @@ type TweetFields struct {
 	ContextAnnotations bool // context_annotations
 	ConversationID     bool // conversation_id
 	CreatedAt          bool // created_at
+	EditControls       bool // edit_controls
+	EditHistory        bool // edit_history_tweet_ids
 	Entities           bool // entities
 	Location           bool // geo
 	InReplyTo          bool // in_reply_to_user_id
@@ -44,6 +48,12 @@ func (f TweetFields) Values() []string {
 	if f.CreatedAt {
 		values = append(values, "created_at")
 	}
+	if f.EditControls {
+		values = append(values, "edit_controls")
+	}
+	if f.EditHistory {
+		values = append(values, "edit_history_tweet_ids")
+	}
 	if f.Entities {
 		values = append(values, "entities")
 	}
@@ -97,6 +107,10 @@ func (f *TweetFields) Set(name string, value bool) bool {
 		f.ConversationID = value
 	case "created_at":
 		f.CreatedAt = value
+	case "edit_controls":
+		f.EditControls = value
+	case "edit_history_tweet_ids":
+		f.EditHistory = value
 	case "entities":
 		f.Entities = value
 	case "geo":
@@ -127,6 +141,57 @@ func (f *TweetFields) Set(name string, value bool) bool {
 	return true
 }
 
+// tweetKnownFields are the JSON field names recognized by the Tweet type.
+var tweetKnownFields = map[string]bool{
+	"attachments":            true,
+	"author_id":              true,
+	"context_annotations":    true,
+	"conversation_id":        true,
+	"created_at":             true,
+	"edit_controls":          true,
+	"edit_history_tweet_ids": true,
+	"entities":               true,
+	"geo":                    true,
+	"id":                     true,
+	"in_reply_to_user_id":    true,
+	"lang":                   true,
+	"non_public_metrics":     true,
+	"organic_metrics":        true,
+	"possibly_sensitive":     true,
+	"promoted_metrics":       true,
+	"public_metrics":         true,
+	"referenced_tweets":      true,
+	"source":                 true,
+	"text":                   true,
+	"withheld":               true,
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.  Unrecognized
+// fields are discarded; to store them in o.Extra, use Decode.
+func (o *Tweet) UnmarshalJSON(data []byte) error {
+	type plain Tweet
+	o.Extra = nil
+	return json.Unmarshal(data, (*plain)(o))
+}
+
+// preserveExtra stores the fields of data not recognized by the type in o.Extra.
+func (o *Tweet) preserveExtra(data []byte) error {
+	extra, err := decodeExtra(data, tweetKnownFields)
+	o.Extra = extra
+	return err
+}
+
+// MarshalJSON implements the json.Marshaler interface.  Any fields in o.Extra
+// are included in the encoding, unless they conflict with known fields.
+func (o Tweet) MarshalJSON() ([]byte, error) {
+	type plain Tweet
+	data, err := json.Marshal(plain(o))
+	if err != nil || len(o.Extra) == 0 {
+		return data, err
+	}
+	return mergeExtra(data, o.Extra)
+}
+
 // Tweets is a searchable slice of Tweet values.
 type Tweets []*Tweet
 
@@ -229,6 +294,50 @@ func (f *UserFields) Set(name string, value bool) bool {
 	return true
 }
 
+// userKnownFields are the JSON field names recognized by the User type.
+var userKnownFields = map[string]bool{
+	"created_at":        true,
+	"description":       true,
+	"entities":          true,
+	"id":                true,
+	"location":          true,
+	"name":              true,
+	"pinned_tweet_id":   true,
+	"profile_image_url": true,
+	"protected":         true,
+	"public_metrics":    true,
+	"url":               true,
+	"username":          true,
+	"verified":          true,
+	"withheld":          true,
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.  Unrecognized
+// fields are discarded; to store them in o.Extra, use Decode.
+func (o *User) UnmarshalJSON(data []byte) error {
+	type plain User
+	o.Extra = nil
+	return json.Unmarshal(data, (*plain)(o))
+}
+
+// preserveExtra stores the fields of data not recognized by the type in o.Extra.
+func (o *User) preserveExtra(data []byte) error {
+	extra, err := decodeExtra(data, userKnownFields)
+	o.Extra = extra
+	return err
+}
+
+// MarshalJSON implements the json.Marshaler interface.  Any fields in o.Extra
+// are included in the encoding, unless they conflict with known fields.
+func (o User) MarshalJSON() ([]byte, error) {
+	type plain User
+	data, err := json.Marshal(plain(o))
+	if err != nil || len(o.Extra) == 0 {
+		return data, err
+	}
+	return mergeExtra(data, o.Extra)
+}
+
 // Users is a searchable slice of User values.
 type Users []*User
 
@@ -311,6 +420,44 @@ func (f *ListFields) Set(name string, value bool) bool {
 	return true
 }
 
+// listKnownFields are the JSON field names recognized by the List type.
+var listKnownFields = map[string]bool{
+	"created_at":     true,
+	"description":    true,
+	"follower_count": true,
+	"id":             true,
+	"member_count":   true,
+	"name":           true,
+	"owner_id":       true,
+	"private":        true,
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.  Unrecognized
+// fields are discarded; to store them in o.Extra, use Decode.
+func (o *List) UnmarshalJSON(data []byte) error {
+	type plain List
+	o.Extra = nil
+	return json.Unmarshal(data, (*plain)(o))
+}
+
+// preserveExtra stores the fields of data not recognized by the type in o.Extra.
+func (o *List) preserveExtra(data []byte) error {
+	extra, err := decodeExtra(data, listKnownFields)
+	o.Extra = extra
+	return err
+}
+
+// MarshalJSON implements the json.Marshaler interface.  Any fields in o.Extra
+// are included in the encoding, unless they conflict with known fields.
+func (o List) MarshalJSON() ([]byte, error) {
+	type plain List
+	data, err := json.Marshal(plain(o))
+	if err != nil || len(o.Extra) == 0 {
+		return data, err
+	}
+	return mergeExtra(data, o.Extra)
+}
+
 // Lists is a searchable slice of List values.
 type Lists []*List
 
@@ -326,6 +473,7 @@ func (ls Lists) FindByID(id string) *List {
 
 // MediaFields defines optional Media field parameters.
 type MediaFields struct {
+	AltText          bool // alt_text
 	Attachments      bool // attachments
 	Duration         bool // duration_ms
 	Height           bool // height
@@ -335,6 +483,7 @@ type MediaFields struct {
 	PromotedMetrics  bool // promoted_metrics
 	PublicMetrics    bool // public_metrics
 	URL              bool // url
+	Variants         bool // variants
 	Width            bool // width
 }
 
@@ -344,6 +493,9 @@ func (MediaFields) Label() string { return "media.fields" }
 // Values returns a slice of the selected field names from f.
 func (f MediaFields) Values() []string {
 	var values []string
+	if f.AltText {
+		values = append(values, "alt_text")
+	}
 	if f.Attachments {
 		values = append(values, "attachments")
 	}
@@ -371,6 +523,9 @@ func (f MediaFields) Values() []string {
 	if f.URL {
 		values = append(values, "url")
 	}
+	if f.Variants {
+		values = append(values, "variants")
+	}
 	if f.Width {
 		values = append(values, "width")
 	}
@@ -381,6 +536,8 @@ func (f MediaFields) Values() []string {
 // It reports whether name is a known parameter of f.
 func (f *MediaFields) Set(name string, value bool) bool {
 	switch name {
+	case "alt_text":
+		f.AltText = value
 	case "attachments":
 		f.Attachments = value
 	case "duration_ms":
@@ -399,6 +556,8 @@ func (f *MediaFields) Set(name string, value bool) bool {
 		f.PublicMetrics = value
 	case "url":
 		f.URL = value
+	case "variants":
+		f.Variants = value
 	case "width":
 		f.Width = value
 	default:
@@ -407,6 +566,50 @@ func (f *MediaFields) Set(name string, value bool) bool {
 	return true
 }
 
+// mediaKnownFields are the JSON field names recognized by the Media type.
+var mediaKnownFields = map[string]bool{
+	"alt_text":           true,
+	"attachments":        true,
+	"duration_ms":        true,
+	"height":             true,
+	"media_key":          true,
+	"non_public_metrics": true,
+	"organic_metrics":    true,
+	"preview_image_url":  true,
+	"promoted_metrics":   true,
+	"public_metrics":     true,
+	"type":               true,
+	"url":                true,
+	"variants":           true,
+	"width":              true,
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.  Unrecognized
+// fields are discarded; to store them in o.Extra, use Decode.
+func (o *Media) UnmarshalJSON(data []byte) error {
+	type plain Media
+	o.Extra = nil
+	return json.Unmarshal(data, (*plain)(o))
+}
+
+// preserveExtra stores the fields of data not recognized by the type in o.Extra.
+func (o *Media) preserveExtra(data []byte) error {
+	extra, err := decodeExtra(data, mediaKnownFields)
+	o.Extra = extra
+	return err
+}
+
+// MarshalJSON implements the json.Marshaler interface.  Any fields in o.Extra
+// are included in the encoding, unless they conflict with known fields.
+func (o Media) MarshalJSON() ([]byte, error) {
+	type plain Media
+	data, err := json.Marshal(plain(o))
+	if err != nil || len(o.Extra) == 0 {
+		return data, err
+	}
+	return mergeExtra(data, o.Extra)
+}
+
 // Medias is a searchable slice of Media values.
 type Medias []*Media
 
@@ -467,6 +670,42 @@ func (f *PollFields) Set(name string, value bool) bool {
 	return true
 }
 
+// pollKnownFields are the JSON field names recognized by the Poll type.
+var pollKnownFields = map[string]bool{
+	"attachments":      true,
+	"duration_minutes": true,
+	"end_datetime":     true,
+	"id":               true,
+	"options":          true,
+	"voting_status":    true,
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.  Unrecognized
+// fields are discarded; to store them in o.Extra, use Decode.
+func (o *Poll) UnmarshalJSON(data []byte) error {
+	type plain Poll
+	o.Extra = nil
+	return json.Unmarshal(data, (*plain)(o))
+}
+
+// preserveExtra stores the fields of data not recognized by the type in o.Extra.
+func (o *Poll) preserveExtra(data []byte) error {
+	extra, err := decodeExtra(data, pollKnownFields)
+	o.Extra = extra
+	return err
+}
+
+// MarshalJSON implements the json.Marshaler interface.  Any fields in o.Extra
+// are included in the encoding, unless they conflict with known fields.
+func (o Poll) MarshalJSON() ([]byte, error) {
+	type plain Poll
+	data, err := json.Marshal(plain(o))
+	if err != nil || len(o.Extra) == 0 {
+		return data, err
+	}
+	return mergeExtra(data, o.Extra)
+}
+
 // Polls is a searchable slice of Poll values.
 type Polls []*Poll
 
@@ -545,6 +784,45 @@ func (f *PlaceFields) Set(name string, value bool) bool {
 	return true
 }
 
+// placeKnownFields are the JSON field names recognized by the Place type.
+var placeKnownFields = map[string]bool{
+	"attachments":      true,
+	"contained_within": true,
+	"country":          true,
+	"country_code":     true,
+	"full_name":        true,
+	"geo":              true,
+	"id":               true,
+	"name":             true,
+	"place_type":       true,
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.  Unrecognized
+// fields are discarded; to store them in o.Extra, use Decode.
+func (o *Place) UnmarshalJSON(data []byte) error {
+	type plain Place
+	o.Extra = nil
+	return json.Unmarshal(data, (*plain)(o))
+}
+
+// preserveExtra stores the fields of data not recognized by the type in o.Extra.
+func (o *Place) preserveExtra(data []byte) error {
+	extra, err := decodeExtra(data, placeKnownFields)
+	o.Extra = extra
+	return err
+}
+
+// MarshalJSON implements the json.Marshaler interface.  Any fields in o.Extra
+// are included in the encoding, unless they conflict with known fields.
+func (o Place) MarshalJSON() ([]byte, error) {
+	type plain Place
+	data, err := json.Marshal(plain(o))
+	if err != nil || len(o.Extra) == 0 {
+		return data, err
+	}
+	return mergeExtra(data, o.Extra)
+}
+
 // Places is a searchable slice of Place values.
 type Places []*Place
 
@@ -558,6 +836,290 @@ func (ps Places) FindByID(id string) *Place {
 	return nil
 }
 
+// SpaceFields defines optional Space field parameters.
+type SpaceFields struct {
+	CreatedAt        bool // created_at
+	CreatorID        bool // creator_id
+	EndedAt          bool // ended_at
+	HostIDs          bool // host_ids
+	InvitedUserIDs   bool // invited_user_ids
+	IsTicketed       bool // is_ticketed
+	Language         bool // lang
+	ParticipantCount bool // participant_count
+	ScheduledStart   bool // scheduled_start
+	SpeakerIDs       bool // speaker_ids
+	StartedAt        bool // started_at
+	SubscriberCount  bool // subscriber_count
+	Title            bool // title
+	TopicIDs         bool // topic_ids
+	UpdatedAt        bool // updated_at
+}
+
+// Label returns the parameter tag for optional Space fields.
+func (SpaceFields) Label() string { return "space.fields" }
+
+// Values returns a slice of the selected field names from f.
+func (f SpaceFields) Values() []string {
+	var values []string
+	if f.CreatedAt {
+		values = append(values, "created_at")
+	}
+	if f.CreatorID {
+		values = append(values, "creator_id")
+	}
+	if f.EndedAt {
+		values = append(values, "ended_at")
+	}
+	if f.HostIDs {
+		values = append(values, "host_ids")
+	}
+	if f.InvitedUserIDs {
+		values = append(values, "invited_user_ids")
+	}
+	if f.IsTicketed {
+		values = append(values, "is_ticketed")
+	}
+	if f.Language {
+		values = append(values, "lang")
+	}
+	if f.ParticipantCount {
+		values = append(values, "participant_count")
+	}
+	if f.ScheduledStart {
+		values = append(values, "scheduled_start")
+	}
+	if f.SpeakerIDs {
+		values = append(values, "speaker_ids")
+	}
+	if f.StartedAt {
+		values = append(values, "started_at")
+	}
+	if f.SubscriberCount {
+		values = append(values, "subscriber_count")
+	}
+	if f.Title {
+		values = append(values, "title")
+	}
+	if f.TopicIDs {
+		values = append(values, "topic_ids")
+	}
+	if f.UpdatedAt {
+		values = append(values, "updated_at")
+	}
+	return values
+}
+
+// Set sets the selected field of f to value, by its parameter name.
+// It reports whether name is a known parameter of f.
+func (f *SpaceFields) Set(name string, value bool) bool {
+	switch name {
+	case "created_at":
+		f.CreatedAt = value
+	case "creator_id":
+		f.CreatorID = value
+	case "ended_at":
+		f.EndedAt = value
+	case "host_ids":
+		f.HostIDs = value
+	case "invited_user_ids":
+		f.InvitedUserIDs = value
+	case "is_ticketed":
+		f.IsTicketed = value
+	case "lang":
+		f.Language = value
+	case "participant_count":
+		f.ParticipantCount = value
+	case "scheduled_start":
+		f.ScheduledStart = value
+	case "speaker_ids":
+		f.SpeakerIDs = value
+	case "started_at":
+		f.StartedAt = value
+	case "subscriber_count":
+		f.SubscriberCount = value
+	case "title":
+		f.Title = value
+	case "topic_ids":
+		f.TopicIDs = value
+	case "updated_at":
+		f.UpdatedAt = value
+	default:
+		return false
+	}
+	return true
+}
+
+// spaceKnownFields are the JSON field names recognized by the Space type.
+var spaceKnownFields = map[string]bool{
+	"created_at":        true,
+	"creator_id":        true,
+	"ended_at":          true,
+	"host_ids":          true,
+	"id":                true,
+	"invited_user_ids":  true,
+	"is_ticketed":       true,
+	"lang":              true,
+	"participant_count": true,
+	"scheduled_start":   true,
+	"speaker_ids":       true,
+	"started_at":        true,
+	"state":             true,
+	"subscriber_count":  true,
+	"title":             true,
+	"topic_ids":         true,
+	"updated_at":        true,
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.  Unrecognized
+// fields are discarded; to store them in o.Extra, use Decode.
+func (o *Space) UnmarshalJSON(data []byte) error {
+	type plain Space
+	o.Extra = nil
+	return json.Unmarshal(data, (*plain)(o))
+}
+
+// preserveExtra stores the fields of data not recognized by the type in o.Extra.
+func (o *Space) preserveExtra(data []byte) error {
+	extra, err := decodeExtra(data, spaceKnownFields)
+	o.Extra = extra
+	return err
+}
+
+// MarshalJSON implements the json.Marshaler interface.  Any fields in o.Extra
+// are included in the encoding, unless they conflict with known fields.
+func (o Space) MarshalJSON() ([]byte, error) {
+	type plain Space
+	data, err := json.Marshal(plain(o))
+	if err != nil || len(o.Extra) == 0 {
+		return data, err
+	}
+	return mergeExtra(data, o.Extra)
+}
+
+// Spaces is a searchable slice of Space values.
+type Spaces []*Space
+
+// FindByID returns the first Space in ss whose ID matches, or nil.
+func (ss Spaces) FindByID(id string) *Space {
+	for _, v := range ss {
+		if v.ID == id {
+			return v
+		}
+	}
+	return nil
+}
+
+// DMEventFields defines optional DMEvent field parameters.
+type DMEventFields struct {
+	Attachments    bool // attachments
+	CreatedAt      bool // created_at
+	ConversationID bool // dm_conversation_id
+	ParticipantIDs bool // participant_ids
+	Referenced     bool // referenced_tweets
+	SenderID       bool // sender_id
+}
+
+// Label returns the parameter tag for optional DMEvent fields.
+func (DMEventFields) Label() string { return "dm_event.fields" }
+
+// Values returns a slice of the selected field names from f.
+func (f DMEventFields) Values() []string {
+	var values []string
+	if f.Attachments {
+		values = append(values, "attachments")
+	}
+	if f.CreatedAt {
+		values = append(values, "created_at")
+	}
+	if f.ConversationID {
+		values = append(values, "dm_conversation_id")
+	}
+	if f.ParticipantIDs {
+		values = append(values, "participant_ids")
+	}
+	if f.Referenced {
+		values = append(values, "referenced_tweets")
+	}
+	if f.SenderID {
+		values = append(values, "sender_id")
+	}
+	return values
+}
+
+// Set sets the selected field of f to value, by its parameter name.
+// It reports whether name is a known parameter of f.
+func (f *DMEventFields) Set(name string, value bool) bool {
+	switch name {
+	case "attachments":
+		f.Attachments = value
+	case "created_at":
+		f.CreatedAt = value
+	case "dm_conversation_id":
+		f.ConversationID = value
+	case "participant_ids":
+		f.ParticipantIDs = value
+	case "referenced_tweets":
+		f.Referenced = value
+	case "sender_id":
+		f.SenderID = value
+	default:
+		return false
+	}
+	return true
+}
+
+// dmEventKnownFields are the JSON field names recognized by the DMEvent type.
+var dmEventKnownFields = map[string]bool{
+	"attachments":        true,
+	"created_at":         true,
+	"dm_conversation_id": true,
+	"event_type":         true,
+	"id":                 true,
+	"participant_ids":    true,
+	"referenced_tweets":  true,
+	"sender_id":          true,
+	"text":               true,
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.  Unrecognized
+// fields are discarded; to store them in o.Extra, use Decode.
+func (o *DMEvent) UnmarshalJSON(data []byte) error {
+	type plain DMEvent
+	o.Extra = nil
+	return json.Unmarshal(data, (*plain)(o))
+}
+
+// preserveExtra stores the fields of data not recognized by the type in o.Extra.
+func (o *DMEvent) preserveExtra(data []byte) error {
+	extra, err := decodeExtra(data, dmEventKnownFields)
+	o.Extra = extra
+	return err
+}
+
+// MarshalJSON implements the json.Marshaler interface.  Any fields in o.Extra
+// are included in the encoding, unless they conflict with known fields.
+func (o DMEvent) MarshalJSON() ([]byte, error) {
+	type plain DMEvent
+	data, err := json.Marshal(plain(o))
+	if err != nil || len(o.Extra) == 0 {
+		return data, err
+	}
+	return mergeExtra(data, o.Extra)
+}
+
+// DMEvents is a searchable slice of DMEvent values.
+type DMEvents []*DMEvent
+
+// FindByID returns the first DMEvent in ds whose ID matches, or nil.
+func (ds DMEvents) FindByID(id string) *DMEvent {
+	for _, v := range ds {
+		if v.ID == id {
+			return v
+		}
+	}
+	return nil
+}
+
 // Label returns the parameter tag for optional Expansions fields.
 func (Expansions) Label() string { return "expansions" }
 
@@ -594,6 +1156,30 @@ func (f Expansions) Values() []string {
 	if f.OwnerID {
 		values = append(values, "owner_id")
 	}
+	if f.EditHistoryTweetIDs {
+		values = append(values, "edit_history_tweet_ids")
+	}
+	if f.HostIDs {
+		values = append(values, "host_ids")
+	}
+	if f.CreatorID {
+		values = append(values, "creator_id")
+	}
+	if f.InvitedUserIDs {
+		values = append(values, "invited_user_ids")
+	}
+	if f.SpeakerIDs {
+		values = append(values, "speaker_ids")
+	}
+	if f.TopicIDs {
+		values = append(values, "topic_ids")
+	}
+	if f.SenderID {
+		values = append(values, "sender_id")
+	}
+	if f.ParticipantIDs {
+		values = append(values, "participant_ids")
+	}
 	return values
 }
 
@@ -621,6 +1207,22 @@ func (f *Expansions) Set(name string, value bool) bool {
 		f.PinnedTweetID = value
 	case "owner_id":
 		f.OwnerID = value
+	case "edit_history_tweet_ids":
+		f.EditHistoryTweetIDs = value
+	case "host_ids":
+		f.HostIDs = value
+	case "creator_id":
+		f.CreatorID = value
+	case "invited_user_ids":
+		f.InvitedUserIDs = value
+	case "speaker_ids":
+		f.SpeakerIDs = value
+	case "topic_ids":
+		f.TopicIDs = value
+	case "sender_id":
+		f.SenderID = value
+	case "participant_ids":
+		f.ParticipantIDs = value
 	default:
 		return false
 	}
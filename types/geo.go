@@ -0,0 +1,143 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// A Point is a geographic position in decimal degrees.
+type Point struct {
+	Lat, Lon float64
+}
+
+// A BoundingBox is a rectangular geographic region in decimal degrees.  If
+// West > East, the box crosses the antimeridian.
+type BoundingBox struct {
+	West, South, East, North float64
+}
+
+// Contains reports whether the point at lat, lon lies within b, inclusive of
+// its edges.
+func (b BoundingBox) Contains(lat, lon float64) bool {
+	if lat < b.South || lat > b.North {
+		return false
+	}
+	if b.West <= b.East {
+		return lon >= b.West && lon <= b.East
+	}
+	return lon >= b.West || lon <= b.East // crosses the antimeridian
+}
+
+// Centroid returns the center point of b.
+func (b BoundingBox) Centroid() Point {
+	east := b.East
+	if b.West > east {
+		east += 360
+	}
+	lon := (b.West + east) / 2
+	if lon > 180 {
+		lon -= 360
+	}
+	return Point{Lat: (b.South + b.North) / 2, Lon: lon}
+}
+
+// Point decodes the exact coordinates of a tweet location. It returns nil
+// without error if no coordinates are present.
+func (l *Location) Point() (*Point, error) {
+	if l == nil || len(l.Coordinates) == 0 || string(l.Coordinates) == "null" {
+		return nil, nil
+	}
+	var geom struct {
+		Type        string    `json:"type"`
+		Coordinates []float64 `json:"coordinates"`
+	}
+	if err := json.Unmarshal(l.Coordinates, &geom); err != nil {
+		return nil, fmt.Errorf("decoding coordinates: %w", err)
+	} else if geom.Type != "Point" || len(geom.Coordinates) < 2 {
+		return nil, fmt.Errorf("coordinates are not a GeoJSON point: %s", l.Coordinates)
+	}
+	return &Point{Lon: geom.Coordinates[0], Lat: geom.Coordinates[1]}, nil
+}
+
+// BoundingBox decodes the bounding box of the place from its GeoJSON
+// location.  It accepts either a feature with a "bbox" member, as reported by
+// the v2 API, or a polygon, as reported by the v1.1 API. A polygon whose
+// longitudes span more than 180 degrees is taken to cross the antimeridian,
+// and yields a box with West > East. It returns nil without error if the place
+// has no location.
+func (p *Place) BoundingBox() (*BoundingBox, error) {
+	if p == nil || len(p.Location) == 0 || string(p.Location) == "null" {
+		return nil, nil
+	}
+	var geo struct {
+		BBox        []float64     `json:"bbox"`
+		Type        string        `json:"type"`
+		Coordinates [][][]float64 `json:"coordinates"`
+		Geometry    *struct {
+			Type        string          `json:"type"`
+			Coordinates json.RawMessage `json:"coordinates"`
+		} `json:"geometry"`
+	}
+	if err := json.Unmarshal(p.Location, &geo); err != nil {
+		return nil, fmt.Errorf("decoding place geo: %w", err)
+	}
+	if len(geo.BBox) == 4 {
+		return &BoundingBox{West: geo.BBox[0], South: geo.BBox[1], East: geo.BBox[2], North: geo.BBox[3]}, nil
+	}
+	ring := geo.Coordinates
+	if geo.Type == "Feature" && geo.Geometry != nil && geo.Geometry.Type == "Polygon" {
+		if err := json.Unmarshal(geo.Geometry.Coordinates, &ring); err != nil {
+			return nil, fmt.Errorf("decoding place geometry: %w", err)
+		}
+	}
+	var b *BoundingBox
+	minEast, maxWest := 180.0, -180.0 // extremes by hemisphere, for wrapping
+	for _, poly := range ring {
+		for _, pt := range poly {
+			if len(pt) < 2 {
+				continue
+			}
+			lon, lat := pt[0], pt[1]
+			if lon >= 0 && lon < minEast {
+				minEast = lon
+			} else if lon < 0 && lon > maxWest {
+				maxWest = lon
+			}
+			if b == nil {
+				b = &BoundingBox{West: lon, South: lat, East: lon, North: lat}
+				continue
+			}
+			if lon < b.West {
+				b.West = lon
+			}
+			if lon > b.East {
+				b.East = lon
+			}
+			if lat < b.South {
+				b.South = lat
+			}
+			if lat > b.North {
+				b.North = lat
+			}
+		}
+	}
+	if b == nil {
+		return nil, errors.New("place geo has no bounding box")
+	}
+	if b.East-b.West > 180 {
+		// The polygon spans more than half the globe, which for a place means
+		// it crosses the antimeridian; wrap the box around it instead.
+		b.West, b.East = minEast, maxWest
+	}
+	return b, nil
+}
+
+// Contains reports whether the point at lat, lon lies within the bounding box
+// of the place. It reports false if the place has no valid bounding box.
+func (p *Place) Contains(lat, lon float64) bool {
+	b, err := p.BoundingBox()
+	return err == nil && b != nil && b.Contains(lat, lon)
+}
@@ -0,0 +1,98 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package types_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/928799934/twitter/types"
+)
+
+func TestPlaceBoundingBox(t *testing.T) {
+	tests := []struct {
+		name, geo string
+	}{
+		{"v2", `{"type":"Feature","bbox":[-74.026675,40.683935,-73.910408,40.877483],"properties":{}}`},
+		{"v1.1", `{"type":"Polygon","coordinates":[[[-74.026675,40.683935],[-73.910408,40.683935],
+  [-73.910408,40.877483],[-74.026675,40.877483]]]}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := &types.Place{FullName: "Manhattan, NY", Location: json.RawMessage(test.geo)}
+			b, err := p.BoundingBox()
+			if err != nil {
+				t.Fatalf("BoundingBox: unexpected error: %v", err)
+			}
+			want := types.BoundingBox{West: -74.026675, South: 40.683935, East: -73.910408, North: 40.877483}
+			if *b != want {
+				t.Errorf("BoundingBox: got %+v, want %+v", *b, want)
+			}
+			if !p.Contains(40.7484, -73.9857) {
+				t.Error("Contains: point in Manhattan is not contained")
+			}
+			if p.Contains(51.5007, -0.1246) {
+				t.Error("Contains: point in London is contained")
+			}
+		})
+	}
+
+	var none *types.Place
+	if b, err := none.BoundingBox(); b != nil || err != nil {
+		t.Errorf("BoundingBox(nil): got %v, %v; want nil, nil", b, err)
+	}
+}
+
+func TestBoundingBoxAntimeridian(t *testing.T) {
+	b := types.BoundingBox{West: 170, South: -20, East: -170, North: -10}
+	if !b.Contains(-15, 179) || !b.Contains(-15, -179) {
+		t.Error("Contains: points near the antimeridian are not contained")
+	}
+	if b.Contains(-15, 0) {
+		t.Error("Contains: point outside the box is contained")
+	}
+	if got := b.Centroid(); got.Lat != -15 || got.Lon != 180 {
+		t.Errorf("Centroid: got %+v, want lat -15, lon 180", got)
+	}
+}
+
+func TestPlacePolygonAntimeridian(t *testing.T) {
+	// A rough outline of Fiji, which straddles 180 degrees longitude.
+	p := &types.Place{Location: json.RawMessage(`{"type":"Polygon","coordinates":[[
+  [177.0,-19.2],[-179.8,-19.2],[-179.8,-16.0],[177.0,-16.0]]]}`)}
+	b, err := p.BoundingBox()
+	if err != nil {
+		t.Fatalf("BoundingBox: unexpected error: %v", err)
+	}
+	want := types.BoundingBox{West: 177.0, South: -19.2, East: -179.8, North: -16.0}
+	if *b != want {
+		t.Errorf("BoundingBox: got %+v, want %+v", *b, want)
+	}
+	if !p.Contains(-17.8, 178.4) || !p.Contains(-17.8, -179.9) {
+		t.Error("Contains: points in Fiji are not contained")
+	}
+	if p.Contains(-17.8, 0) {
+		t.Error("Contains: point on the prime meridian is contained")
+	}
+}
+
+func TestLocationPoint(t *testing.T) {
+	var tw types.Tweet
+	const input = `{"id":"1","text":"here","geo":{"place_id":"01a9a39529b27f36",
+  "coordinates":{"type":"Point","coordinates":[-73.9857,40.7484]}}}`
+	if err := json.Unmarshal([]byte(input), &tw); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	pt, err := tw.Location.Point()
+	if err != nil {
+		t.Fatalf("Point: unexpected error: %v", err)
+	}
+	if pt.Lat != 40.7484 || pt.Lon != -73.9857 {
+		t.Errorf("Point: got %+v, want lat 40.7484, lon -73.9857", pt)
+	}
+
+	noCoords := &types.Location{PlaceID: "01a9a39529b27f36"}
+	if pt, err := noCoords.Point(); pt != nil || err != nil {
+		t.Errorf("Point: got %v, %v; want nil, nil", pt, err)
+	}
+}
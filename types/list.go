@@ -19,4 +19,7 @@ type List struct {
 	Members     int        `json:"member_count,omitempty"`
 	OwnerID     string     `json:"owner_id,omitempty"`
 	Private     bool       `json:"private,omitempty"`
+
+	// Fields not recognized by this type; see DecodeOpts.
+	Extra Extra `json:"-"`
 }
@@ -14,7 +14,44 @@ type Media struct {
 	Height          int          `json:"height"` // pixels
 	Width           int          `json:"width"`  // pixels
 	PreviewImageURL string       `json:"preview_image_url"`
+	AltText         string       `json:"alt_text,omitempty"` // a description for accessibility
+
+	// For videos and animated GIFs, the available encodings of the media.
+	Variants []*MediaVariant `json:"variants,omitempty"`
 
 	Attachments `json:"attachments"`
 	MetricSet
+
+	// Fields not recognized by this type; see DecodeOpts.
+	Extra Extra `json:"-"`
+}
+
+// BestVariant returns the variant of m with the highest bit rate among those
+// with the given content type, or nil if there are none. If contentType is
+// "", all variants are considered. Variants with no bit rate, such as HLS
+// playlists, are chosen only if there is no other candidate.
+//
+// For example, to find the highest-quality MP4 encoding of a video:
+//
+//	if v := m.BestVariant("video/mp4"); v != nil {
+//	   fetch(v.URL)
+//	}
+func (m *Media) BestVariant(contentType string) *MediaVariant {
+	var best *MediaVariant
+	for _, v := range m.Variants {
+		if contentType != "" && v.ContentType != contentType {
+			continue
+		}
+		if best == nil || v.BitRate > best.BitRate {
+			best = v
+		}
+	}
+	return best
+}
+
+// A MediaVariant describes one encoding of a video or animated GIF.
+type MediaVariant struct {
+	BitRate     int    `json:"bit_rate,omitempty"` // bits per second
+	ContentType string `json:"content_type"`       // e.g., "video/mp4"
+	URL         string `json:"url"`
 }
@@ -0,0 +1,53 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package types_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/928799934/twitter/types"
+)
+
+func TestMediaVariants(t *testing.T) {
+	const input = `{"media_key":"7_1","type":"video","alt_text":"a cat",
+  "public_metrics":{"view_count":5},
+  "non_public_metrics":{"playback_25_count":3},
+  "variants":[
+    {"content_type":"application/x-mpegURL","url":"https://video.example.com/pl.m3u8"},
+    {"bit_rate":832000,"content_type":"video/mp4","url":"https://video.example.com/mid.mp4"},
+    {"bit_rate":2176000,"content_type":"video/mp4","url":"https://video.example.com/high.mp4"},
+    {"bit_rate":256000,"content_type":"video/mp4","url":"https://video.example.com/low.mp4"}
+  ]}`
+	var m types.Media
+	if err := json.Unmarshal([]byte(input), &m); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if m.AltText != "a cat" {
+		t.Errorf("AltText: got %q, want %q", m.AltText, "a cat")
+	}
+	if got := m.PublicMetrics[types.Metric_ViewCount]; got != 5 {
+		t.Errorf("View count: got %d, want 5", got)
+	}
+	if got := m.NonPublicMetrics[types.Metric_Playback25Count]; got != 3 {
+		t.Errorf("Playback 25%% count: got %d, want 3", got)
+	}
+
+	tests := []struct {
+		ctype, want string
+	}{
+		{"video/mp4", "https://video.example.com/high.mp4"},
+		{"", "https://video.example.com/high.mp4"},
+		{"application/x-mpegURL", "https://video.example.com/pl.m3u8"},
+		{"image/gif", ""},
+	}
+	for _, test := range tests {
+		var got string
+		if v := m.BestVariant(test.ctype); v != nil {
+			got = v.URL
+		}
+		if got != test.want {
+			t.Errorf("BestVariant(%q): got %q, want %q", test.ctype, got, test.want)
+		}
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"reflect"
 	"sort"
 	"strings"
+	"unicode"
 
 	"github.com/928799934/twitter/types"
 )
@@ -36,18 +37,31 @@ func main() {
 	// See: https://golang.org/s/generatedcode
 	fmt.Fprintf(&code, "package types\n// Code generated by %[1]s. DO NOT EDIT.\n\n",
 		filepath.Base(os.Args[0]))
+	fmt.Fprint(&code, "import \"encoding/json\"\n\n")
 	generateEnum(&code, "Tweet", (*types.Tweet)(nil))
+	generateJSONMethods(&code, "Tweet", (*types.Tweet)(nil))
 	generateSearchableSlice(&code, "Tweet", "ID")
 	generateEnum(&code, "User", (*types.User)(nil))
+	generateJSONMethods(&code, "User", (*types.User)(nil))
 	generateSearchableSlice(&code, "User", "ID", "Username")
 	generateEnum(&code, "List", (*types.List)(nil))
+	generateJSONMethods(&code, "List", (*types.List)(nil))
 	generateSearchableSlice(&code, "List", "ID")
 	generateEnum(&code, "Media", (*types.Media)(nil))
+	generateJSONMethods(&code, "Media", (*types.Media)(nil))
 	generateSearchableSlice(&code, "Media", "Key")
 	generateEnum(&code, "Poll", (*types.Poll)(nil))
+	generateJSONMethods(&code, "Poll", (*types.Poll)(nil))
 	generateSearchableSlice(&code, "Poll", "ID")
 	generateEnum(&code, "Place", (*types.Place)(nil))
+	generateJSONMethods(&code, "Place", (*types.Place)(nil))
 	generateSearchableSlice(&code, "Place", "ID")
+	generateEnum(&code, "Space", (*types.Space)(nil))
+	generateJSONMethods(&code, "Space", (*types.Space)(nil))
+	generateSearchableSlice(&code, "Space", "ID")
+	generateEnum(&code, "DMEvent", (*types.DMEvent)(nil))
+	generateJSONMethods(&code, "DMEvent", (*types.DMEvent)(nil))
+	generateSearchableSlice(&code, "DMEvent", "ID")
 	generateFieldsMethods(&code, "Expansions", "Expansions", "expansions",
 		fieldKeys((*types.Expansions)(nil)))
 
@@ -73,8 +87,8 @@ func main() {
 }
 
 func generateEnum(w io.Writer, base string, v interface{}) {
-	typeName := base + "Fields"                    // e.g., TweetFields
-	typeLabel := strings.ToLower(base) + ".fields" // e.g., tweet.fields
+	typeName := base + "Fields"              // e.g., TweetFields
+	typeLabel := snakeCase(base) + ".fields" // e.g., tweet.fields
 
 	fmt.Fprintf(w, "// %s defines optional %s field parameters.\n", typeName, base)
 	fmt.Fprintf(w, "type %s struct{\n", typeName)
@@ -94,6 +108,76 @@ func generateEnum(w io.Writer, base string, v interface{}) {
 	generateFieldsMethods(w, base, typeName, typeLabel, fields)
 }
 
+// snakeCase converts a type name like "DMEvent" into the lower-case form the
+// API uses in parameter labels, e.g., "dm_event".
+func snakeCase(name string) string {
+	var sb strings.Builder
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := rune(name[i-1])
+			next := i+1 < len(name) && unicode.IsLower(rune(name[i+1]))
+			if unicode.IsLower(prev) || next {
+				sb.WriteByte('_')
+			}
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	return sb.String()
+}
+
+// lowerInitial converts the leading initialism of a type name to lower case,
+// e.g., "Tweet" becomes "tweet" and "DMEvent" becomes "dmEvent".
+func lowerInitial(name string) string {
+	n := 1
+	for n < len(name) && unicode.IsUpper(rune(name[n])) {
+		n++
+	}
+	if n > 1 && n < len(name) {
+		n-- // the last capital begins the next word
+	}
+	return strings.ToLower(name[:n]) + name[n:]
+}
+
+// generateJSONMethods generates JSON encoding and decoding methods for the
+// type named by base, to support preservation of unknown fields.
+func generateJSONMethods(w io.Writer, base string, v interface{}) {
+	knownName := lowerInitial(base) + "KnownFields"
+	fmt.Fprintf(w, "// %s are the JSON field names recognized by the %s type.\n", knownName, base)
+	fmt.Fprintf(w, "var %s = map[string]bool{\n", knownName)
+	for _, name := range jsonFieldNames(v) {
+		fmt.Fprintf(w, "\t%q: true,\n", name)
+	}
+	fmt.Fprint(w, "}\n\n")
+
+	fmt.Fprintf(w, `// UnmarshalJSON implements the json.Unmarshaler interface.  Unrecognized
+// fields are discarded; to store them in o.Extra, use Decode.
+func (o *%[1]s) UnmarshalJSON(data []byte) error {
+  type plain %[1]s
+  o.Extra = nil
+  return json.Unmarshal(data, (*plain)(o))
+}
+
+// preserveExtra stores the fields of data not recognized by the type in o.Extra.
+func (o *%[1]s) preserveExtra(data []byte) error {
+  extra, err := decodeExtra(data, %[2]s)
+  o.Extra = extra
+  return err
+}
+
+// MarshalJSON implements the json.Marshaler interface.  Any fields in o.Extra
+// are included in the encoding, unless they conflict with known fields.
+func (o %[1]s) MarshalJSON() ([]byte, error) {
+  type plain %[1]s
+  data, err := json.Marshal(plain(o))
+  if err != nil || len(o.Extra) == 0 {
+    return data, err
+  }
+  return mergeExtra(data, o.Extra)
+}
+
+`, base, knownName)
+}
+
 func generateSearchableSlice(w io.Writer, base string, fields ...string) {
 	typeName := base + "s"
 	recvName := strings.ToLower(base[:1]) + "s"
@@ -198,6 +282,28 @@ func fieldKeys(v interface{}) []fieldInfo {
 	return tags
 }
 
+// jsonFieldNames returns the sorted JSON names of all the fields of v, which
+// must be of type *T for some struct type T, including the fields of any
+// embedded anonymous structs.
+func jsonFieldNames(v interface{}) []string {
+	var names []string
+	var visit func(reflect.Type)
+	visit = func(typ reflect.Type) {
+		for i := 0; i < typ.NumField(); i++ {
+			next := typ.Field(i)
+			if name, ok := jsonFieldName(next.Tag); ok {
+				names = append(names, name)
+			} else if _, tagged := next.Tag.Lookup("json"); !tagged &&
+				next.Anonymous && next.Type.Kind() == reflect.Struct {
+				visit(next.Type)
+			}
+		}
+	}
+	visit(reflect.TypeOf(v).Elem())
+	sort.Strings(names)
+	return names
+}
+
 func jsonFieldName(tag reflect.StructTag) (string, bool) {
 	val, ok := tag.Lookup("json")
 	if ok {
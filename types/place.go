@@ -16,7 +16,10 @@ type Place struct {
 	ContainedIn []string        `json:"contained_within"`
 	CountryName string          `json:"country"`      // e.g., "United States"
 	CountryCode string          `json:"country_code"` // e.g., "US"; https://www.iso.org/obp/ui/#search
-	Location    json.RawMessage `json:"geo"`          // in GeoJSON; https://geojson.org/
+	Location    json.RawMessage `json:"geo"`          // in GeoJSON; see BoundingBox
 
 	Attachments `json:"attachments"`
+
+	// Fields not recognized by this type; see DecodeOpts.
+	Extra Extra `json:"-"`
 }
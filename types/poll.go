@@ -16,6 +16,9 @@ type Poll struct {
 	VotingStatus string     `json:"voting_status"` // e.g., "closed"
 
 	Attachments `json:"attachments"`
+
+	// Fields not recognized by this type; see DecodeOpts.
+	Extra Extra `json:"-"`
 }
 
 // A PollOption is a single choice item in a poll.
@@ -0,0 +1,32 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package types
+
+import "time"
+
+// A Space is the decoded form of a Space (a live audio conversation).  The
+// fields marked "default" will always be populated by the API; other fields
+// are filled in based on the parameters in the request.
+type Space struct {
+	ID    string `json:"id" twitter:"default"`
+	State string `json:"state" twitter:"default"` // "live", "scheduled", or "ended"
+
+	CreatedAt        *time.Time `json:"created_at,omitempty"`
+	CreatorID        string     `json:"creator_id,omitempty"`
+	EndedAt          *time.Time `json:"ended_at,omitempty"`
+	HostIDs          []string   `json:"host_ids,omitempty"`
+	InvitedUserIDs   []string   `json:"invited_user_ids,omitempty"`
+	IsTicketed       bool       `json:"is_ticketed,omitempty"`
+	Language         string     `json:"lang,omitempty"`
+	ParticipantCount int        `json:"participant_count,omitempty"`
+	ScheduledStart   *time.Time `json:"scheduled_start,omitempty"`
+	SpeakerIDs       []string   `json:"speaker_ids,omitempty"`
+	StartedAt        *time.Time `json:"started_at,omitempty"`
+	SubscriberCount  int        `json:"subscriber_count,omitempty"`
+	Title            string     `json:"title,omitempty"`
+	TopicIDs         []string   `json:"topic_ids,omitempty"`
+	UpdatedAt        *time.Time `json:"updated_at,omitempty"`
+
+	// Fields not recognized by this type; see DecodeOpts.
+	Extra Extra `json:"-"`
+}
@@ -4,6 +4,7 @@ package types
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
 )
 
@@ -25,16 +26,32 @@ type Tweet struct {
 	Referenced     []*Ref     `json:"referenced_tweets,omitempty"`
 	Source         string     `json:"source,omitempty"` // e.g., "Twitter Web App"
 
+	// The IDs of all versions of an edited tweet, oldest first. The last is
+	// the most recent version. A tweet that was not edited has one ID.
+	EditHistory  []string      `json:"edit_history_tweet_ids,omitempty"`
+	EditControls *EditControls `json:"edit_controls,omitempty"`
+
 	ContextAnnotations []*ContextAnnotation `json:"context_annotations,omitempty"`
 	Withheld           *Withholding         `json:"withheld,omitempty"`
 	Attachments        `json:"attachments,omitempty"`
 	MetricSet
+
+	// Fields not recognized by this type; see DecodeOpts.
+	Extra Extra `json:"-"`
 }
 
 // Attachments is a map of attachment type keys to string IDs for objects
 // attached to a reply.
 type Attachments map[string][]string
 
+// MediaKeys returns the keys of the attached media, if any.  To resolve them
+// against the media included in a reply, use twitter.Reply.AttachedMedia.
+func (a Attachments) MediaKeys() []string { return a["media_keys"] }
+
+// PollIDs returns the IDs of the attached polls, if any.  To resolve them
+// against the polls included in a reply, use twitter.Reply.AttachedPolls.
+func (a Attachments) PollIDs() []string { return a["poll_ids"] }
+
 // A ContextAnnotation is a collection of domain and/or entity labels, inferred
 // based on the text of a tweet.  Context annotations can yield one or many
 // domains.
@@ -56,16 +73,72 @@ type Domain struct {
 // An Entity identifies a programmatically-defined entity annotation associated
 // with a particular span of a tweet (see Annotation).
 type Entity struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// HasContext reports whether t has a context annotation with the given domain
+// and entity IDs. An empty ID matches any domain or entity, so for example
+// HasContext("46", "") reports whether t has any annotation in domain 46.
+func (t *Tweet) HasContext(domainID, entityID string) bool {
+	for _, ca := range t.ContextAnnotations {
+		if domainID != "" && (ca.Domain == nil || ca.Domain.ID != domainID) {
+			continue
+		}
+		if entityID != "" && (ca.Entity == nil || ca.Entity.ID != entityID) {
+			continue
+		}
+		return true
+	}
+	return false
 }
 
-// A Span denotes a span of text associated with an annotation.
+// A Span denotes a span of text associated with an annotation. The offsets
+// count Unicode code points (runes), not bytes, from the start of the text;
+// Start is inclusive and End is exclusive.
 type Span struct {
 	Start int `json:"start"`
 	End   int `json:"end"`
 }
 
+// Text returns the portion of text covered by s, or "" if s does not lie
+// within text. Since the offsets of s count runes, slicing text directly by
+// byte offset is wrong for text that is not ASCII.
+func (s Span) Text(text string) string {
+	start, end := s.ByteOffsets(text)
+	if start < 0 {
+		return ""
+	}
+	return text[start:end]
+}
+
+// ByteOffsets returns the byte offsets in text of the start (inclusive) and
+// end (exclusive) of s, or -1, -1 if s does not lie within text.
+func (s Span) ByteOffsets(text string) (start, end int) {
+	if s.Start < 0 || s.End < s.Start {
+		return -1, -1
+	}
+	start = -1
+	var n int // runes seen
+	for i := range text {
+		if n == s.Start {
+			start = i
+		}
+		if n == s.End {
+			return start, i
+		}
+		n++
+	}
+	if n == s.End { // the span ends at the end of text
+		if start < 0 {
+			start = len(text)
+		}
+		return start, len(text)
+	}
+	return -1, -1
+}
+
 // Entities captures annotations and other embedded entities in a text.
 type Entities struct {
 	Annotations []*Annotation `json:"annotations,omitempty"`
@@ -93,6 +166,7 @@ type Tag struct {
 type Mention struct {
 	Span
 	Username string `json:"username"`
+	ID       string `json:"id,omitempty"` // the user ID of the mentioned user
 }
 
 // A URL denotes a span of text encoding a URL.
@@ -108,7 +182,8 @@ type URL struct {
 }
 
 // A Location carries the content of a place ("geo"). The payload is encoded as
-// GeoJSON, see https://geojson.org. It is captured here as raw JSON.
+// GeoJSON, see https://geojson.org. It is captured here as raw JSON; use the
+// Point method to decode the coordinates.
 type Location struct {
 	PlaceID     string          `json:"place_id"`
 	Coordinates json.RawMessage `json:"coordinates"` // as GeoJSON
@@ -148,6 +223,13 @@ type MetricSet struct {
 	PromotedMetrics Metrics `json:"promoted_metrics,omitempty" twitter:"user-context"`
 }
 
+// EditControls describes whether and for how long a tweet can be edited.
+type EditControls struct {
+	EditsRemaining int        `json:"edits_remaining"`
+	IsEditEligible bool       `json:"is_edit_eligible"`
+	EditableUntil  *time.Time `json:"editable_until,omitempty"`
+}
+
 // A Ref is a reference to another entity, giving its type and ID.
 type Ref struct {
 	Type string `json:"type"`
@@ -158,4 +240,26 @@ type Ref struct {
 type Withholding struct {
 	Copyright    bool     `json:"copyright"`
 	CountryCodes []string `json:"country_codes"`
+	Scope        string   `json:"scope,omitempty"` // "tweet" or "user"
+}
+
+// InCountry reports whether w withholds content in the country with the given
+// ISO 3166-1 alpha-2 code. The code "XX" in w means all countries. It is safe
+// to call InCountry on a nil *Withholding, which withholds nothing.
+func (w *Withholding) InCountry(code string) bool {
+	if w == nil {
+		return false
+	}
+	for _, cc := range w.CountryCodes {
+		if cc == "XX" || strings.EqualFold(cc, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// A MatchingRule identifies a filtered stream rule that matched a tweet.
+type MatchingRule struct {
+	ID  string `json:"id"`
+	Tag string `json:"tag,omitempty"`
 }
@@ -0,0 +1,105 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package types_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/928799934/twitter/types"
+)
+
+func TestHasContext(t *testing.T) {
+	const input = `{"id":"1","text":"goal!","context_annotations":[
+  {"domain":{"id":"11","name":"Sport","description":"Types of sports"},
+   "entity":{"id":"847","name":"Football","description":"The sport"}},
+  {"domain":{"id":"46","name":"Brand Category"},"entity":{"id":"781"}}
+]}`
+	var tw types.Tweet
+	if err := json.Unmarshal([]byte(input), &tw); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if got := tw.ContextAnnotations[0].Entity.Description; got != "The sport" {
+		t.Errorf("Entity description: got %q, want %q", got, "The sport")
+	}
+
+	tests := []struct {
+		domain, entity string
+		want           bool
+	}{
+		{"", "", true},
+		{"11", "", true},
+		{"11", "847", true},
+		{"", "781", true},
+		{"11", "781", false},
+		{"12", "", false},
+	}
+	for _, test := range tests {
+		if got := tw.HasContext(test.domain, test.entity); got != test.want {
+			t.Errorf("HasContext(%q, %q): got %v, want %v", test.domain, test.entity, got, test.want)
+		}
+	}
+}
+
+func TestSpanText(t *testing.T) {
+	const input = `{"id":"2","text":"¡Hola @gopher! 🐹 #golang $GOOG",
+  "entities":{
+    "mentions":[{"start":6,"end":13,"username":"gopher","id":"42"}],
+    "hashtags":[{"start":17,"end":24,"tag":"golang"}],
+    "cashtags":[{"start":25,"end":30,"tag":"GOOG"}],
+    "annotations":[{"start":7,"end":13,"probability":0.5,"type":"Other","normalized_text":"gopher"}]
+  }}`
+	var tw types.Tweet
+	if err := json.Unmarshal([]byte(input), &tw); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	ents := tw.Entities
+	if got := ents.Mentions[0].ID; got != "42" {
+		t.Errorf("Mention ID: got %q, want 42", got)
+	}
+	tests := []struct {
+		span types.Span
+		want string
+	}{
+		{ents.Mentions[0].Span, "@gopher"},
+		{ents.HashTags[0].Span, "#golang"},
+		{ents.CashTags[0].Span, "$GOOG"},
+		{ents.Annotations[0].Span, "gopher"},
+		{types.Span{Start: 0, End: 1}, "¡"},
+		{types.Span{Start: 30, End: 30}, ""},
+		{types.Span{Start: 25, End: 31}, ""}, // past the end
+		{types.Span{Start: 3, End: 2}, ""},   // inverted
+	}
+	for _, test := range tests {
+		if got := test.span.Text(tw.Text); got != test.want {
+			t.Errorf("Span %+v: got %q, want %q", test.span, got, test.want)
+		}
+	}
+	if start, end := (types.Span{Start: 30, End: 30}).ByteOffsets(tw.Text); start != len(tw.Text) || end != start {
+		t.Errorf("ByteOffsets at end: got %d, %d, want %d", start, end, len(tw.Text))
+	}
+}
+
+func TestWithheld(t *testing.T) {
+	const input = `{"id":"3","text":"no","withheld":{"copyright":false,"country_codes":["DE","FR"],"scope":"tweet"}}`
+	var tw types.Tweet
+	if err := json.Unmarshal([]byte(input), &tw); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	w := tw.Withheld
+	if w == nil || w.Scope != "tweet" {
+		t.Fatalf("Withheld: got %+v, want scope tweet", w)
+	}
+	for code, want := range map[string]bool{"DE": true, "fr": true, "US": false} {
+		if got := w.InCountry(code); got != want {
+			t.Errorf("InCountry(%q): got %v, want %v", code, got, want)
+		}
+	}
+	if (&types.Withholding{CountryCodes: []string{"XX"}}).InCountry("US") != true {
+		t.Error("InCountry: XX does not withhold everywhere")
+	}
+	var none *types.Withholding
+	if none.InCountry("DE") {
+		t.Error("InCountry: nil withholds content")
+	}
+}
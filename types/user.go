@@ -25,6 +25,9 @@ type User struct {
 
 	PublicMetrics Metrics      `json:"public_metrics,omitempty"`
 	Withheld      *Withholding `json:"withheld,omitempty"`
+
+	// Fields not recognized by this type; see DecodeOpts.
+	Extra Extra `json:"-"`
 }
 
 // UserEntities describe entities found in a user's profile.
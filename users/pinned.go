@@ -0,0 +1,57 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package users
+
+import (
+	"context"
+
+	"github.com/928799934/twitter"
+	"github.com/928799934/twitter/types"
+)
+
+// A PinnedTweet pairs a user with the tweet pinned to the user's profile.
+type PinnedTweet struct {
+	User  *types.User
+	Tweet *types.Tweet // nil if the user has no pinned tweet, or it was not reported
+}
+
+// PinnedTweets pairs each user in r with its pinned tweet, in order. The
+// pinned tweets are resolved from the includes of r, so the query must have
+// requested the pinned_tweet_id expansion (see LookupPinned).
+func (r *Reply) PinnedTweets() ([]*PinnedTweet, error) {
+	inc, err := r.IncludedTweets()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*PinnedTweet, len(r.Users))
+	for i, u := range r.Users {
+		out[i] = &PinnedTweet{User: u}
+		if u.PinnedTweetID != "" {
+			out[i].Tweet = inc.FindByID(u.PinnedTweetID)
+		}
+	}
+	return out, nil
+}
+
+// LookupPinned looks up the given user ID, and any additional IDs in
+// opts.More, with the pinned_tweet_id expansion, and reports each user paired
+// with its pinned tweet. Optional tweet fields in opts apply to the pinned
+// tweets.
+//
+//	pins, err := users.LookupPinned(ctx, cli, "12", nil)
+//
+// API: 2/users
+func LookupPinned(ctx context.Context, cli *twitter.Client, id string, opts *LookupOpts) ([]*PinnedTweet, error) {
+	var lopts LookupOpts
+	if opts != nil {
+		lopts = *opts
+	}
+	lopts.Optional = append([]types.Fields{
+		types.Expansions{PinnedTweetID: true},
+	}, lopts.Optional...)
+	rsp, err := Lookup(id, &lopts).Invoke(ctx, cli)
+	if err != nil {
+		return nil, err
+	}
+	return rsp.PinnedTweets()
+}
@@ -26,7 +26,6 @@ package users
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"strconv"
 
 	"github.com/928799934/twitter"
@@ -34,13 +33,33 @@ import (
 	"github.com/928799934/twitter/types"
 )
 
+// Me constructs a query for the authenticated user. The reply contains
+// exactly one user. The More and Concurrency options are ignored. This query
+// requires user-context authorization.
+//
+// API: 2/users/me
 func Me(opts *LookupOpts) Query {
-	return newLookup("2/users/me", "user.fields", "name", opts)
+	req := &jape.Request{
+		Method: "2/users/me",
+		Params: make(jape.Params),
+	}
+	if opts != nil {
+		for _, fs := range opts.Optional {
+			if vs := fs.Values(); len(vs) != 0 {
+				req.Params.Add(fs.Label(), vs...)
+			}
+		}
+	}
+	return Query{Request: req}
 }
 
 // Lookup constructs a lookup query for one or more users by ID.  To look up
 // multiple IDs, add subsequent values to the opts.More field.
 //
+// The API accepts at most twitter.MaxLookupIDs IDs per request. If more IDs
+// are given, the query issues multiple requests and merges their results
+// into a single reply (see twitter.Client.CallChunked).
+//
 // API: 2/users
 func Lookup(id string, opts *LookupOpts) Query {
 	return newLookup("2/users", "ids", id, opts)
@@ -48,6 +67,7 @@ func Lookup(id string, opts *LookupOpts) Query {
 
 // LookupByName constructs a lookup query for one or more users by username.
 // To look up multiple usernames, add subsequent values to the opts.More field.
+// See Lookup for how large numbers of usernames are handled.
 //
 // API: 2/users/by
 func LookupByName(name string, opts *LookupOpts) Query {
@@ -61,7 +81,11 @@ func newLookup(method, param, key string, opts *LookupOpts) Query {
 	}
 	req.Params.Add(param, key)
 	opts.addRequestParams(param, req)
-	return Query{Request: req}
+	q := Query{Request: req, chunkParam: param}
+	if opts != nil {
+		q.concurrency = opts.Concurrency
+	}
+	return q
 }
 
 // FollowersOf returns a query for the followers of the specified user ID.
@@ -144,21 +168,33 @@ func LikersOf(id string, opts *ListOpts) Query {
 // A Query performs a lookup query for one or more users.
 type Query struct {
 	*jape.Request
+	chunkParam  string // for lookups, the parameter listing IDs or names
+	concurrency int    // for chunked lookups
 }
 
 // Invoke executes the query on the given context and client.
 func (q Query) Invoke(ctx context.Context, cli *twitter.Client) (*Reply, error) {
-	rsp, err := cli.Call(ctx, q.Request)
+	var rsp *twitter.Reply
+	var err error
+	if q.chunkParam != "" {
+		rsp, err = cli.CallChunked(ctx, q.Request, q.chunkParam, q.concurrency)
+	} else {
+		rsp, err = cli.Call(ctx, q.Request)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	fmt.Println(string(rsp.Data))
-
 	var users types.Users
 	if len(rsp.Data) == 0 {
 		// no results
-	} else if err := json.Unmarshal(rsp.Data, &users); err != nil {
+	} else if rsp.Data[0] == '{' {
+		users = append(users, new(types.User))
+		err = json.Unmarshal(rsp.Data, users[0])
+	} else {
+		err = json.Unmarshal(rsp.Data, &users)
+	}
+	if err != nil {
 		return nil, &jape.Error{Data: rsp.Data, Message: "decoding users data", Err: err}
 	}
 	out := &Reply{Reply: rsp, Users: users}
@@ -187,6 +223,26 @@ func (q Query) HasMorePages() bool {
 // invoking the query will then fetch the first page of results.
 func (q Query) ResetPageToken() { q.Request.Params.Reset(twitter.NextTokenParam) }
 
+// PageToken returns the page token the query will send when it is next
+// invoked, or "" if the query is fresh or has no more pages.
+func (q Query) PageToken() string { return q.Request.Params.Get(twitter.NextTokenParam) }
+
+// Iterate returns an iterator over the users reported by q, which fetches
+// pages of results from cli as needed.
+func (q Query) Iterate(cli *twitter.Client) Iterator {
+	return Iterator{twitter.NewIterator[*Reply, *types.User](cli, q, func(r *Reply) []*types.User {
+		return r.Users
+	})}
+}
+
+// An Iterator yields the users reported by a paginated query.
+type Iterator struct {
+	*twitter.Iterator[*Reply, *types.User]
+}
+
+// User returns the current user. It is valid only after Next returns true.
+func (it Iterator) User() *types.User { return it.Item() }
+
 // A Reply is the response from a Query.
 type Reply struct {
 	*twitter.Reply
@@ -202,6 +258,11 @@ type LookupOpts struct {
 
 	// Optional response fields and expansions.
 	Optional []types.Fields
+
+	// If more than twitter.MaxLookupIDs usernames or IDs are given, issue up
+	// to this many requests concurrently. If zero, requests are issued one at
+	// a time.
+	Concurrency int
 }
 
 func (o *LookupOpts) addRequestParams(param string, req *jape.Request) {
@@ -0,0 +1,91 @@
+// Copyright (C) 2022 Michael J. Fromberger. All Rights Reserved.
+
+package users_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/928799934/twitter/internal/otest"
+	"github.com/928799934/twitter/types"
+	"github.com/928799934/twitter/users"
+)
+
+func TestLikersOf(t *testing.T) {
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2/tweets/20/liking_users" {
+			t.Errorf("Unexpected request path %q", r.URL.Path)
+		}
+		io.WriteString(w, `{"data":[{"id":"1","username":"a"},{"id":"2","username":"b"}],`+
+			`"meta":{"result_count":2}}`)
+	}))
+
+	rsp, err := users.LikersOf("20", nil).Invoke(context.Background(), cli)
+	if err != nil {
+		t.Fatalf("LikersOf failed: %v", err)
+	}
+	var got []string
+	for _, u := range rsp.Users {
+		got = append(got, u.Username)
+	}
+	if want := "a b"; strings.Join(got, " ") != want {
+		t.Errorf("LikersOf: got %q, want %q", got, want)
+	}
+}
+
+func TestMe(t *testing.T) {
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2/users/me" {
+			t.Errorf("Unexpected request path %q", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("user.fields"); got != "description" {
+			t.Errorf("Parameter user.fields: got %q, want description", got)
+		}
+		io.WriteString(w, `{"data":{"id":"12","name":"jack","username":"jack","description":"hi"}}`)
+	}))
+
+	rsp, err := users.Me(&users.LookupOpts{
+		Optional: []types.Fields{types.UserFields{Description: true}},
+	}).Invoke(context.Background(), cli)
+	if err != nil {
+		t.Fatalf("Me failed: %v", err)
+	}
+	if len(rsp.Users) != 1 || rsp.Users[0].ID != "12" || rsp.Users[0].Description != "hi" {
+		t.Errorf("Me: got %+v, want user 12", rsp.Users)
+	}
+}
+
+func TestLookupPinned(t *testing.T) {
+	cli := otest.ServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if got := q.Get("expansions"); got != "pinned_tweet_id" {
+			t.Errorf("Parameter expansions: got %q, want pinned_tweet_id", got)
+		}
+		if got := q.Get("ids"); got != "1,2,3" {
+			t.Errorf("Parameter ids: got %q, want 1,2,3", got)
+		}
+		io.WriteString(w, `{"data":[{"id":"1","pinned_tweet_id":"10"},{"id":"2"},{"id":"3","pinned_tweet_id":"30"}],`+
+			`"includes":{"tweets":[{"id":"10","text":"pinned"}]}}`)
+	}))
+
+	pins, err := users.LookupPinned(context.Background(), cli, "1", &users.LookupOpts{
+		More: []string{"2", "3"},
+	})
+	if err != nil {
+		t.Fatalf("LookupPinned failed: %v", err)
+	}
+	var got []string
+	for _, p := range pins {
+		s := p.User.ID + ":"
+		if p.Tweet != nil {
+			s += p.Tweet.Text
+		}
+		got = append(got, s)
+	}
+	if want := "1:pinned 2: 3:"; strings.Join(got, " ") != want {
+		t.Errorf("LookupPinned: got %q, want %q", got, want)
+	}
+}